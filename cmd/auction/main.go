@@ -2,66 +2,526 @@ package main
 
 import (
 	"context"
+	"errors"
+	"expvar"
+	"flag"
+	"fmt"
+	"fullcycle-auction_go/configuration/config"
 	"fullcycle-auction_go/configuration/database/mongodb"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/apikey_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/ratelimit_entity"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/infra/api/web/controller/apikey_controller"
 	"fullcycle-auction_go/internal/infra/api/web/controller/auction_controller"
+	"fullcycle-auction_go/internal/infra/api/web/controller/auction_image_controller"
+	"fullcycle-auction_go/internal/infra/api/web/controller/auth_controller"
 	"fullcycle-auction_go/internal/infra/api/web/controller/bid_controller"
+	"fullcycle-auction_go/internal/infra/api/web/controller/category_controller"
+	"fullcycle-auction_go/internal/infra/api/web/controller/health_controller"
+	"fullcycle-auction_go/internal/infra/api/web/controller/logging_controller"
 	"fullcycle-auction_go/internal/infra/api/web/controller/user_controller"
+	"fullcycle-auction_go/internal/infra/api/web/middleware"
+	"fullcycle-auction_go/internal/infra/auth"
+	"fullcycle-auction_go/internal/infra/blobstore"
+	"fullcycle-auction_go/internal/infra/database/apikey"
 	"fullcycle-auction_go/internal/infra/database/auction"
 	"fullcycle-auction_go/internal/infra/database/bid"
+	"fullcycle-auction_go/internal/infra/database/category"
+	"fullcycle-auction_go/internal/infra/database/idempotency"
 	"fullcycle-auction_go/internal/infra/database/user"
+	"fullcycle-auction_go/internal/infra/health"
+	"fullcycle-auction_go/internal/infra/metrics"
+	"fullcycle-auction_go/internal/infra/ratelimit"
+	"fullcycle-auction_go/internal/infra/readiness"
+	"fullcycle-auction_go/internal/infra/tracing"
+	"fullcycle-auction_go/internal/infra/wal"
+	"fullcycle-auction_go/internal/usecase/apikey_usecase"
 	"fullcycle-auction_go/internal/usecase/auction_usecase"
+	"fullcycle-auction_go/internal/usecase/auth_usecase"
 	"fullcycle-auction_go/internal/usecase/bid_usecase"
+	"fullcycle-auction_go/internal/usecase/category_usecase"
 	"fullcycle-auction_go/internal/usecase/user_usecase"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"log"
+	"math"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
 )
 
 func main() {
 	ctx := context.Background()
 
-	if err := godotenv.Load("cmd/auction/.env"); err != nil {
-		log.Fatal("Error trying to load env variables")
+	// O .env é opcional; as variáveis do ambiente têm precedência sobre ele
+	if err := config.LoadEnvFile("cmd/auction/.env"); err != nil {
+		log.Fatal(err.Error())
+		return
+	}
+
+	flags := flag.NewFlagSet("auction", flag.ExitOnError)
+	printConfig := flags.Bool("print-config", false, "print the effective configuration and exit")
+	cfg, err := config.Load(flags, os.Args[1:])
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%s", err)
+		return
+	}
+
+	if *printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+
+	err = logger.Configure(logger.Settings{
+		Level:              cfg.Log.Level,
+		Format:             cfg.Log.Format,
+		SamplingInitial:    cfg.Log.SamplingInitial,
+		SamplingThereafter: cfg.Log.SamplingThereafter,
+	})
+	if err != nil {
+		log.Fatal(err.Error())
+		return
+	}
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing.Exporter, cfg.Tracing.ServiceName)
+	if err != nil {
+		log.Fatal(err.Error())
 		return
 	}
 
-	databaseConnection, err := mongodb.NewMongoDBConnection(ctx)
+	databaseConnection, err := mongodb.NewMongoDBConnection(ctx, cfg.MongoDB.URL, cfg.MongoDB.Database)
 	if err != nil {
 		log.Fatal(err.Error())
 		return
 	}
 
-	router := gin.Default()
+	blobStore, err := blobstore.NewBlobStore(cfg.BlobStore.Type, cfg.BlobStore.LocalDir, blobstore.S3Config{
+		Endpoint:  cfg.BlobStore.S3.Endpoint,
+		Region:    cfg.BlobStore.S3.Region,
+		Bucket:    cfg.BlobStore.S3.Bucket,
+		AccessKey: cfg.BlobStore.S3.AccessKey,
+		SecretKey: cfg.BlobStore.S3.SecretKey,
+	})
+	if err != nil {
+		log.Fatal(err.Error())
+		return
+	}
+
+	keySet, err := auth.LoadOrGenerateKeySet(cfg.Auth.KeysDir, cfg.Auth.ActiveKeyId)
+	if err != nil {
+		log.Fatal(err.Error())
+		return
+	}
+	tokenService := auth.NewTokenService(
+		keySet, cfg.Auth.Issuer, cfg.Auth.AccessTokenTTL, cfg.Auth.RefreshTokenTTL)
+
+	bidRateLimiters, err := newBidRateLimiters(
+		ratelimit.NewStore(cfg.RateLimit.Store, databaseConnection), cfg.RateLimit, cfg.Bids.MaxBidsPerRequest)
+	if err != nil {
+		log.Fatal(err.Error())
+		return
+	}
+
+	bidLog, err := wal.OpenBidLog(cfg.Bids.WALDir, cfg.Bids.WALSegmentSize)
+	if err != nil {
+		log.Fatal(err.Error())
+		return
+	}
+
+	deps := initDependencies(cfg, databaseConnection, blobStore, bidLog, keySet, tokenService, bidRateLimiters)
+
+	readinessState := readiness.NewState()
+	expvar.Publish("startup_recovery", expvar.Func(func() any {
+		return readinessState.Report()
+	}))
+
+	// A recuperação termina antes de o servidor HTTP subir e antes de novos
+	// lances entrarem na fila
+	if err := recoverOnStartup(ctx, deps, readinessState, cfg.Server.RecoveryTimeout); err != nil {
+		log.Fatal(err.Error())
+		return
+	}
+	deps.bidUseCase.Start()
+
+	expvar.Publish("bid_queue", expvar.Func(func() any {
+		return deps.bidUseCase.QueueStats()
+	}))
+	metrics.RegisterActiveAuctions(func() float64 {
+		return countActiveAuctions(deps.auctionRepository)
+	})
+
+	if len(cfg.Auth.BootstrapAdminEmails) > 0 {
+		deps.userUseCase.BootstrapAdmins(ctx, cfg.Auth.BootstrapAdminEmails)
+	}
+
+	// O log de acesso do gin é substituído pelo AccessLog, que inclui o request id
+	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatal(err.Error())
+		return
+	}
+	router.Use(
+		gin.Recovery(),
+		otelgin.Middleware(cfg.Tracing.ServiceName),
+		middleware.RequestContext(),
+		middleware.AccessLog(),
+		middleware.Metrics(),
+		deps.accessPolicy.Handler())
+
+	// Toda rota é registrada junto com a sua regra de acesso; o usuário que
+	// age nas rotas autenticadas é sempre o do token
+	route := func(method, path string, rule middleware.Rule, handlers ...gin.HandlerFunc) {
+		deps.accessPolicy.Set(method, path, rule)
+		router.Handle(method, path, handlers...)
+	}
+
+	loggingController := logging_controller.NewLoggingController()
+
+	// Chaves de API só são aceitas nas rotas com escopo
+	authenticated := middleware.Authenticated()
+	public := middleware.Public()
+	admins := middleware.RequireRoles(user_entity.RoleAdmin)
+	sellers := middleware.RequireRoles(user_entity.RoleSeller)
+	bidders := middleware.RequireRoles(user_entity.RoleBidder)
+	auctionsRead := public.WithScope(apikey_entity.ScopeAuctionsRead)
+	bidsRead := public.WithScope(apikey_entity.ScopeBidsRead)
+	auctionsWrite := sellers.WithScope(apikey_entity.ScopeAuctionsWrite)
+	bidsWrite := bidders.WithScope(apikey_entity.ScopeBidsWrite)
+
+	route(http.MethodGet, "/.well-known/jwks.json", public, deps.authController.JWKS)
+	route(http.MethodPost, "/auth/login", public, deps.authController.Login)
+	route(http.MethodPost, "/auth/refresh", public, deps.authController.Refresh)
+	route(http.MethodGet, "/apikey", authenticated, deps.apiKeyController.FindAPIKeys)
+	route(http.MethodPost, "/apikey", authenticated, deps.apiKeyController.CreateAPIKey)
+	route(http.MethodDelete, "/apikey/:keyId", authenticated, deps.apiKeyController.RevokeAPIKey)
+	route(http.MethodPost, "/apikey/:keyId/rotate", authenticated, deps.apiKeyController.RotateAPIKey)
+	route(http.MethodGet, "/auction", auctionsRead, deps.auctionController.FindAuctions)
+	route(http.MethodGet, "/auction/search", auctionsRead, deps.auctionController.SearchAuctions)
+	route(http.MethodGet, "/auction/:auctionId", auctionsRead, deps.auctionController.FindAuctionById)
+	route(http.MethodPost, "/auction", auctionsWrite, deps.idempotency.Handler(), deps.auctionController.CreateAuction)
+	route(http.MethodGet, "/auction/winner/:auctionId", auctionsRead, deps.auctionController.FindWinningBidByAuctionId)
+	route(http.MethodPost, "/auction/:auctionId/images", auctionsWrite, deps.auctionImageController.UploadImages)
+	route(http.MethodPut, "/auction/:auctionId/images/order", auctionsWrite, deps.auctionImageController.ReorderImages)
+	route(http.MethodGet, "/auction/:auctionId/images/:imageId", auctionsRead, deps.auctionImageController.FindImage)
+	route(http.MethodGet, "/auction/:auctionId/images/:imageId/thumbnail", auctionsRead, deps.auctionImageController.FindThumbnail)
+	route(http.MethodDelete, "/auction/:auctionId/images/:imageId", auctionsWrite, deps.auctionImageController.DeleteImage)
+	requireReady := middleware.RequireReady(readinessState)
+	route(http.MethodPost, "/bid", bidsWrite, requireReady, bidRateLimiters.single.Handler(), deps.idempotency.Handler(), deps.bidController.CreateBid)
+	route(http.MethodPost, "/bids/batch", bidsWrite, requireReady, bidRateLimiters.batch.Handler(), deps.idempotency.Handler(), deps.bidController.CreateBids)
+	route(http.MethodGet, "/bid/:auctionId", bidsRead, deps.bidController.FindBidByAuctionId)
+	route(http.MethodGet, "/user/:userId", public, deps.userController.FindUserById)
+	route(http.MethodPost, "/user", public, deps.userController.CreateUser)
+	route(http.MethodGet, "/category", auctionsRead, deps.categoryController.FindCategoryTree)
+	route(http.MethodGet, "/category/:categoryId", auctionsRead, deps.categoryController.FindCategoryById)
+	route(http.MethodPost, "/admin/category", admins, deps.categoryController.CreateCategory)
+	route(http.MethodPut, "/admin/category/:categoryId", admins, deps.categoryController.UpdateCategory)
+	route(http.MethodDelete, "/admin/category/:categoryId", admins, deps.categoryController.DeleteCategory)
+	route(http.MethodPut, "/admin/user/:userId/roles/:role", admins, deps.userController.GrantRole)
+	route(http.MethodDelete, "/admin/user/:userId/roles/:role", admins, deps.userController.RevokeRole)
+	route(http.MethodPost, "/admin/user/:userId/suspend", admins, deps.userController.SuspendUser)
+	route(http.MethodDelete, "/admin/user/:userId/suspend", admins, deps.userController.UnsuspendUser)
+	route(http.MethodPost, "/admin/bid/:bidId/void", admins, deps.bidController.VoidBid)
+	route(http.MethodGet, "/admin/logging", admins, loggingController.FindSettings)
+	route(http.MethodPut, "/admin/logging", admins, loggingController.UpdateSettings)
+	route(http.MethodGet, "/debug/vars", admins, gin.WrapH(expvar.Handler()))
+	route(http.MethodGet, "/metrics", public, gin.WrapH(metrics.Handler()))
+
+	healthController := health_controller.NewHealthController(
+		newHealthChecker(databaseConnection, deps, readinessState, cfg.Health))
+	route(http.MethodGet, "/healthz", public, healthController.Liveness)
+	route(http.MethodGet, "/readyz", public, healthController.Readiness)
+
+	if err := deps.accessPolicy.Verify(router.Routes()); err != nil {
+		log.Fatal(err.Error())
+		return
+	}
+
+	server := &http.Server{
+		Addr:    cfg.Server.Address,
+		Handler: router,
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err.Error())
+		}
+	}()
 
-	userController, bidController, auctionsController := initDependencies(databaseConnection)
+	signalCtx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	<-signalCtx.Done()
 
-	router.GET("/auction", auctionsController.FindAuctions)
-	router.GET("/auction/:auctionId", auctionsController.FindAuctionById)
-	router.POST("/auction", auctionsController.CreateAuction)
-	router.GET("/auction/winner/:auctionId", auctionsController.FindWinningBidByAuctionId)
-	router.POST("/bid", bidController.CreateBid)
-	router.GET("/bid/:auctionId", bidController.FindBidByAuctionId)
-	router.GET("/user/:userId", userController.FindUserById)
-	router.POST("/user", userController.CreateUser)
+	logger.Info("Shutdown signal received")
+	shutdown([]shutdownStep{
+		// Primeiro param de receber requisições, esperando as que estão em andamento
+		{"http server", cfg.Server.ShutdownTimeout, server.Shutdown},
+		// Depois grava os lances que ficaram na fila
+		{"bid queue", 20 * time.Second, func(ctx context.Context) error {
+			if err := deps.bidUseCase.Shutdown(ctx); err != nil {
+				return err
+			}
+			return nil
+		}},
+		{"auction closer", 5 * time.Second, deps.auctionRepository.Shutdown},
+		{"bid write-ahead log", time.Second, func(ctx context.Context) error {
+			return bidLog.Close()
+		}},
+		{"mongodb client", 5 * time.Second, databaseConnection.Client().Disconnect},
+		// Por último envia os spans pendentes, inclusive os do próprio encerramento
+		{"tracing", 5 * time.Second, shutdownTracing},
+	})
+	logger.Sync()
+}
+
+// recoverOnStartup recupera o estado deixado pela execução anterior e registra
+// o resultado de cada etapa em state
+func recoverOnStartup(
+	ctx context.Context,
+	deps *dependencies,
+	state *readiness.State,
+	timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	router.Run(":8080")
+	// Os lances do log são regravados antes de os leilões vencidos serem
+	// fechados: eles já foram confirmados ao cliente e valem pelo horário em
+	// que foram feitos, e o vencedor de um leilão fechado agora já os considera
+	err := state.Run("logged bids", func() (any, error) {
+		recovery, err := deps.bidUseCase.RecoverLoggedBids(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return recovery, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = state.Run("auctions", func() (any, error) {
+		recovery, err := deps.auctionRepository.RecoverAuctions(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return recovery, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	state.MarkReady()
+	logger.InfoContext(ctx, "Startup recovery completed", zap.Any("recovery", state.Report()))
+	return nil
+}
+
+// shutdownStep é uma etapa do encerramento, com prazo próprio
+type shutdownStep struct {
+	name    string
+	timeout time.Duration
+	run     func(ctx context.Context) error
 }
 
-func initDependencies(database *mongo.Database) (
-	userController *user_controller.UserController,
-	bidController *bid_controller.BidController,
-	auctionController *auction_controller.AuctionController) {
+// shutdown executa as etapas em ordem; uma etapa que falha ou estoura o prazo
+// é registrada no log e não impede as seguintes
+func shutdown(steps []shutdownStep) {
+	for _, step := range steps {
+		started := time.Now()
+		ctx, cancel := context.WithTimeout(context.Background(), step.timeout)
+		err := step.run(ctx)
+		cancel()
+
+		if err != nil {
+			logger.Error("Error during shutdown step", err, zap.String("step", step.name),
+				zap.Duration("elapsed", time.Since(started)))
+			continue
+		}
+		logger.Info("Shutdown step completed", zap.String("step", step.name),
+			zap.Duration("elapsed", time.Since(started)))
+	}
+}
 
-	auctionRepository := auction.NewAuctionRepository(database)
+// bidRateLimiters reúne os limites de lances das duas rotas de envio
+type bidRateLimiters struct {
+	single *middleware.RateLimiter
+	// batch cobra uma ficha por item; a regra por leilão é aplicada a cada
+	// item pelo caso de uso, com perAuction e os baldes de store
+	batch      *middleware.RateLimiter
+	perAuction ratelimit_entity.Limit
+	store      ratelimit_entity.RateLimitStoreInterface
+}
+
+// newBidRateLimiters limita os lances por usuário, por IP e por leilão
+func newBidRateLimiters(
+	store ratelimit_entity.RateLimitStoreInterface,
+	limits config.RateLimitConfig,
+	maxBidsPerRequest int) (*bidRateLimiters, error) {
+	rules := []struct{ name, value string }{
+		{"user", limits.PerUser},
+		{"ip", limits.PerIP},
+		{"auction", limits.PerAuction},
+	}
+
+	parsed := make(map[string]ratelimit_entity.Limit, len(rules))
+	for _, rule := range rules {
+		limit, err := ratelimit_entity.ParseLimit(rule.value)
+		if err != nil {
+			return nil, fmt.Errorf("%s rate limit: %w", rule.name, err)
+		}
+		parsed[rule.name] = limit
+	}
+
+	batchCost := middleware.JSONArrayRateLimitCost(maxBidsPerRequest)
+	return &bidRateLimiters{
+		single: middleware.NewRateLimiter(store,
+			middleware.RateLimitRule{Name: "user", Limit: parsed["user"], Key: middleware.UserRateLimitKey},
+			middleware.RateLimitRule{Name: "ip", Limit: parsed["ip"], Key: middleware.ClientIPRateLimitKey},
+			middleware.RateLimitRule{Name: "auction", Limit: parsed["auction"], Key: middleware.JSONFieldRateLimitKey("auction_id")}),
+		batch: middleware.NewRateLimiter(store,
+			middleware.RateLimitRule{Name: "user", Limit: parsed["user"], Key: middleware.UserRateLimitKey, Cost: batchCost},
+			middleware.RateLimitRule{Name: "ip", Limit: parsed["ip"], Key: middleware.ClientIPRateLimitKey, Cost: batchCost}),
+		perAuction: parsed["auction"],
+		store:      store,
+	}, nil
+}
+
+// countActiveAuctions é lido a cada coleta do /metrics; uma falha no banco
+// aparece como NaN em vez de um valor antigo
+func countActiveAuctions(auctionRepository *auction.AuctionRepository) float64 {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	count, err := auctionRepository.CountActiveAuctions(ctx)
+	if err != nil {
+		return math.NaN()
+	}
+	return float64(count)
+}
+
+// newHealthChecker registra as dependências verificadas pelo /readyz
+func newHealthChecker(
+	database *mongo.Database,
+	deps *dependencies,
+	readinessState *readiness.State,
+	healthConfig config.HealthConfig) *health.Checker {
+	checker := health.NewChecker(healthConfig.CheckTimeout)
+
+	checker.Register("mongodb", func(ctx context.Context) (any, error) {
+		return nil, database.Client().Ping(ctx, readpref.Primary())
+	})
+
+	saturation := healthConfig.QueueSaturation
+	checker.Register("bid_queue", func(ctx context.Context) (any, error) {
+		stats := deps.bidUseCase.QueueStats()
+		if stats.Capacity > 0 && float64(stats.Depth) >= saturation*float64(stats.Capacity) {
+			return stats, fmt.Errorf("bid queue is saturated (%d of %d)", stats.Depth, stats.Capacity)
+		}
+		return stats, nil
+	})
+
+	checker.Register("auction_closer", func(ctx context.Context) (any, error) {
+		status, err := deps.auctionRepository.CheckAuctionCloser(time.Now())
+		// Um *InternalError nil não pode ser retornado direto como error
+		if err != nil {
+			return status, err
+		}
+		return status, nil
+	})
+
+	checker.Register("startup_recovery", func(ctx context.Context) (any, error) {
+		report := readinessState.Report()
+		if report.Phase != readiness.Ready {
+			return report, fmt.Errorf("startup recovery is %s", report.Phase)
+		}
+		return report, nil
+	})
+
+	return checker
+}
+
+type dependencies struct {
+	userController         *user_controller.UserController
+	bidController          *bid_controller.BidController
+	auctionController      *auction_controller.AuctionController
+	categoryController     *category_controller.CategoryController
+	auctionImageController *auction_image_controller.AuctionImageController
+	authController         *auth_controller.AuthController
+	apiKeyController       *apikey_controller.APIKeyController
+	auctionRepository      *auction.AuctionRepository
+	userUseCase            user_usecase.UserUseCaseInterface
+	bidUseCase             bid_usecase.BidUseCaseInterface
+	accessPolicy           *middleware.Policy
+	idempotency            *middleware.Idempotency
+}
+
+func initDependencies(
+	cfg *config.Config,
+	database *mongo.Database,
+	blobStore blobstore.BlobStore,
+	bidLog bid_entity.BidLogInterface,
+	keySet *auth.KeySet,
+	tokenService *auth.TokenService,
+	bidRateLimiters *bidRateLimiters) *dependencies {
+
+	auctionRepository := auction.NewAuctionRepository(database, auction.AuctionRepositoryConfig{
+		AuctionDuration:    cfg.Auctions.Duration,
+		CloserSyncInterval: cfg.Auctions.CloserSyncInterval,
+		StateCacheTTL:      cfg.Auctions.StateCacheTTL,
+		ChangeStream:       cfg.Auctions.ChangeStream,
+		LeaseTTL:           cfg.Leader.LeaseTTL,
+		LeaseRenewInterval: cfg.Leader.RenewInterval,
+		LeaseRetryInterval: cfg.Leader.RetryInterval,
+	})
 	bidRepository := bid.NewBidRepository(database, auctionRepository)
 	userRepository := user.NewUserRepository(database)
+	categoryRepository := category.NewCategoryRepository(database)
+	apiKeyRepository := apikey.NewAPIKeyRepository(database)
+
+	userUseCase := user_usecase.NewUserUseCase(userRepository)
+	bidUseCase := bid_usecase.NewBidUseCase(bidRepository, bidLog, bid_usecase.BidBatchConfig{
+		QueueCapacity:        cfg.Bids.QueueCapacity,
+		EnqueueTimeout:       cfg.Bids.EnqueueTimeout,
+		MaxBatchSize:         cfg.Bids.MaxBatchSize,
+		MaxBatchBytes:        cfg.Bids.MaxBatchBytes,
+		FlushInterval:        cfg.Bids.FlushInterval,
+		MaxConcurrentFlushes: cfg.Bids.MaxConcurrentFlushes,
+		MaxBidsPerRequest:    cfg.Bids.MaxBidsPerRequest,
 
-	userController = user_controller.NewUserController(
-		user_usecase.NewUserUseCase(userRepository))
-	auctionController = auction_controller.NewAuctionController(
-		auction_usecase.NewAuctionUseCase(auctionRepository, bidRepository))
-	bidController = bid_controller.NewBidController(bid_usecase.NewBidUseCase(bidRepository))
+		AuctionRateLimit:      bidRateLimiters.perAuction,
+		AuctionRateLimitStore: bidRateLimiters.store,
+	})
 
-	return
+	return &dependencies{
+		userController: user_controller.NewUserController(userUseCase),
+		auctionController: auction_controller.NewAuctionController(
+			auction_usecase.NewAuctionUseCase(auctionRepository, bidRepository, categoryRepository)),
+		categoryController: category_controller.NewCategoryController(
+			category_usecase.NewCategoryUseCase(categoryRepository, auctionRepository)),
+		auctionImageController: auction_image_controller.NewAuctionImageController(
+			auction_usecase.NewAuctionImageUseCase(auctionRepository, blobStore, auction_usecase.AuctionImageConfig{
+				MaxImageSize:     cfg.Images.MaxSize,
+				MaxImages:        cfg.Images.MaxPerAuction,
+				MaxPixels:        cfg.Images.MaxPixels,
+				ThumbnailMaxSide: cfg.Images.ThumbnailMaxSide,
+			})),
+		authController: auth_controller.NewAuthController(
+			auth_usecase.NewAuthUseCase(userRepository, tokenService), keySet),
+		apiKeyController: apikey_controller.NewAPIKeyController(
+			apikey_usecase.NewAPIKeyUseCase(apiKeyRepository)),
+		bidController:     bid_controller.NewBidController(bidUseCase),
+		auctionRepository: auctionRepository,
+		userUseCase:       userUseCase,
+		bidUseCase:        bidUseCase,
+		accessPolicy:      middleware.NewPolicy(tokenService, userRepository, apiKeyRepository),
+		idempotency: middleware.NewIdempotency(
+			idempotency.NewIdempotencyRepository(database), cfg.Server.IdempotencyTTL),
+	}
 }
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/usecase/auction_usecase"
+	"fullcycle-auction_go/internal/usecase/bid_usecase"
+	"time"
+)
+
+// action executa um comando já com os argumentos validados
+type action func(ctx context.Context, app *app) error
+
+var commands = []command{
+	{name: "auction list", args: "[--status active,completed,cancelled] [--category-id id] [--product text] [--limit n] [--page-token token]",
+		summary: "list auctions", parse: listAuctions},
+	{name: "auction inspect", args: "<auction-id>",
+		summary: "show an auction and its winning bid", parse: inspectAuction},
+	{name: "auction close", args: "<auction-id>",
+		summary: "close an active auction now", parse: closeAuction},
+	{name: "auction cancel", args: "<auction-id> --reason text",
+		summary: "cancel an active auction without a winner", parse: cancelAuction},
+	{name: "auction extend", args: "<auction-id> (--until RFC3339 | --by duration)",
+		summary: "move the end time of an active auction", parse: extendAuction},
+	{name: "auction relist", args: "<auction-id> [--duration duration]",
+		summary: "create a new auction from a finished one", parse: relistAuction},
+	{name: "bid void", args: "<bid-id> --reason text",
+		summary: "void a bid and recalculate the auction price", parse: voidBid},
+	{name: "user get", args: "<user-id|email>",
+		summary: "look up a user by id or email", parse: getUser},
+	{name: "migrate status", args: "",
+		summary: "list data migrations and whether they were applied", parse: migrationStatus},
+	{name: "migrate up", args: "",
+		summary: "apply pending data migrations", parse: migrateUp},
+}
+
+func listAuctions(args []string) (action, error) {
+	flags := flag.NewFlagSet("auction list", flag.ContinueOnError)
+	status := flags.String("status", "", "comma separated statuses: active, completed, cancelled")
+	categoryId := flags.String("category-id", "", "category id, including its subcategories")
+	productName := flags.String("product", "", "text contained in the product name")
+	limit := flags.Int("limit", 20, "page size (1-100)")
+	pageToken := flags.String("page-token", "", "token returned by the previous page")
+	sort := flags.String("sort", "created_at", "created_at, end_time, current_price or bid_count")
+	order := flags.String("order", "desc", "asc or desc")
+	if _, err := parseArgs(flags, args, 0); err != nil {
+		return nil, err
+	}
+
+	input := auction_usecase.FindAuctionsInputDTO{
+		CategoryId:  *categoryId,
+		ProductName: *productName,
+		Limit:       *limit,
+		PageToken:   *pageToken,
+		Sort:        *sort,
+		Order:       *order,
+	}
+	for _, name := range splitList(*status) {
+		parsed, err := auction_entity.ParseAuctionStatus(name)
+		if err != nil {
+			return nil, usageError(err.Error())
+		}
+		input.Statuses = append(input.Statuses, auction_usecase.AuctionStatus(parsed))
+	}
+
+	return func(ctx context.Context, app *app) error {
+		page, err := app.auctionUseCase.FindAuctions(ctx, input)
+		if err != nil {
+			return err
+		}
+		return app.out.auctionPage(page)
+	}, nil
+}
+
+func inspectAuction(args []string) (action, error) {
+	values, err := parseArgs(flag.NewFlagSet("auction inspect", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, app *app) error {
+		winning, findErr := app.auctionUseCase.FindWinningBidByAuctionId(ctx, values[0])
+		if findErr != nil {
+			return findErr
+		}
+		return app.out.auctionInspection(winning)
+	}, nil
+}
+
+func closeAuction(args []string) (action, error) {
+	values, err := parseArgs(flag.NewFlagSet("auction close", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, app *app) error {
+		auction, closeErr := app.auctionUseCase.ForceCloseAuction(ctx, app.actor, values[0])
+		if closeErr != nil {
+			return closeErr
+		}
+		return app.out.auction(auction)
+	}, nil
+}
+
+func cancelAuction(args []string) (action, error) {
+	flags := flag.NewFlagSet("auction cancel", flag.ContinueOnError)
+	reason := flags.String("reason", "", "why the auction is being cancelled (required)")
+	values, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	if *reason == "" {
+		return nil, usageError("--reason is required")
+	}
+
+	return func(ctx context.Context, app *app) error {
+		auction, cancelErr := app.auctionUseCase.CancelAuction(ctx, app.actor, values[0],
+			auction_usecase.CancelAuctionInputDTO{Reason: *reason})
+		if cancelErr != nil {
+			return cancelErr
+		}
+		return app.out.auction(auction)
+	}, nil
+}
+
+func extendAuction(args []string) (action, error) {
+	flags := flag.NewFlagSet("auction extend", flag.ContinueOnError)
+	until := flags.String("until", "", "new end time in RFC3339")
+	by := flags.Duration("by", 0, "time added to the current end time")
+	values, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	// Confere as flags antes de consultar o término atual
+	if _, err := parseEndTime(*until, *by, time.Time{}); err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, app *app) error {
+		current, findErr := app.auctionUseCase.FindAuctionById(ctx, values[0])
+		if findErr != nil {
+			return findErr
+		}
+
+		endTime, err := parseEndTime(*until, *by, current.EndTime)
+		if err != nil {
+			return err
+		}
+
+		auction, extendErr := app.auctionUseCase.ExtendAuction(ctx, app.actor, values[0],
+			auction_usecase.ExtendAuctionInputDTO{EndTime: endTime})
+		if extendErr != nil {
+			return extendErr
+		}
+		return app.out.auction(auction)
+	}, nil
+}
+
+func relistAuction(args []string) (action, error) {
+	flags := flag.NewFlagSet("auction relist", flag.ContinueOnError)
+	duration := flags.Duration("duration", 0, "duration of the new auction (default: category or global duration)")
+	values, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, app *app) error {
+		auction, relistErr := app.auctionUseCase.RelistAuction(ctx, app.actor, values[0],
+			auction_usecase.RelistAuctionInputDTO{Duration: *duration})
+		if relistErr != nil {
+			return relistErr
+		}
+		return app.out.auction(auction)
+	}, nil
+}
+
+func voidBid(args []string) (action, error) {
+	flags := flag.NewFlagSet("bid void", flag.ContinueOnError)
+	reason := flags.String("reason", "", "why the bid is being voided (required)")
+	values, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(*reason) < 3 {
+		return nil, usageError("--reason with at least 3 characters is required")
+	}
+
+	return func(ctx context.Context, app *app) error {
+		bid, voidErr := app.bidUseCase.VoidBid(ctx, values[0], bid_usecase.VoidBidInputDTO{Reason: *reason})
+		if voidErr != nil {
+			return voidErr
+		}
+		return app.out.bid(bid)
+	}, nil
+}
+
+func getUser(args []string) (action, error) {
+	values, err := parseArgs(flag.NewFlagSet("user get", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, app *app) error {
+		user, findErr := app.userUseCase.LookupUser(ctx, values[0])
+		if findErr != nil {
+			return findErr
+		}
+		return app.out.user(user)
+	}, nil
+}
+
+func migrationStatus(args []string) (action, error) {
+	if _, err := parseArgs(flag.NewFlagSet("migrate status", flag.ContinueOnError), args, 0); err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, app *app) error {
+		statuses, err := app.migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return app.out.migrations(statuses)
+	}, nil
+}
+
+func migrateUp(args []string) (action, error) {
+	if _, err := parseArgs(flag.NewFlagSet("migrate up", flag.ContinueOnError), args, 0); err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, app *app) error {
+		// As migrações aplicadas são exibidas mesmo quando uma delas falha
+		applied, err := app.migrator.Up(ctx)
+		if printErr := app.out.migrations(applied); printErr != nil {
+			return printErr
+		}
+		if err != nil {
+			return err
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,245 @@
+// auctionctl opera leilões, lances e usuários direto no banco, com os mesmos
+// casos de uso e repositórios da API, e aplica as migrações de dados.
+//
+//	auctionctl [flags de configuração] [--output table|json] <comando> [argumentos]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"fullcycle-auction_go/configuration/config"
+	"fullcycle-auction_go/configuration/database/mongodb"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/infra/database/auction"
+	"fullcycle-auction_go/internal/infra/database/bid"
+	"fullcycle-auction_go/internal/infra/database/category"
+	"fullcycle-auction_go/internal/infra/database/migration"
+	"fullcycle-auction_go/internal/infra/database/user"
+	"fullcycle-auction_go/internal/usecase/auction_usecase"
+	"fullcycle-auction_go/internal/usecase/bid_usecase"
+	"fullcycle-auction_go/internal/usecase/user_usecase"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// app reúne as dependências usadas pelos comandos
+type app struct {
+	auctionUseCase auction_usecase.AuctionUseCaseInterface
+	bidUseCase     bid_usecase.BidUseCaseInterface
+	userUseCase    user_usecase.UserUseCaseInterface
+	migrator       *migration.Migrator
+	out            *printer
+	// actor identifica o operador nos logs das operações de administração
+	actor string
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run executa o comando e retorna o código de saída: 1 em caso de erro e 2
+// para uso incorreto
+func run(args []string) int {
+	// O mesmo .env da API; as variáveis do ambiente têm precedência sobre ele
+	if err := config.LoadEnvFile("cmd/auction/.env"); err != nil {
+		return fail(err)
+	}
+
+	flags := flag.NewFlagSet("auctionctl", flag.ExitOnError)
+	output := flags.String("output", formatTable, "output format: table or json")
+	actor := flags.String("actor", defaultActor(), "operator name recorded in the logs")
+	flags.Usage = func() { usage(flags) }
+
+	cfg, err := config.Load(flags, args)
+	if err != nil {
+		return fail(fmt.Errorf("invalid configuration:\n%w", err))
+	}
+
+	out, err := newPrinter(os.Stdout, *output)
+	if err != nil {
+		return fail(err)
+	}
+
+	cmd, cmdArgs, ok := findCommand(flags.Args())
+	if !ok {
+		usage(flags)
+		return 2
+	}
+
+	// Os argumentos são conferidos antes de conectar ao banco
+	execute, err := cmd.parse(cmdArgs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\nusage: auctionctl %s %s\n", err, cmd.name, cmd.args)
+		return 2
+	}
+
+	// Os logs vão para a saída de erro, deixando a saída padrão só com o resultado
+	logger.SetOutput(os.Stderr)
+	err = logger.Configure(logger.Settings{Level: cfg.Log.Level, Format: cfg.Log.Format})
+	if err != nil {
+		return fail(err)
+	}
+	defer logger.Sync()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	database, err := mongodb.NewMongoDBConnection(ctx, cfg.MongoDB.URL, cfg.MongoDB.Database)
+	if err != nil {
+		return fail(err)
+	}
+	defer database.Client().Disconnect(context.Background())
+
+	app, cleanup, err := newApp(cfg, database, out, *actor)
+	if err != nil {
+		return fail(err)
+	}
+	defer cleanup()
+
+	if err := execute(ctx, app); err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+// newApp monta os casos de uso como na API, mas sem o fechamento automático
+// de leilões e sem a fila de lances: a CLI só executa operações pontuais
+func newApp(cfg *config.Config, database *mongo.Database, out *printer, actor string) (*app, func(), error) {
+	auctionRepository := auction.NewAuctionRepository(database, auction.AuctionRepositoryConfig{
+		AuctionDuration:  cfg.Auctions.Duration,
+		StateCacheTTL:    cfg.Auctions.StateCacheTTL,
+		DisableAutoClose: true,
+	})
+	bidRepository := bid.NewBidRepository(database, auctionRepository)
+	userRepository := user.NewUserRepository(database)
+	categoryRepository := category.NewCategoryRepository(database)
+
+	migrator, err := migration.NewMigrator(database, migration.Migrations(cfg.Auctions.Duration))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &app{
+		auctionUseCase: auction_usecase.NewAuctionUseCase(auctionRepository, bidRepository, categoryRepository),
+		// Sem Start, o caso de uso não inicia a fila; a anulação grava direto no banco
+		bidUseCase:  bid_usecase.NewBidUseCase(bidRepository, nil, bid_usecase.BidBatchConfig{}),
+		userUseCase: user_usecase.NewUserUseCase(userRepository),
+		migrator:    migrator,
+		out:         out,
+		actor:       actor,
+	}, auctionRepository.Cleanup, nil
+}
+
+func defaultActor() string {
+	if name := os.Getenv("USER"); name != "" {
+		return "cli:" + name
+	}
+	return "cli"
+}
+
+func usage(flags *flag.FlagSet) {
+	w := flags.Output()
+	fmt.Fprintln(w, "usage: auctionctl [flags] <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-16s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "flags (configuration flags match the API server):")
+	flags.PrintDefaults()
+}
+
+func fail(err error) int {
+	fmt.Fprintln(os.Stderr, "error:", err)
+	return 1
+}
+
+// command é um subcomando, identificado pelo grupo e pela ação ("auction list")
+type command struct {
+	name    string
+	args    string
+	summary string
+	// parse valida os argumentos e retorna a ação a executar
+	parse func(args []string) (action, error)
+}
+
+// findCommand procura o comando pelas duas primeiras palavras e retorna os
+// argumentos restantes
+func findCommand(args []string) (*command, []string, bool) {
+	if len(args) < 2 {
+		return nil, nil, false
+	}
+	name := args[0] + " " + args[1]
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i], args[2:], true
+		}
+	}
+	return nil, nil, false
+}
+
+// usageError indica argumentos inválidos
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+// parseArgs aceita flags antes ou depois dos argumentos posicionais, como em
+// "auction cancel <id> --reason fraude", e confere a quantidade de argumentos
+func parseArgs(flags *flag.FlagSet, args []string, positional int) ([]string, error) {
+	flags.SetOutput(os.Stderr)
+
+	var values []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, usageError(err.Error())
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		values = append(values, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+
+	if len(values) != positional {
+		return nil, usageError(fmt.Sprintf("expected %d argument(s), got %d", positional, len(values)))
+	}
+	return values, nil
+}
+
+// parseEndTime interpreta --until (RFC3339) ou --by (a partir do término atual)
+func parseEndTime(until string, by time.Duration, current time.Time) (time.Time, error) {
+	switch {
+	case until != "" && by != 0:
+		return time.Time{}, usageError("use either --until or --by")
+	case until != "":
+		endTime, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			return time.Time{}, usageError(fmt.Sprintf("invalid --until %q, use RFC3339", until))
+		}
+		return endTime, nil
+	case by > 0:
+		return current.Add(by), nil
+	default:
+		return time.Time{}, usageError("--until or a positive --by is required")
+	}
+}
+
+// splitList separa valores informados com vírgula
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
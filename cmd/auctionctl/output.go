@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/infra/database/migration"
+	"fullcycle-auction_go/internal/usecase/auction_usecase"
+	"fullcycle-auction_go/internal/usecase/bid_usecase"
+	"fullcycle-auction_go/internal/usecase/user_usecase"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+// printer escreve o resultado dos comandos como tabela, para leitura, ou como
+// JSON, com os mesmos campos das respostas da API
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+func newPrinter(w io.Writer, format string) (*printer, error) {
+	if format != formatTable && format != formatJSON {
+		return nil, fmt.Errorf("invalid output %q, use table or json", format)
+	}
+	return &printer{w: w, format: format}, nil
+}
+
+func (p *printer) auctionPage(page *auction_usecase.AuctionPageOutputDTO) error {
+	if p.format == formatJSON {
+		return p.json(page)
+	}
+
+	rows := make([][]string, 0, len(page.Items))
+	for _, auction := range page.Items {
+		rows = append(rows, []string{
+			auction.Id, auction.ProductName, auction.Category, statusName(auction.Status),
+			formatAmount(auction.CurrentPrice), strconv.FormatInt(auction.BidCount, 10), formatTime(auction.EndTime),
+		})
+	}
+	if err := p.table([]string{"ID", "PRODUCT", "CATEGORY", "STATUS", "PRICE", "BIDS", "END TIME"}, rows); err != nil {
+		return err
+	}
+
+	if page.Total != nil {
+		fmt.Fprintf(p.w, "\n%d auction(s) in total\n", *page.Total)
+	}
+	if page.NextToken != "" {
+		fmt.Fprintf(p.w, "next page: --page-token %s\n", page.NextToken)
+	}
+	return nil
+}
+
+func (p *printer) auction(auction *auction_usecase.AuctionOutputDTO) error {
+	if p.format == formatJSON {
+		return p.json(auction)
+	}
+	return p.table(nil, auctionFields(auction))
+}
+
+func (p *printer) auctionInspection(winning *auction_usecase.WinningInfoOutputDTO) error {
+	if p.format == formatJSON {
+		return p.json(winning)
+	}
+
+	rows := auctionFields(&winning.Auction)
+	if winning.Bid == nil {
+		rows = append(rows, []string{"WINNING BID", "none"})
+	} else {
+		rows = append(rows,
+			[]string{"WINNING BID", winning.Bid.Id},
+			[]string{"WINNING USER", winning.Bid.UserId},
+			[]string{"WINNING AMOUNT", formatAmount(winning.Bid.Amount)})
+	}
+	rows = append(rows, []string{"WINNING RULE", winning.WinningRule})
+	return p.table(nil, rows)
+}
+
+func (p *printer) bid(bid *bid_usecase.BidOutputDTO) error {
+	if p.format == formatJSON {
+		return p.json(bid)
+	}
+	return p.table(nil, [][]string{
+		{"ID", bid.Id},
+		{"AUCTION", bid.AuctionId},
+		{"USER", bid.UserId},
+		{"AMOUNT", formatAmount(bid.Amount)},
+		{"SEQUENCE", strconv.FormatInt(bid.Sequence, 10)},
+		{"TIMESTAMP", formatTime(bid.Timestamp)},
+		{"VOIDED", strconv.FormatBool(bid.Voided)},
+		{"VOID REASON", bid.VoidReason},
+	})
+}
+
+func (p *printer) user(user *user_usecase.UserAdminOutputDTO) error {
+	if p.format == formatJSON {
+		return p.json(user)
+	}
+	return p.table(nil, [][]string{
+		{"ID", user.Id},
+		{"NAME", user.Name},
+		{"EMAIL", user.Email},
+		{"ROLES", strings.Join(user.Roles, ",")},
+		{"SUSPENDED", strconv.FormatBool(user.Suspended)},
+	})
+}
+
+func (p *printer) migrations(statuses []migration.MigrationStatus) error {
+	if p.format == formatJSON {
+		if statuses == nil {
+			statuses = []migration.MigrationStatus{}
+		}
+		return p.json(statuses)
+	}
+
+	rows := make([][]string, 0, len(statuses))
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = formatTime(*status.AppliedAt)
+		}
+		rows = append(rows, []string{strconv.Itoa(status.Version), status.Name, appliedAt, status.Description})
+	}
+	return p.table([]string{"VERSION", "NAME", "APPLIED", "DESCRIPTION"}, rows)
+}
+
+func auctionFields(auction *auction_usecase.AuctionOutputDTO) [][]string {
+	rows := [][]string{
+		{"ID", auction.Id},
+		{"PRODUCT", auction.ProductName},
+		{"CATEGORY", auction.Category},
+		{"SELLER", auction.SellerId},
+		{"STATUS", statusName(auction.Status)},
+		{"CREATED", formatTime(auction.Timestamp)},
+		{"END TIME", formatTime(auction.EndTime)},
+		{"PRICE", formatAmount(auction.CurrentPrice)},
+		{"BIDS", strconv.FormatInt(auction.BidCount, 10)},
+		{"IMAGES", strconv.Itoa(len(auction.Images))},
+	}
+	if auction.CancelReason != "" {
+		rows = append(rows, []string{"CANCEL REASON", auction.CancelReason})
+	}
+	if auction.CancelledAt != nil {
+		rows = append(rows, []string{"CANCELLED AT", formatTime(*auction.CancelledAt)})
+	}
+	if auction.RelistedFrom != "" {
+		rows = append(rows, []string{"RELISTED FROM", auction.RelistedFrom})
+	}
+	return rows
+}
+
+// table alinha as colunas; sem header, cada linha é um par campo e valor
+func (p *printer) table(header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	if header != nil {
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+	}
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func (p *printer) json(value any) error {
+	encoder := json.NewEncoder(p.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func statusName(status auction_usecase.AuctionStatus) string {
+	return auction_entity.AuctionStatus(status).String()
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+func formatTime(value time.Time) string {
+	if value.IsZero() {
+		return "-"
+	}
+	return value.Local().Format(time.RFC3339)
+}
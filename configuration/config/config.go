@@ -0,0 +1,351 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/internal/entity/ratelimit_entity"
+	"io"
+	"net/url"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config reúne toda a configuração da aplicação. Cada campo tem o nome usado
+// no arquivo YAML (tag yaml) e a variável de ambiente correspondente (tag env);
+// a flag de linha de comando é o caminho no YAML com hífens, como
+// --bids.max-batch-size.
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	MongoDB   MongoDBConfig   `yaml:"mongodb"`
+	Log       LogConfig       `yaml:"log"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	Health    HealthConfig    `yaml:"health"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Auctions  AuctionsConfig  `yaml:"auctions"`
+	Leader    LeaderConfig    `yaml:"leader"`
+	Bids      BidsConfig      `yaml:"bids"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Images    ImagesConfig    `yaml:"images"`
+	BlobStore BlobStoreConfig `yaml:"blob_store"`
+}
+
+type ServerConfig struct {
+	Address string `yaml:"address" env:"HTTP_ADDRESS"`
+	// ShutdownTimeout é o tempo dado às requisições em andamento no encerramento
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	// RecoveryTimeout é o prazo da recuperação na inicialização
+	RecoveryTimeout time.Duration `yaml:"recovery_timeout" env:"RECOVERY_TIMEOUT"`
+	// TrustedProxies são os proxies cujo X-Forwarded-For é aceito; sem eles, o
+	// IP do cliente é o endereço da conexão e não pode ser forjado
+	TrustedProxies []string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES"`
+	// IdempotencyTTL é por quanto tempo as respostas das requisições com
+	// Idempotency-Key ficam guardadas
+	IdempotencyTTL time.Duration `yaml:"idempotency_ttl" env:"IDEMPOTENCY_TTL"`
+}
+
+type MongoDBConfig struct {
+	URL      string `yaml:"url" env:"MONGODB_URL"`
+	Database string `yaml:"database" env:"MONGODB_DB"`
+}
+
+type LogConfig struct {
+	Level              string `yaml:"level" env:"LOG_LEVEL"`
+	Format             string `yaml:"format" env:"LOG_FORMAT"`
+	SamplingInitial    int    `yaml:"sampling_initial" env:"LOG_SAMPLING_INITIAL"`
+	SamplingThereafter int    `yaml:"sampling_thereafter" env:"LOG_SAMPLING_THEREAFTER"`
+}
+
+// TracingConfig escolhe o exportador dos spans; o endereço do coletor OTLP e
+// a amostragem seguem as variáveis padrão do OpenTelemetry
+type TracingConfig struct {
+	Exporter    string `yaml:"exporter" env:"OTEL_TRACES_EXPORTER"`
+	ServiceName string `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+}
+
+type HealthConfig struct {
+	// CheckTimeout é o prazo de cada verificação do /readyz
+	CheckTimeout time.Duration `yaml:"check_timeout" env:"HEALTH_CHECK_TIMEOUT"`
+	// QueueSaturation é a fração da fila de lances a partir da qual a réplica
+	// deixa de estar pronta
+	QueueSaturation float64 `yaml:"queue_saturation" env:"HEALTH_QUEUE_SATURATION"`
+}
+
+type AuthConfig struct {
+	Issuer          string        `yaml:"issuer" env:"AUTH_ISSUER"`
+	AccessTokenTTL  time.Duration `yaml:"access_token_ttl" env:"ACCESS_TOKEN_TTL"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl" env:"REFRESH_TOKEN_TTL"`
+	// Sem KeysDir é gerada uma chave temporária, e os tokens deixam de valer
+	// quando a aplicação reinicia
+	KeysDir     string `yaml:"keys_dir" env:"AUTH_KEYS_DIR"`
+	ActiveKeyId string `yaml:"active_key_id" env:"AUTH_ACTIVE_KEY_ID"`
+	// BootstrapAdminEmails recebem o papel de administrador na inicialização
+	BootstrapAdminEmails []string `yaml:"bootstrap_admin_emails" env:"BOOTSTRAP_ADMIN_EMAILS"`
+}
+
+type AuctionsConfig struct {
+	// Duration é quanto tempo um leilão fica aberto
+	Duration time.Duration `yaml:"duration" env:"AUCTION_INTERVAL"`
+	// CloserSyncInterval é o intervalo em que a líder busca leilões criados
+	// nas demais réplicas
+	CloserSyncInterval time.Duration `yaml:"closer_sync_interval" env:"AUCTION_CLOSER_SYNC_INTERVAL"`
+	// StateCacheTTL é por quanto tempo um estado lido do banco é usado sem
+	// notificação de mudança
+	StateCacheTTL time.Duration `yaml:"state_cache_ttl" env:"AUCTION_STATE_CACHE_TTL"`
+	// ChangeStream acompanha as mudanças feitas pelas demais réplicas
+	ChangeStream bool `yaml:"change_stream" env:"AUCTION_CHANGE_STREAM"`
+}
+
+// LeaderConfig define a concessão disputada pelas réplicas para o fechamento
+// automático dos leilões
+type LeaderConfig struct {
+	LeaseTTL      time.Duration `yaml:"lease_ttl" env:"LEADER_LEASE_TTL"`
+	RenewInterval time.Duration `yaml:"renew_interval" env:"LEADER_RENEW_INTERVAL"`
+	RetryInterval time.Duration `yaml:"retry_interval" env:"LEADER_RETRY_INTERVAL"`
+}
+
+type BidsConfig struct {
+	QueueCapacity        int           `yaml:"queue_capacity" env:"BID_QUEUE_CAPACITY"`
+	EnqueueTimeout       time.Duration `yaml:"enqueue_timeout" env:"BID_ENQUEUE_TIMEOUT"`
+	MaxBatchSize         int           `yaml:"max_batch_size" env:"MAX_BATCH_SIZE"`
+	MaxBatchBytes        int           `yaml:"max_batch_bytes" env:"MAX_BATCH_BYTES"`
+	FlushInterval        time.Duration `yaml:"flush_interval" env:"BATCH_INSERT_INTERVAL"`
+	MaxConcurrentFlushes int           `yaml:"max_concurrent_flushes" env:"BID_MAX_CONCURRENT_FLUSHES"`
+	MaxBidsPerRequest    int           `yaml:"max_bids_per_request" env:"MAX_BIDS_PER_REQUEST"`
+	WALDir               string        `yaml:"wal_dir" env:"BID_WAL_DIR"`
+	WALSegmentSize       int64         `yaml:"wal_segment_size" env:"BID_WAL_SEGMENT_SIZE"`
+}
+
+// RateLimitConfig define os limites de lances no formato "quantidade/janela"
+type RateLimitConfig struct {
+	// Store é "memory" ou "mongo", necessário quando há várias réplicas
+	Store      string `yaml:"store" env:"RATE_LIMIT_STORE"`
+	PerUser    string `yaml:"per_user" env:"BID_RATE_LIMIT_PER_USER"`
+	PerIP      string `yaml:"per_ip" env:"BID_RATE_LIMIT_PER_IP"`
+	PerAuction string `yaml:"per_auction" env:"BID_RATE_LIMIT_PER_AUCTION"`
+}
+
+type ImagesConfig struct {
+	MaxSize          int64 `yaml:"max_size" env:"MAX_IMAGE_SIZE"`
+	MaxPerAuction    int   `yaml:"max_per_auction" env:"MAX_IMAGES_PER_AUCTION"`
+	MaxPixels        int64 `yaml:"max_pixels" env:"MAX_IMAGE_PIXELS"`
+	ThumbnailMaxSide int   `yaml:"thumbnail_max_side" env:"THUMBNAIL_MAX_SIDE"`
+}
+
+type BlobStoreConfig struct {
+	// Type é "local" ou "s3"
+	Type     string   `yaml:"type" env:"BLOB_STORE"`
+	LocalDir string   `yaml:"local_dir" env:"BLOB_LOCAL_DIR"`
+	S3       S3Config `yaml:"s3"`
+}
+
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint" env:"S3_ENDPOINT"`
+	Region    string `yaml:"region" env:"S3_REGION"`
+	Bucket    string `yaml:"bucket" env:"S3_BUCKET"`
+	AccessKey string `yaml:"access_key" env:"S3_ACCESS_KEY"`
+	SecretKey string `yaml:"secret_key" env:"S3_SECRET_KEY"`
+}
+
+// Default retorna os valores usados quando nenhuma fonte define o campo
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Address:         ":8080",
+			ShutdownTimeout: 15 * time.Second,
+			RecoveryTimeout: 2 * time.Minute,
+			IdempotencyTTL:  24 * time.Hour,
+		},
+		Log: LogConfig{
+			Level:              "info",
+			Format:             "json",
+			SamplingInitial:    100,
+			SamplingThereafter: 100,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			ServiceName: "fullcycle-auction",
+		},
+		Health: HealthConfig{
+			CheckTimeout:    2 * time.Second,
+			QueueSaturation: 0.9,
+		},
+		Auth: AuthConfig{
+			Issuer:          "fullcycle-auction",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+		Auctions: AuctionsConfig{
+			Duration:           5 * time.Minute,
+			CloserSyncInterval: 10 * time.Second,
+			StateCacheTTL:      30 * time.Second,
+		},
+		Leader: LeaderConfig{
+			LeaseTTL:      15 * time.Second,
+			RenewInterval: 5 * time.Second,
+			RetryInterval: 5 * time.Second,
+		},
+		Bids: BidsConfig{
+			QueueCapacity:        1000,
+			MaxBatchSize:         5,
+			MaxBatchBytes:        1 << 20,
+			FlushInterval:        3 * time.Minute,
+			MaxConcurrentFlushes: 8,
+			MaxBidsPerRequest:    30,
+			WALDir:               "data/wal",
+			WALSegmentSize:       16 << 20,
+		},
+		RateLimit: RateLimitConfig{
+			Store:      "memory",
+			PerUser:    "30/1m",
+			PerIP:      "120/1m",
+			PerAuction: "600/1m",
+		},
+		Images: ImagesConfig{
+			MaxSize:          5 << 20,
+			MaxPerAuction:    10,
+			MaxPixels:        40_000_000,
+			ThumbnailMaxSide: 320,
+		},
+		BlobStore: BlobStoreConfig{
+			Type:     "local",
+			LocalDir: "data/blobs",
+			S3:       S3Config{Region: "us-east-1"},
+		},
+	}
+}
+
+// Validate confere a configuração inteira e retorna todos os problemas
+// encontrados de uma vez, cada um identificado pelo caminho do campo
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	v.require(c.Server.Address != "", "server.address", "is required")
+	v.require(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout", "must be positive")
+	v.require(c.Server.RecoveryTimeout > 0, "server.recovery_timeout", "must be positive")
+	v.require(c.Server.IdempotencyTTL > 0, "server.idempotency_ttl", "must be positive")
+
+	v.require(c.MongoDB.URL != "", "mongodb.url", "is required")
+	v.require(c.MongoDB.Database != "", "mongodb.database", "is required")
+
+	v.oneOf(c.Log.Level, "log.level", "debug", "info", "warn", "error")
+	v.oneOf(c.Log.Format, "log.format", "json", "console")
+	v.require(c.Log.SamplingInitial >= 0, "log.sampling_initial", "must not be negative")
+	v.require(c.Log.SamplingThereafter >= 0, "log.sampling_thereafter", "must not be negative")
+
+	v.oneOf(c.Tracing.Exporter, "tracing.exporter", "none", "otlp", "stdout")
+	v.require(c.Tracing.ServiceName != "", "tracing.service_name", "is required")
+
+	v.require(c.Health.CheckTimeout > 0, "health.check_timeout", "must be positive")
+	v.require(c.Health.QueueSaturation > 0 && c.Health.QueueSaturation <= 1,
+		"health.queue_saturation", "must be greater than 0 and at most 1")
+
+	v.require(c.Auth.Issuer != "", "auth.issuer", "is required")
+	v.require(c.Auth.AccessTokenTTL > 0, "auth.access_token_ttl", "must be positive")
+	v.require(c.Auth.RefreshTokenTTL > c.Auth.AccessTokenTTL,
+		"auth.refresh_token_ttl", "must be longer than auth.access_token_ttl")
+	v.require(c.Auth.ActiveKeyId == "" || c.Auth.KeysDir != "",
+		"auth.active_key_id", "requires auth.keys_dir")
+
+	v.require(c.Auctions.Duration > 0, "auctions.duration", "must be positive")
+	v.require(c.Auctions.CloserSyncInterval > 0, "auctions.closer_sync_interval", "must be positive")
+	v.require(c.Auctions.StateCacheTTL > 0, "auctions.state_cache_ttl", "must be positive")
+
+	v.require(c.Leader.LeaseTTL > 0, "leader.lease_ttl", "must be positive")
+	v.require(c.Leader.RenewInterval > 0 && c.Leader.RenewInterval < c.Leader.LeaseTTL,
+		"leader.renew_interval", "must be positive and shorter than leader.lease_ttl")
+	v.require(c.Leader.RetryInterval > 0, "leader.retry_interval", "must be positive")
+
+	v.require(c.Bids.QueueCapacity >= c.Bids.MaxBatchSize,
+		"bids.queue_capacity", "must be at least bids.max_batch_size")
+	v.require(c.Bids.EnqueueTimeout >= 0, "bids.enqueue_timeout", "must not be negative")
+	v.require(c.Bids.MaxBatchSize > 0, "bids.max_batch_size", "must be positive")
+	v.require(c.Bids.MaxBatchBytes >= 0, "bids.max_batch_bytes", "must not be negative")
+	v.require(c.Bids.FlushInterval > 0, "bids.flush_interval", "must be positive")
+	v.require(c.Bids.MaxConcurrentFlushes > 0, "bids.max_concurrent_flushes", "must be positive")
+	v.require(c.Bids.MaxBidsPerRequest > 0, "bids.max_bids_per_request", "must be positive")
+	v.require(c.Bids.WALDir != "", "bids.wal_dir", "is required")
+	v.require(c.Bids.WALSegmentSize > 0, "bids.wal_segment_size", "must be positive")
+
+	v.oneOf(c.RateLimit.Store, "rate_limit.store", "memory", "mongo")
+	// Um lote cobra uma ficha por lance, então um burst menor que o maior
+	// lote permitido recusaria esses lotes para sempre
+	v.limit(c.RateLimit.PerUser, "rate_limit.per_user", c.Bids.MaxBidsPerRequest)
+	v.limit(c.RateLimit.PerIP, "rate_limit.per_ip", c.Bids.MaxBidsPerRequest)
+	v.limit(c.RateLimit.PerAuction, "rate_limit.per_auction", c.Bids.MaxBidsPerRequest)
+
+	v.require(c.Images.MaxSize > 0, "images.max_size", "must be positive")
+	v.require(c.Images.MaxPerAuction > 0, "images.max_per_auction", "must be positive")
+	v.require(c.Images.MaxPixels > 0, "images.max_pixels", "must be positive")
+	v.require(c.Images.ThumbnailMaxSide > 0, "images.thumbnail_max_side", "must be positive")
+
+	v.oneOf(c.BlobStore.Type, "blob_store.type", "local", "s3")
+	if c.BlobStore.Type == "local" {
+		v.require(c.BlobStore.LocalDir != "", "blob_store.local_dir", "is required for the local blob store")
+	}
+	if c.BlobStore.Type == "s3" {
+		v.require(c.BlobStore.S3.Endpoint != "", "blob_store.s3.endpoint", "is required for the s3 blob store")
+		v.require(c.BlobStore.S3.Bucket != "", "blob_store.s3.bucket", "is required for the s3 blob store")
+		v.require(c.BlobStore.S3.AccessKey != "", "blob_store.s3.access_key", "is required for the s3 blob store")
+		v.require(c.BlobStore.S3.SecretKey != "", "blob_store.s3.secret_key", "is required for the s3 blob store")
+	}
+
+	return errors.Join(v.errs...)
+}
+
+// Print escreve a configuração efetiva em YAML, no mesmo formato aceito pelo
+// arquivo, com as credenciais mascaradas
+func (c *Config) Print(w io.Writer) error {
+	redacted := *c
+	redacted.MongoDB.URL = redactURL(redacted.MongoDB.URL)
+	if redacted.BlobStore.S3.SecretKey != "" {
+		redacted.BlobStore.S3.SecretKey = redactedValue
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(redacted); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+const redactedValue = "******"
+
+// redactURL mascara a senha da URL de conexão
+func redactURL(value string) string {
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return value
+	}
+	return parsed.Redacted()
+}
+
+type validator struct {
+	errs []error
+}
+
+func (v *validator) require(ok bool, path, message string) {
+	if !ok {
+		v.errs = append(v.errs, fmt.Errorf("%s %s", path, message))
+	}
+}
+
+func (v *validator) oneOf(value, path string, allowed ...string) {
+	for _, option := range allowed {
+		if value == option {
+			return
+		}
+	}
+	v.errs = append(v.errs, fmt.Errorf("%s must be one of %v, got %q", path, allowed, value))
+}
+
+func (v *validator) limit(value, path string, minBurst int) {
+	limit, err := ratelimit_entity.ParseLimit(value)
+	if err != nil {
+		v.errs = append(v.errs, fmt.Errorf("%s: %w", path, err))
+		return
+	}
+	v.require(limit.Disabled() || limit.Burst >= minBurst,
+		path, "burst must be at least bids.max_bids_per_request")
+}
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv indica o arquivo YAML quando a flag --config não é usada
+const ConfigFileEnv = "CONFIG_FILE"
+
+// LoadEnvFile carrega as variáveis de um arquivo .env sem sobrescrever as que
+// já existem no ambiente; o arquivo é opcional
+func LoadEnvFile(path string) error {
+	if err := godotenv.Load(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("loading %s: %w", path, err)
+	}
+	return nil
+}
+
+// Load monta a configuração a partir dos valores padrão, do arquivo YAML, das
+// variáveis de ambiente e das flags, nessa ordem de precedência crescente, e
+// a valida. As flags da configuração são registradas em flags junto com as
+// que o chamador já registrou, e os argumentos restantes ficam em flags.Args().
+func Load(flags *flag.FlagSet, args []string) (*Config, error) {
+	config := Default()
+
+	configFile := flags.String("config", "", "YAML configuration file (env "+ConfigFileEnv+")")
+	overrides := make(map[string]string)
+	for _, f := range config.fields() {
+		flags.Var(&flagValue{path: f.path, bool: f.value.Kind() == reflect.Bool, values: overrides},
+			f.flagName(), "env "+f.env)
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	path := *configFile
+	if path == "" {
+		path = os.Getenv(ConfigFileEnv)
+	}
+	if path != "" {
+		if err := config.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	var errs []error
+	for _, f := range config.fields() {
+		if value := os.Getenv(f.env); value != "" {
+			if err := f.set(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", f.env, err))
+			}
+		}
+		if value, ok := overrides[f.path]; ok {
+			if err := f.set(value); err != nil {
+				errs = append(errs, fmt.Errorf("--%s: %w", f.flagName(), err))
+			}
+		}
+	}
+	if err := config.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return config, nil
+}
+
+// loadFile aplica o arquivo YAML; campos desconhecidos são recusados para que
+// um erro de digitação não passe despercebido
+func (c *Config) loadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening config file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("reading config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// field é um valor da configuração, com o caminho no YAML e a variável de ambiente
+type field struct {
+	path  string
+	env   string
+	value reflect.Value
+}
+
+func (f field) flagName() string {
+	return strings.ReplaceAll(f.path, "_", "-")
+}
+
+func (f field) set(raw string) error {
+	switch f.value.Interface().(type) {
+	case time.Duration:
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		f.value.SetInt(int64(duration))
+		return nil
+	case []string:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		f.value.Set(reflect.ValueOf(items))
+		return nil
+	}
+
+	switch f.value.Kind() {
+	case reflect.String:
+		f.value.SetString(raw)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		f.value.SetBool(parsed)
+	case reflect.Int, reflect.Int64:
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		f.value.SetInt(parsed)
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		f.value.SetFloat(parsed)
+	default:
+		return fmt.Errorf("unsupported type %s", f.value.Type())
+	}
+	return nil
+}
+
+// fields lista os campos com tag env, percorrendo as seções recursivamente
+func (c *Config) fields() []field {
+	return collectFields(reflect.ValueOf(c).Elem(), "")
+}
+
+func collectFields(value reflect.Value, prefix string) []field {
+	var fields []field
+	for i := 0; i < value.NumField(); i++ {
+		structField := value.Type().Field(i)
+		path := prefix + structField.Tag.Get("yaml")
+
+		if env := structField.Tag.Get("env"); env != "" {
+			fields = append(fields, field{path: path, env: env, value: value.Field(i)})
+			continue
+		}
+		if structField.Type.Kind() == reflect.Struct {
+			fields = append(fields, collectFields(value.Field(i), path+".")...)
+		}
+	}
+	return fields
+}
+
+// flagValue guarda o valor recebido na linha de comando, aplicado só depois
+// do arquivo e do ambiente
+type flagValue struct {
+	path   string
+	bool   bool
+	values map[string]string
+}
+
+func (v *flagValue) String() string {
+	if v.values == nil {
+		return ""
+	}
+	return v.values[v.path]
+}
+
+func (v *flagValue) Set(value string) error {
+	v.values[v.path] = value
+	return nil
+}
+
+// IsBoolFlag permite --auctions.change-stream sem "=true"
+func (v *flagValue) IsBoolFlag() bool {
+	return v.bool
+}
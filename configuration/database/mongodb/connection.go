@@ -5,20 +5,13 @@ import (
 	"fullcycle-auction_go/configuration/logger"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
-	"os"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
 )
 
-const (
-	MONGODB_URL = "MONGODB_URL"
-	MONGODB_DB  = "MONGODB_DB"
-)
-
-func NewMongoDBConnection(ctx context.Context) (*mongo.Database, error) {
-	mongoURL := os.Getenv(MONGODB_URL)
-	mongoDatabase := os.Getenv(MONGODB_DB)
-
+func NewMongoDBConnection(ctx context.Context, mongoURL, mongoDatabase string) (*mongo.Database, error) {
+	// Cada comando enviado ao banco vira um span filho da operação que o originou
 	client, err := mongo.Connect(
-		ctx, options.Client().ApplyURI(mongoURL))
+		ctx, options.Client().ApplyURI(mongoURL).SetMonitor(otelmongo.NewMonitor()))
 	if err != nil {
 		logger.Error("Error trying to connect to mongodb database", err)
 		return nil, err
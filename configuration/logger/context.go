@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type contextKey struct{}
+
+// contextFields são os identificadores repassados pelo contexto da
+// requisição e incluídos em todo log feito com ele
+type contextFields struct {
+	requestId string
+	userId    string
+	auctionId string
+}
+
+func fieldsFrom(ctx context.Context) contextFields {
+	fields, _ := ctx.Value(contextKey{}).(contextFields)
+	return fields
+}
+
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	fields := fieldsFrom(ctx)
+	fields.requestId = requestId
+	return context.WithValue(ctx, contextKey{}, fields)
+}
+
+func WithUserId(ctx context.Context, userId string) context.Context {
+	fields := fieldsFrom(ctx)
+	fields.userId = userId
+	return context.WithValue(ctx, contextKey{}, fields)
+}
+
+func WithAuctionId(ctx context.Context, auctionId string) context.Context {
+	fields := fieldsFrom(ctx)
+	fields.auctionId = auctionId
+	return context.WithValue(ctx, contextKey{}, fields)
+}
+
+// RequestId retorna o id da requisição guardado em ctx, ou vazio
+func RequestId(ctx context.Context) string {
+	return fieldsFrom(ctx).requestId
+}
+
+func DebugContext(ctx context.Context, message string, tags ...zap.Field) {
+	log.Load().Debug(message, withContext(ctx, tags)...)
+}
+
+func InfoContext(ctx context.Context, message string, tags ...zap.Field) {
+	log.Load().Info(message, withContext(ctx, tags)...)
+}
+
+func WarnContext(ctx context.Context, message string, tags ...zap.Field) {
+	log.Load().Warn(message, withContext(ctx, tags)...)
+}
+
+func ErrorContext(ctx context.Context, message string, err error, tags ...zap.Field) {
+	tags = append(tags, zap.NamedError("error", err))
+	log.Load().Error(message, withContext(ctx, tags)...)
+}
+
+// withContext acrescenta aos campos do log os identificadores de ctx e o
+// trace id, para cruzar o log com o rastreamento. Um campo informado na
+// chamada prevalece sobre o do contexto.
+func withContext(ctx context.Context, tags []zap.Field) []zap.Field {
+	fields := fieldsFrom(ctx)
+	tags = appendMissing(tags, "request_id", fields.requestId)
+	tags = appendMissing(tags, "user_id", fields.userId)
+	tags = appendMissing(tags, "auction_id", fields.auctionId)
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		tags = appendMissing(tags, "trace_id", spanContext.TraceID().String())
+	}
+	return tags
+}
+
+func appendMissing(tags []zap.Field, key string, value string) []zap.Field {
+	if value == "" {
+		return tags
+	}
+	for _, tag := range tags {
+		if tag.Key == key {
+			return tags
+		}
+	}
+	return append(tags, zap.String(key, value))
+}
@@ -1,38 +1,174 @@
 package logger
 
 import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+// Formatos aceitos em LOG_FORMAT e no endpoint de administração
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+// Settings é a configuração atual do logger
+type Settings struct {
+	Level  string `json:"level"`
+	Format string `json:"format"`
+	// Por segundo, cada mensagem abaixo de error é registrada SamplingInitial
+	// vezes e depois uma a cada SamplingThereafter; zero desliga a amostragem
+	SamplingInitial    int `json:"sampling_initial"`
+	SamplingThereafter int `json:"sampling_thereafter"`
+}
+
 var (
-	log *zap.Logger
+	// level é compartilhado por todas as versões do logger, então mudar o
+	// nível não exige reconstruí-lo
+	level = zap.NewAtomicLevelAt(zap.InfoLevel)
+	log   atomic.Pointer[zap.Logger]
+
+	// mutex serializa as reconfigurações
+	mutex    sync.Mutex
+	settings = Settings{Format: FormatJSON, SamplingInitial: 100, SamplingThereafter: 100}
+	output   = zapcore.Lock(os.Stdout)
 )
 
 func init() {
-	logConfiguration := zap.Config{
-		Level:    zap.NewAtomicLevelAt(zap.InfoLevel),
-		Encoding: "json",
-		EncoderConfig: zapcore.EncoderConfig{
-			MessageKey:   "message",
-			LevelKey:     "level",
-			TimeKey:      "time",
-			EncodeLevel:  zapcore.LowercaseLevelEncoder,
-			EncodeTime:   zapcore.ISO8601TimeEncoder,
-			EncodeCaller: zapcore.ShortCallerEncoder,
-		},
+	log.Store(build(settings))
+}
+
+// Configure aplica a configuração lida na inicialização
+func Configure(next Settings) error {
+	if err := validateFormat(next.Format); err != nil {
+		return err
+	}
+	if next.Level != "" {
+		if err := SetLevel(next.Level); err != nil {
+			return err
+		}
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	apply(next)
+	return nil
+}
+
+// SetLevel muda o nível mínimo registrado (debug, info, warn ou error)
+func SetLevel(levelName string) error {
+	parsed, err := zapcore.ParseLevel(levelName)
+	if err != nil || parsed < zapcore.DebugLevel || parsed > zapcore.ErrorLevel {
+		return fmt.Errorf("invalid log level %q, use debug, info, warn or error", levelName)
 	}
 
-	log, _ = logConfiguration.Build()
+	level.SetLevel(parsed)
+	return nil
+}
+
+// SetFormat troca o formato da saída (json ou console)
+func SetFormat(format string) error {
+	if err := validateFormat(format); err != nil {
+		return err
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	next := settings
+	next.Format = format
+	apply(next)
+	return nil
+}
+
+// SetOutput redireciona os registros; a CLI de administração usa a saída de
+// erro para não misturar logs com o resultado dos comandos
+func SetOutput(writer io.Writer) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	output = zapcore.Lock(zapcore.AddSync(writer))
+	apply(settings)
+}
+
+func CurrentSettings() Settings {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	current := settings
+	current.Level = level.Level().String()
+	return current
+}
+
+// Sync descarrega o buffer da saída; chamado no encerramento
+func Sync() error {
+	return log.Load().Sync()
+}
+
+func Debug(message string, tags ...zap.Field) {
+	log.Load().Debug(message, tags...)
 }
 
 func Info(message string, tags ...zap.Field) {
-	log.Info(message, tags...)
-	log.Sync()
+	log.Load().Info(message, tags...)
 }
 
 func Error(message string, err error, tags ...zap.Field) {
 	tags = append(tags, zap.NamedError("error", err))
-	log.Error(message, tags...)
-	log.Sync()
+	log.Load().Error(message, tags...)
+}
+
+func Warn(message string, tags ...zap.Field) {
+	log.Load().Warn(message, tags...)
+}
+
+func apply(next Settings) {
+	log.Store(build(next))
+	settings = next
+}
+
+func build(current Settings) *zap.Logger {
+	encoderConfig := zapcore.EncoderConfig{
+		MessageKey:   "message",
+		LevelKey:     "level",
+		TimeKey:      "time",
+		EncodeLevel:  zapcore.LowercaseLevelEncoder,
+		EncodeTime:   zapcore.ISO8601TimeEncoder,
+		EncodeCaller: zapcore.ShortCallerEncoder,
+	}
+
+	var encoder zapcore.Encoder
+	if current.Format == FormatConsole {
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	} else {
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	}
+
+	// Erros nunca são descartados pela amostragem
+	sampled := zapcore.Core(zapcore.NewCore(encoder, output, zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return l < zapcore.ErrorLevel && level.Enabled(l)
+	})))
+	if current.SamplingInitial > 0 && current.SamplingThereafter > 0 {
+		sampled = zapcore.NewSamplerWithOptions(
+			sampled, time.Second, current.SamplingInitial, current.SamplingThereafter)
+	}
+	unsampled := zapcore.NewCore(encoder, output, zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return l >= zapcore.ErrorLevel && level.Enabled(l)
+	}))
+
+	return zap.New(zapcore.NewTee(sampled, unsampled))
+}
+
+func validateFormat(format string) error {
+	if format != FormatJSON && format != FormatConsole {
+		return fmt.Errorf("invalid log format %q, use json or console", format)
+	}
+	return nil
 }
@@ -0,0 +1,140 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type buffer struct {
+	mutex sync.Mutex
+	bytes.Buffer
+}
+
+func (b *buffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.Buffer.Write(p)
+}
+
+func (b *buffer) Sync() error { return nil }
+
+func (b *buffer) lines() []string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return strings.Split(strings.TrimSpace(b.String()), "\n")
+}
+
+// captureLogs redireciona a saída para um buffer com a configuração dada,
+// restaurando a anterior ao fim do teste
+func captureLogs(t *testing.T, next Settings) *buffer {
+	captured := &buffer{}
+
+	mutex.Lock()
+	previousOutput, previousSettings, previousLevel := output, settings, level.Level()
+	output = captured
+	apply(next)
+	mutex.Unlock()
+
+	t.Cleanup(func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+		output = previousOutput
+		apply(previousSettings)
+		level.SetLevel(previousLevel)
+	})
+	return captured
+}
+
+func TestContextFieldsAreAddedToEachEntry(t *testing.T) {
+	captured := captureLogs(t, Settings{Format: FormatJSON})
+
+	ctx := WithRequestId(context.Background(), "req-1")
+	ctx = WithUserId(ctx, "user-1")
+	ctx = WithAuctionId(ctx, "auction-1")
+	InfoContext(ctx, "Bid queued", zap.String("bid_id", "bid-1"))
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal([]byte(captured.lines()[0]), &entry))
+	assert.Equal(t, "Bid queued", entry["message"])
+	assert.Equal(t, "req-1", entry["request_id"])
+	assert.Equal(t, "user-1", entry["user_id"])
+	assert.Equal(t, "auction-1", entry["auction_id"])
+	assert.Equal(t, "bid-1", entry["bid_id"])
+	assert.Equal(t, "req-1", RequestId(ctx))
+}
+
+func TestExplicitFieldOverridesContext(t *testing.T) {
+	captured := captureLogs(t, Settings{Format: FormatJSON})
+
+	ctx := WithUserId(context.Background(), "admin-1")
+	WarnContext(ctx, "Bid voided", zap.String("user_id", "bidder-1"))
+
+	line := captured.lines()[0]
+	assert.Equal(t, 1, strings.Count(line, `"user_id"`))
+	assert.Contains(t, line, `"user_id":"bidder-1"`)
+}
+
+func TestSamplingKeepsErrors(t *testing.T) {
+	captured := captureLogs(t, Settings{Format: FormatJSON, SamplingInitial: 2, SamplingThereafter: 1000})
+
+	for i := 0; i < 10; i++ {
+		Warn("Bid was not queued")
+		Error("Error trying to insert bid", nil)
+	}
+
+	warnings, errors := 0, 0
+	for _, line := range captured.lines() {
+		switch {
+		case strings.Contains(line, "Bid was not queued"):
+			warnings++
+		case strings.Contains(line, "Error trying to insert bid"):
+			errors++
+		}
+	}
+	assert.Equal(t, 2, warnings)
+	assert.Equal(t, 10, errors)
+}
+
+func TestLevelAndFormatChangeAtRuntime(t *testing.T) {
+	captured := captureLogs(t, Settings{Format: FormatJSON})
+
+	Debug("hidden")
+	require.NoError(t, SetLevel("debug"))
+	require.NoError(t, SetFormat(FormatConsole))
+	Debug("visible")
+
+	assert.Equal(t, []string{"visible"}, messages(captured))
+	assert.Equal(t, Settings{Level: zapcore.DebugLevel.String(), Format: FormatConsole}, CurrentSettings())
+	assert.Error(t, SetLevel("fatal"))
+	assert.Error(t, SetFormat("xml"))
+}
+
+func TestSetOutputKeepsSettings(t *testing.T) {
+	captureLogs(t, Settings{Format: FormatConsole})
+
+	redirected := &buffer{}
+	SetOutput(redirected)
+	Warn("Auction cancelled")
+
+	assert.Contains(t, redirected.lines()[0], "\twarn\tAuction cancelled")
+	assert.Equal(t, FormatConsole, CurrentSettings().Format)
+}
+
+func messages(captured *buffer) []string {
+	var found []string
+	for _, line := range captured.lines() {
+		if strings.Contains(line, "\tdebug\t") {
+			found = append(found, line[strings.LastIndex(line, "\t")+1:])
+		}
+	}
+	return found
+}
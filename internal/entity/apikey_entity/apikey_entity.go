@@ -0,0 +1,153 @@
+package apikey_entity
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fullcycle-auction_go/internal/internal_error"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Scope string
+
+const (
+	ScopeAuctionsRead  Scope = "auctions:read"
+	ScopeAuctionsWrite Scope = "auctions:write"
+	ScopeBidsRead      Scope = "bids:read"
+	ScopeBidsWrite     Scope = "bids:write"
+)
+
+func (s Scope) IsValid() bool {
+	switch s {
+	case ScopeAuctionsRead, ScopeAuctionsWrite, ScopeBidsRead, ScopeBidsWrite:
+		return true
+	}
+	return false
+}
+
+// secretPrefix identifica as chaves em logs e em ferramentas de detecção de segredos
+const secretPrefix = "ak_"
+
+// APIKey é uma credencial de longa duração para clientes automatizados. Ela
+// age em nome do usuário dono, limitada aos escopos concedidos; o segredo só
+// é conhecido na criação e apenas o seu hash SHA-256 é persistido.
+type APIKey struct {
+	Id     string
+	UserId string
+	Name   string
+	// Prefix são os primeiros caracteres do segredo, exibidos para que o
+	// usuário reconheça a chave sem que ela seja revelada
+	Prefix     string
+	Hash       string
+	Scopes     []Scope
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// CreateAPIKey gera uma nova chave e retorna o segredo em texto claro, que
+// deve ser entregue ao usuário uma única vez
+func CreateAPIKey(
+	userId, name string,
+	scopes []Scope,
+	expiresAt *time.Time) (*APIKey, string, *internal_error.InternalError) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, "", internal_error.NewInternalServerError("Error trying to generate API key")
+	}
+	secret := secretPrefix + base64.RawURLEncoding.EncodeToString(random)
+
+	apiKey := &APIKey{
+		Id:        uuid.New().String(),
+		UserId:    userId,
+		Name:      strings.TrimSpace(name),
+		Prefix:    secret[:len(secretPrefix)+6],
+		Hash:      HashSecret(secret),
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	}
+
+	if err := apiKey.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	return apiKey, secret, nil
+}
+
+func (k *APIKey) Validate() *internal_error.InternalError {
+	if len(k.Name) < 3 {
+		return internal_error.NewBadRequestError("API key name must have at least 3 characters")
+	}
+
+	if len(k.Scopes) == 0 {
+		return internal_error.NewBadRequestError("API key needs at least one scope")
+	}
+
+	for _, scope := range k.Scopes {
+		if !scope.IsValid() {
+			return internal_error.NewBadRequestError("Invalid API key scope " + string(scope))
+		}
+	}
+
+	if k.ExpiresAt != nil && !k.ExpiresAt.After(k.CreatedAt) {
+		return internal_error.NewBadRequestError("API key expiration must be in the future")
+	}
+
+	return nil
+}
+
+// IsActive informa se a chave ainda pode ser usada
+func (k *APIKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+func (k *APIKey) HasScope(scope Scope) bool {
+	for _, keyScope := range k.Scopes {
+		if keyScope == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// HashSecret calcula o hash usado para localizar a chave; o segredo tem 256
+// bits aleatórios, então um hash rápido é suficiente
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+type APIKeyRepositoryInterface interface {
+	CreateAPIKey(
+		ctx context.Context, apiKey *APIKey) *internal_error.InternalError
+
+	FindAPIKeyByHash(
+		ctx context.Context, hash string) (*APIKey, *internal_error.InternalError)
+
+	FindAPIKeyById(
+		ctx context.Context, keyId string) (*APIKey, *internal_error.InternalError)
+
+	FindAPIKeysByUserId(
+		ctx context.Context, userId string) ([]APIKey, *internal_error.InternalError)
+
+	RevokeAPIKey(
+		ctx context.Context, keyId string, revokedAt time.Time) *internal_error.InternalError
+
+	// SetAPIKeyExpiration é usado na rotação para encerrar a chave antiga
+	SetAPIKeyExpiration(
+		ctx context.Context, keyId string, expiresAt time.Time) *internal_error.InternalError
+
+	// TouchAPIKey registra o último uso da chave
+	TouchAPIKey(
+		ctx context.Context, keyId string, usedAt time.Time) *internal_error.InternalError
+}
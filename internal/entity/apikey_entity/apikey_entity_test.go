@@ -0,0 +1,49 @@
+package apikey_entity
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAPIKeyStoresOnlyTheHash(t *testing.T) {
+	apiKey, secret, err := CreateAPIKey(
+		"user-1", " deploy bot ", []Scope{ScopeAuctionsRead}, nil)
+	require.Nil(t, err)
+
+	assert.True(t, strings.HasPrefix(secret, "ak_"))
+	assert.True(t, strings.HasPrefix(secret, apiKey.Prefix))
+	assert.Equal(t, HashSecret(secret), apiKey.Hash)
+	assert.NotContains(t, apiKey.Hash, secret)
+	assert.Equal(t, "deploy bot", apiKey.Name)
+	assert.True(t, apiKey.HasScope(ScopeAuctionsRead))
+	assert.False(t, apiKey.HasScope(ScopeBidsWrite))
+}
+
+func TestCreateAPIKeyValidation(t *testing.T) {
+	_, _, err := CreateAPIKey("user-1", "bot", nil, nil)
+	assert.NotNil(t, err)
+
+	_, _, err = CreateAPIKey("user-1", "bot", []Scope{"auctions:delete"}, nil)
+	assert.NotNil(t, err)
+
+	past := time.Now().Add(-time.Minute)
+	_, _, err = CreateAPIKey("user-1", "bot", []Scope{ScopeBidsRead}, &past)
+	assert.NotNil(t, err)
+}
+
+func TestAPIKeyIsActive(t *testing.T) {
+	now := time.Now()
+	expiresAt := now.Add(time.Hour)
+	apiKey, _, err := CreateAPIKey("user-1", "bot", []Scope{ScopeBidsRead}, &expiresAt)
+	require.Nil(t, err)
+
+	assert.True(t, apiKey.IsActive(now))
+	assert.False(t, apiKey.IsActive(expiresAt))
+
+	apiKey.RevokedAt = &now
+	assert.False(t, apiKey.IsActive(now))
+}
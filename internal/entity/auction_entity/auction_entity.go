@@ -2,17 +2,22 @@ package auction_entity
 
 import (
 	"context"
+	"fmt"
+	"fullcycle-auction_go/internal/entity/pagination_entity"
+	"fullcycle-auction_go/internal/entity/search_entity"
 	"fullcycle-auction_go/internal/internal_error"
 	"github.com/google/uuid"
 	"time"
 )
 
 func CreateAuction(
-	productName, category, description string,
+	sellerId, productName, categoryId, category, description string,
 	condition ProductCondition) (*Auction, *internal_error.InternalError) {
 	auction := &Auction{
 		Id:          uuid.New().String(),
+		SellerId:    sellerId,
 		ProductName: productName,
+		CategoryId:  categoryId,
 		Category:    category,
 		Description: description,
 		Condition:   condition,
@@ -28,8 +33,10 @@ func CreateAuction(
 }
 
 func (au *Auction) Validate() *internal_error.InternalError {
-	if len(au.ProductName) <= 1 ||
-		len(au.Category) <= 2 ||
+	if au.SellerId == "" ||
+		len(au.ProductName) <= 1 ||
+		au.CategoryId == "" ||
+		au.Category == "" ||
 		len(au.Description) <= 10 && (au.Condition != New &&
 			au.Condition != Refurbished &&
 			au.Condition != Used) {
@@ -40,13 +47,121 @@ func (au *Auction) Validate() *internal_error.InternalError {
 }
 
 type Auction struct {
-	Id          string
+	Id string
+	// SellerId é o usuário autenticado que criou o leilão; leilões antigos
+	// ficam sem vendedor
+	SellerId    string
 	ProductName string
+	CategoryId  string
+	// Category guarda o nome da categoria no momento da criação, usado na
+	// exibição e na busca textual
 	Category    string
 	Description string
 	Condition   ProductCondition
 	Status      AuctionStatus
 	Timestamp   time.Time
+	// EndTime é preenchido pelo repositório quando não informado na criação
+	EndTime      time.Time
+	CurrentPrice float64
+	BidCount     int64
+	// Images fica ordenado por Position
+	Images []AuctionImage
+	// CancelReason e CancelledAt só existem em leilões cancelados por um administrador
+	CancelReason string
+	CancelledAt  time.Time
+	// RelistedFrom é o leilão de origem quando este foi relançado
+	RelistedFrom string
+}
+
+// AuctionImage descreve uma imagem do leilão; o conteúdo fica no blob store sob
+// ObjectKey (original) e ThumbnailKey (miniatura JPEG)
+type AuctionImage struct {
+	Id           string
+	Position     int
+	ContentType  string
+	Size         int64
+	Width        int
+	Height       int
+	ObjectKey    string
+	ThumbnailKey string
+	UploadedAt   time.Time
+}
+
+// FindImage retorna a imagem do leilão com o id informado
+func (au *Auction) FindImage(imageId string) (*AuctionImage, bool) {
+	for i := range au.Images {
+		if au.Images[i].Id == imageId {
+			return &au.Images[i], true
+		}
+	}
+	return nil, false
+}
+
+// ValidateExtension verifica se o término do leilão pode passar a ser endTime:
+// o leilão precisa estar ativo e o novo término deve ser futuro e posterior ao atual
+func (au *Auction) ValidateExtension(endTime, now time.Time) *internal_error.InternalError {
+	if au.Status != Active {
+		return internal_error.NewBadRequestError("Auction is not active")
+	}
+	if !endTime.After(now) || !endTime.After(au.EndTime) {
+		return internal_error.NewBadRequestError("New end time must be after the current end time")
+	}
+	return nil
+}
+
+// Relist cria um novo leilão ativo com os dados do produto de um leilão já
+// encerrado; imagens, lances e preço não são copiados. Com duration zerado o
+// término fica a cargo do repositório.
+func (au *Auction) Relist(duration time.Duration) (*Auction, *internal_error.InternalError) {
+	if au.Status == Active {
+		return nil, internal_error.NewBadRequestError("Only completed or cancelled auctions can be relisted")
+	}
+
+	auction, err := CreateAuction(
+		au.SellerId, au.ProductName, au.CategoryId, au.Category, au.Description, au.Condition)
+	if err != nil {
+		return nil, err
+	}
+	auction.RelistedFrom = au.Id
+	if duration > 0 {
+		auction.EndTime = auction.Timestamp.Add(duration)
+	}
+
+	return auction, nil
+}
+
+type AuctionSortField string
+
+const (
+	SortByCreatedAt    AuctionSortField = "created_at"
+	SortByEndTime      AuctionSortField = "end_time"
+	SortByCurrentPrice AuctionSortField = "current_price"
+	SortByBidCount     AuctionSortField = "bid_count"
+)
+
+// AuctionFilter reúne os filtros opcionais da listagem de leilões; valores
+// zero ou nil não restringem a busca
+type AuctionFilter struct {
+	Status AuctionStatus
+	// Statuses restringe a busca a qualquer um dos status informados; ao
+	// contrário de Status, permite filtrar apenas os leilões ativos
+	Statuses []AuctionStatus
+	Category string
+	// CategoryIds restringe a busca a qualquer uma das categorias informadas
+	CategoryIds []string
+	ProductName string
+	Condition   ProductCondition
+	MinPrice    *float64
+	MaxPrice    *float64
+	EndsAfter   *time.Time
+	EndsBefore  *time.Time
+}
+
+type AuctionPage struct {
+	Auctions  []Auction
+	NextToken string
+	// Total só é calculado na primeira página, quando a contagem é barata
+	Total *int64
 }
 
 type ProductCondition int
@@ -55,24 +170,111 @@ type AuctionStatus int
 const (
 	Active AuctionStatus = iota
 	Completed
+	// Cancelled é o leilão encerrado por um administrador sem vencedor
+	Cancelled
 )
 
+func (s AuctionStatus) String() string {
+	switch s {
+	case Active:
+		return "active"
+	case Completed:
+		return "completed"
+	case Cancelled:
+		return "cancelled"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+// ParseAuctionStatus converte o nome do status, como exibido por String
+func ParseAuctionStatus(name string) (AuctionStatus, error) {
+	for _, status := range []AuctionStatus{Active, Completed, Cancelled} {
+		if status.String() == name {
+			return status, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid auction status %q, use active, completed or cancelled", name)
+}
+
 const (
 	New ProductCondition = iota + 1
 	Used
 	Refurbished
 )
 
+type AuctionSearchHit struct {
+	Auction    Auction
+	Score      float64
+	Highlights map[string]string
+}
+
+// Pesos dos campos na relevância da busca textual
+const (
+	ProductNameSearchWeight = 10
+	CategorySearchWeight    = 5
+	DescriptionSearchWeight = 1
+)
+
+// SearchFields lista os campos pesquisáveis do leilão com seus pesos
+func (au *Auction) SearchFields() []search_entity.Field {
+	return []search_entity.Field{
+		{Name: "product_name", Text: au.ProductName, Weight: ProductNameSearchWeight},
+		{Name: "category", Text: au.Category, Weight: CategorySearchWeight},
+		{Name: "description", Text: au.Description, Weight: DescriptionSearchWeight},
+	}
+}
+
+type AuctionSearchRepositoryInterface interface {
+	// SearchAuctions retorna até limit leilões que correspondem à busca,
+	// ordenados por relevância
+	SearchAuctions(
+		ctx context.Context,
+		query *search_entity.Query,
+		filter AuctionFilter,
+		limit int) ([]AuctionSearchHit, *internal_error.InternalError)
+}
+
 type AuctionRepositoryInterface interface {
+	AuctionSearchRepositoryInterface
+
 	CreateAuction(
 		ctx context.Context,
 		auctionEntity *Auction) *internal_error.InternalError
 
 	FindAuctions(
 		ctx context.Context,
-		status AuctionStatus,
-		category, productName string) ([]Auction, *internal_error.InternalError)
+		filter AuctionFilter,
+		page pagination_entity.PageRequest) (*AuctionPage, *internal_error.InternalError)
 
 	FindAuctionById(
 		ctx context.Context, id string) (*Auction, *internal_error.InternalError)
+
+	// AddAuctionImages anexa imagens ao final da lista, falhando com bad_request
+	// se o leilão passaria a ter mais de maxImages imagens
+	AddAuctionImages(
+		ctx context.Context,
+		auctionId string,
+		images []AuctionImage,
+		maxImages int) *internal_error.InternalError
+
+	// UpdateAuctionImages substitui a lista de imagens, usado para remover e reordenar
+	UpdateAuctionImages(
+		ctx context.Context,
+		auctionId string,
+		images []AuctionImage) *internal_error.InternalError
+
+	// ForceCloseAuction encerra um leilão ativo antes do término previsto
+	ForceCloseAuction(
+		ctx context.Context, auctionId string) (*Auction, *internal_error.InternalError)
+
+	// CancelAuction encerra um leilão ativo sem vencedor
+	CancelAuction(
+		ctx context.Context, auctionId, reason string) (*Auction, *internal_error.InternalError)
+
+	// ExtendAuction adia o término de um leilão ativo para endTime
+	ExtendAuction(
+		ctx context.Context,
+		auctionId string,
+		endTime time.Time) (*Auction, *internal_error.InternalError)
 }
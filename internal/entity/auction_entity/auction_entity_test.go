@@ -0,0 +1,65 @@
+package auction_entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuctionStatusNames(t *testing.T) {
+	for _, status := range []AuctionStatus{Active, Completed, Cancelled} {
+		parsed, err := ParseAuctionStatus(status.String())
+		assert.Nil(t, err)
+		assert.Equal(t, status, parsed)
+	}
+
+	_, err := ParseAuctionStatus("closed")
+	assert.Error(t, err)
+}
+
+func TestValidateExtension(t *testing.T) {
+	now := time.Now()
+	auction := &Auction{Status: Active, EndTime: now.Add(time.Minute)}
+
+	assert.Nil(t, auction.ValidateExtension(now.Add(time.Hour), now))
+	assert.NotNil(t, auction.ValidateExtension(now.Add(30*time.Second), now),
+		"The end time cannot be brought forward")
+
+	// Um leilão que já passou do término, mas ainda não foi fechado, pode ser prorrogado
+	overdue := &Auction{Status: Active, EndTime: now.Add(-time.Minute)}
+	assert.Nil(t, overdue.ValidateExtension(now.Add(time.Minute), now))
+	assert.NotNil(t, overdue.ValidateExtension(now.Add(-30*time.Second), now))
+
+	auction.Status = Completed
+	assert.NotNil(t, auction.ValidateExtension(now.Add(time.Hour), now))
+}
+
+func TestRelistCopiesProductOnly(t *testing.T) {
+	original, err := CreateAuction("seller-1", "Wooden Chair", "category-1", "Furniture",
+		"Old chair in good condition", Used)
+	assert.Nil(t, err)
+	original.CurrentPrice = 150
+	original.BidCount = 4
+	original.Images = []AuctionImage{{Id: "image-1"}}
+
+	_, err = original.Relist(0)
+	assert.NotNil(t, err, "Active auctions cannot be relisted")
+
+	original.Status = Cancelled
+	relisted, err := original.Relist(time.Hour)
+	assert.Nil(t, err)
+	assert.NotEqual(t, original.Id, relisted.Id)
+	assert.Equal(t, original.Id, relisted.RelistedFrom)
+	assert.Equal(t, Active, relisted.Status)
+	assert.Equal(t, "seller-1", relisted.SellerId)
+	assert.Equal(t, "category-1", relisted.CategoryId)
+	assert.Zero(t, relisted.CurrentPrice)
+	assert.Zero(t, relisted.BidCount)
+	assert.Empty(t, relisted.Images)
+	assert.Equal(t, relisted.Timestamp.Add(time.Hour), relisted.EndTime)
+
+	relisted, err = original.Relist(0)
+	assert.Nil(t, err)
+	assert.True(t, relisted.EndTime.IsZero())
+}
@@ -0,0 +1,34 @@
+package auth_entity
+
+import (
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+type TokenType string
+
+const (
+	AccessToken  TokenType = "access"
+	RefreshToken TokenType = "refresh"
+)
+
+// Claims são as informações extraídas de um token válido
+type Claims struct {
+	UserId    string
+	TokenType TokenType
+	ExpiresAt time.Time
+}
+
+type TokenPair struct {
+	AccessToken      string
+	RefreshToken     string
+	AccessExpiresAt  time.Time
+	RefreshExpiresAt time.Time
+}
+
+type TokenServiceInterface interface {
+	IssueTokens(userId string) (*TokenPair, *internal_error.InternalError)
+
+	// ParseToken valida assinatura, expiração e tipo do token
+	ParseToken(token string, tokenType TokenType) (*Claims, *internal_error.InternalError)
+}
@@ -2,6 +2,7 @@ package bid_entity
 
 import (
 	"context"
+	"fullcycle-auction_go/internal/entity/pagination_entity"
 	"fullcycle-auction_go/internal/internal_error"
 	"github.com/google/uuid"
 	"time"
@@ -13,6 +14,13 @@ type Bid struct {
 	AuctionId string
 	Amount    float64
 	Timestamp time.Time
+	// Sequence é atribuído pelo repositório quando o lance é aceito e cresce
+	// de forma monotônica dentro de cada leilão
+	Sequence int64
+	// Lances anulados por um administrador continuam na listagem, mas não
+	// disputam o leilão
+	Voided     bool
+	VoidReason string
 }
 
 func CreateBid(userId, auctionId string, amount float64) (*Bid, *internal_error.InternalError) {
@@ -43,14 +51,87 @@ func (b *Bid) Validate() *internal_error.InternalError {
 	return nil
 }
 
+type BidSortField string
+
+const (
+	SortBySequence BidSortField = "sequence"
+	SortByAmount   BidSortField = "amount"
+)
+
+type BidPage struct {
+	Bids      []Bid
+	NextToken string
+	// Total só é calculado na primeira página, quando a contagem é barata
+	Total *int64
+}
+
+// Motivos de recusa de um lance pelo repositório
+const (
+	RejectionAuctionNotFound = "auction_not_found"
+	RejectionAuctionClosed   = "auction_closed"
+	// RejectionStorageError é uma falha de gravação; o lance pode ser reenviado
+	RejectionStorageError = "storage_error"
+)
+
+// Motivos de recusa anteriores ao repositório, usados nas métricas
+const (
+	RejectionInvalidBid = "invalid_bid"
+	// RejectionQueueUnavailable é a recusa por fila cheia ou em encerramento
+	RejectionQueueUnavailable = "queue_unavailable"
+	// RejectionRateLimited é a recusa de um item de lote pelo limite por leilão
+	RejectionRateLimited = "rate_limited"
+)
+
+// BidRejection informa por que um lance não foi aceito
+type BidRejection struct {
+	BidId  string
+	Reason string
+}
+
 type BidEntityRepository interface {
+	// CreateBid grava os lances aceitos; lances recusados por regra do leilão
+	// são descartados e falhas de gravação são reportadas como erro
 	CreateBid(
 		ctx context.Context,
 		bidEntities []Bid) *internal_error.InternalError
 
+	// CreateBidsWithResults segue o mesmo caminho de CreateBid, mas retorna o
+	// motivo de cada lance recusado em vez de um erro
+	CreateBidsWithResults(
+		ctx context.Context,
+		bidEntities []Bid) []BidRejection
+
 	FindBidByAuctionId(
-		ctx context.Context, auctionId string) ([]Bid, *internal_error.InternalError)
+		ctx context.Context,
+		auctionId string,
+		page pagination_entity.PageRequest) (*BidPage, *internal_error.InternalError)
 
+	// FindWinningBidByAuctionId retorna o lance de maior valor; em caso de empate
+	// vence o lance aceito primeiro (menor Sequence). Lances anulados são ignorados.
 	FindWinningBidByAuctionId(
 		ctx context.Context, auctionId string) (*Bid, *internal_error.InternalError)
+
+	// VoidBid anula o lance e recalcula o preço corrente do leilão
+	VoidBid(
+		ctx context.Context, bidId, reason string) (*Bid, *internal_error.InternalError)
+
+	// FindExistingBidIds retorna quais dos lances informados já foram gravados
+	FindExistingBidIds(
+		ctx context.Context, bidIds []string) (map[string]bool, *internal_error.InternalError)
 }
+
+// BidLogInterface guarda de forma durável os lances aceitos até que sejam
+// gravados no banco, para que sobrevivam a uma queda do processo
+type BidLogInterface interface {
+	// Append só retorna depois que o lance está no disco
+	Append(bid Bid) *internal_error.InternalError
+
+	// Ack marca os lances como gravados (ou descartados); eles não serão reprocessados
+	Ack(bidIds ...string) *internal_error.InternalError
+
+	// PendingBids retorna os lances registrados e não confirmados antes da última parada
+	PendingBids() ([]Bid, *internal_error.InternalError)
+}
+
+// WinningBidRule descreve o critério de desempate aplicado na escolha do vencedor
+const WinningBidRule = "highest amount wins; ties go to the earliest bid (lowest timestamp, then lowest sequence)"
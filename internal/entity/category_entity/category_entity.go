@@ -0,0 +1,159 @@
+package category_entity
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Category é um nó da árvore de categorias. Ancestors guarda os ids do topo da
+// árvore até o pai imediato, o que permite buscar todos os descendentes de uma
+// categoria com uma única consulta.
+type Category struct {
+	Id                string
+	Name              string
+	Slug              string
+	ParentId          string
+	Ancestors         []string
+	AllowedConditions []auction_entity.ProductCondition // vazio aceita qualquer condição
+	DefaultDuration   time.Duration                     // zero usa a duração padrão dos leilões
+}
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+func CreateCategory(
+	name, slug string,
+	parent *Category,
+	allowedConditions []auction_entity.ProductCondition,
+	defaultDuration time.Duration) (*Category, *internal_error.InternalError) {
+	category := &Category{
+		Id:                uuid.New().String(),
+		Name:              strings.TrimSpace(name),
+		Slug:              slug,
+		AllowedConditions: allowedConditions,
+		DefaultDuration:   defaultDuration,
+	}
+
+	if category.Slug == "" {
+		category.Slug = Slugify(category.Name)
+	}
+
+	category.SetParent(parent)
+
+	if err := category.Validate(); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+// SetParent posiciona a categoria abaixo de parent; nil a torna uma raiz
+func (c *Category) SetParent(parent *Category) {
+	if parent == nil {
+		c.ParentId = ""
+		c.Ancestors = []string{}
+		return
+	}
+
+	c.ParentId = parent.Id
+	c.Ancestors = append(append([]string{}, parent.Ancestors...), parent.Id)
+}
+
+func (c *Category) Validate() *internal_error.InternalError {
+	if len(c.Name) < 2 {
+		return internal_error.NewBadRequestError("Category name must have at least 2 characters")
+	}
+
+	if !slugPattern.MatchString(c.Slug) {
+		return internal_error.NewBadRequestError("Category slug must contain only lowercase letters, digits and hyphens")
+	}
+
+	for _, condition := range c.AllowedConditions {
+		if condition != auction_entity.New &&
+			condition != auction_entity.Used &&
+			condition != auction_entity.Refurbished {
+			return internal_error.NewBadRequestError("Invalid allowed condition")
+		}
+	}
+
+	if c.DefaultDuration < 0 {
+		return internal_error.NewBadRequestError("Default duration must be positive")
+	}
+
+	return nil
+}
+
+// AllowsCondition informa se leilões desta categoria podem usar a condição
+func (c *Category) AllowsCondition(condition auction_entity.ProductCondition) bool {
+	if len(c.AllowedConditions) == 0 {
+		return true
+	}
+
+	for _, allowed := range c.AllowedConditions {
+		if allowed == condition {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDescendantOf informa se a categoria está abaixo de categoryId na árvore
+func (c *Category) IsDescendantOf(categoryId string) bool {
+	for _, ancestor := range c.Ancestors {
+		if ancestor == categoryId {
+			return true
+		}
+	}
+	return false
+}
+
+// Slugify gera um slug a partir do nome, removendo acentos e pontuação
+func Slugify(name string) string {
+	var builder strings.Builder
+	lastHyphen := true
+
+	for _, r := range norm.NFD.String(strings.ToLower(name)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			builder.WriteRune(r)
+			lastHyphen = false
+		case !lastHyphen:
+			builder.WriteRune('-')
+			lastHyphen = true
+		}
+	}
+
+	return strings.TrimSuffix(builder.String(), "-")
+}
+
+type CategoryRepositoryInterface interface {
+	CreateCategory(
+		ctx context.Context, category *Category) *internal_error.InternalError
+
+	// UpdateCategory grava a categoria e, se ela mudou de posição na árvore,
+	// atualiza os ancestrais de todos os seus descendentes
+	UpdateCategory(
+		ctx context.Context, category *Category) *internal_error.InternalError
+
+	DeleteCategory(
+		ctx context.Context, id string) *internal_error.InternalError
+
+	FindCategoryById(
+		ctx context.Context, id string) (*Category, *internal_error.InternalError)
+
+	FindCategories(
+		ctx context.Context) ([]Category, *internal_error.InternalError)
+
+	// FindDescendants retorna todas as categorias abaixo de id, em qualquer nível
+	FindDescendants(
+		ctx context.Context, id string) ([]Category, *internal_error.InternalError)
+}
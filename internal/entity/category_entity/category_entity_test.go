@@ -0,0 +1,40 @@
+package category_entity
+
+import (
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlugify(t *testing.T) {
+	assert.Equal(t, "eletronicos-informatica", Slugify("  Eletrônicos & Informática "))
+	assert.Equal(t, "tv-4k", Slugify("TV 4K!"))
+}
+
+func TestCreateCategoryBuildsAncestors(t *testing.T) {
+	root, err := CreateCategory("Electronics", "", nil, nil, 0)
+	assert.Nil(t, err)
+	assert.Empty(t, root.Ancestors)
+
+	phones, err := CreateCategory("Phones", "", root, nil, 0)
+	assert.Nil(t, err)
+	smartphones, err := CreateCategory("Smartphones", "", phones, nil, 0)
+	assert.Nil(t, err)
+
+	assert.Equal(t, phones.Id, smartphones.ParentId)
+	assert.Equal(t, []string{root.Id, phones.Id}, smartphones.Ancestors)
+	assert.True(t, smartphones.IsDescendantOf(root.Id))
+	assert.False(t, root.IsDescendantOf(smartphones.Id))
+}
+
+func TestCategoryRules(t *testing.T) {
+	_, err := CreateCategory("Bad", "Not A Slug", nil, nil, 0)
+	assert.NotNil(t, err)
+
+	category, err := CreateCategory("Collectibles", "", nil,
+		[]auction_entity.ProductCondition{auction_entity.New, auction_entity.Used}, 0)
+	assert.Nil(t, err)
+	assert.True(t, category.AllowsCondition(auction_entity.Used))
+	assert.False(t, category.AllowsCondition(auction_entity.Refurbished))
+}
@@ -0,0 +1,57 @@
+package idempotency_entity
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+// Record guarda a primeira requisição feita com uma chave de idempotência e,
+// depois de concluída, a resposta que é devolvida nas repetições
+type Record struct {
+	// Key já inclui o usuário e a rota, para que chaves iguais de clientes
+	// diferentes não colidam
+	Key         string
+	Fingerprint string
+	Completed   bool
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	// ExpiresAt é o fim do bloqueio enquanto a requisição está em andamento e
+	// o fim da retenção da resposta depois de concluída
+	ExpiresAt time.Time
+}
+
+// Expired informa se o registro pode ser descartado e a chave reutilizada
+func (r *Record) Expired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+// Fingerprint identifica o conteúdo da requisição; a mesma chave com outro
+// conteúdo é um conflito
+func Fingerprint(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(path))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+type IdempotencyRepositoryInterface interface {
+	// Reserve grava o registro se a chave estiver livre (ou expirada) e
+	// retorna nil; caso contrário retorna o registro existente
+	Reserve(
+		ctx context.Context, record *Record, now time.Time) (*Record, *internal_error.InternalError)
+
+	// Complete guarda a resposta de uma chave reservada e estende sua validade
+	Complete(ctx context.Context, record *Record) *internal_error.InternalError
+
+	// Release libera uma chave reservada cuja requisição falhou, para que o
+	// cliente possa repetir
+	Release(ctx context.Context, key string) *internal_error.InternalError
+}
@@ -0,0 +1,26 @@
+package idempotency_entity
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprintDependsOnRouteAndBody(t *testing.T) {
+	body := []byte(`{"auction_id":"a","amount":10}`)
+	fingerprint := Fingerprint(http.MethodPost, "/bid", body)
+
+	assert.Equal(t, fingerprint, Fingerprint(http.MethodPost, "/bid", body))
+	assert.NotEqual(t, fingerprint, Fingerprint(http.MethodPost, "/bid", []byte(`{"auction_id":"a","amount":11}`)))
+	assert.NotEqual(t, fingerprint, Fingerprint(http.MethodPost, "/auction", body))
+}
+
+func TestRecordExpired(t *testing.T) {
+	now := time.Now()
+	record := &Record{ExpiresAt: now.Add(time.Minute)}
+
+	assert.False(t, record.Expired(now))
+	assert.True(t, record.Expired(now.Add(time.Minute)))
+}
@@ -0,0 +1,43 @@
+package lease_entity
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+// Lease é a posse temporária de um papel exclusivo entre as réplicas, como o
+// fechamento automático de leilões. Token cresce a cada nova posse e serve de
+// fencing token: operações feitas com um token antigo são recusadas.
+type Lease struct {
+	Name      string
+	HolderId  string
+	Token     int64
+	ExpiresAt time.Time
+}
+
+// HeldAt informa se a concessão ainda vale em now
+func (l *Lease) HeldAt(now time.Time) bool {
+	return now.Before(l.ExpiresAt)
+}
+
+type LeaseRepositoryInterface interface {
+	// Acquire obtém a concessão se ela estiver livre, vencida ou já for de
+	// holderId, incrementando o token; retorna nil se outra réplica a detém
+	Acquire(
+		ctx context.Context,
+		name, holderId string,
+		ttl time.Duration,
+		now time.Time) (*Lease, *internal_error.InternalError)
+
+	// Renew estende a concessão enquanto o token não mudar; retorna nil se ela
+	// foi perdida para outra réplica
+	Renew(
+		ctx context.Context,
+		lease *Lease,
+		ttl time.Duration,
+		now time.Time) (*Lease, *internal_error.InternalError)
+
+	// Release libera a concessão para que outra réplica assuma sem esperar o vencimento
+	Release(ctx context.Context, lease *Lease) *internal_error.InternalError
+}
@@ -0,0 +1,26 @@
+package pagination_entity
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// PageRequest descreve a página solicitada em uma listagem paginada por cursor.
+// Token é o valor opaco devolvido como NextToken pela página anterior.
+type PageRequest struct {
+	Limit      int
+	Token      string
+	SortBy     string
+	Descending bool
+}
+
+// EffectiveLimit aplica o limite padrão e o limite máximo permitido
+func (p PageRequest) EffectiveLimit() int {
+	if p.Limit <= 0 {
+		return DefaultLimit
+	}
+	if p.Limit > MaxLimit {
+		return MaxLimit
+	}
+	return p.Limit
+}
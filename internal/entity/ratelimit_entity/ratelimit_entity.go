@@ -0,0 +1,130 @@
+package ratelimit_entity
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/internal/internal_error"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit descreve um token bucket: Burst é a capacidade do balde e Rate a
+// quantidade de fichas repostas por segundo
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Disabled informa se o limite foi desligado na configuração
+func (l Limit) Disabled() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
+
+// ParseLimit lê limites no formato "<requisições>/<período>[:<burst>]", como
+// "10/1m" ou "5/1s:20". Sem burst, a capacidade é igual ao número de
+// requisições; "0" ou vazio desligam o limite.
+func ParseLimit(value string) (Limit, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "0" {
+		return Limit{}, nil
+	}
+
+	spec, burstValue, hasBurst := strings.Cut(value, ":")
+	countValue, periodValue, found := strings.Cut(spec, "/")
+	if !found {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: expected <requests>/<period>", value)
+	}
+
+	count, err := strconv.Atoi(countValue)
+	if err != nil || count < 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: bad request count", value)
+	}
+
+	period, err := time.ParseDuration(periodValue)
+	if err != nil || period <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: bad period", value)
+	}
+
+	burst := count
+	if hasBurst {
+		burst, err = strconv.Atoi(burstValue)
+		if err != nil || burst < 0 {
+			return Limit{}, fmt.Errorf("invalid rate limit %q: bad burst", value)
+		}
+	}
+
+	return Limit{Rate: float64(count) / period.Seconds(), Burst: burst}, nil
+}
+
+// Bucket é o estado de um balde: as fichas disponíveis no instante UpdatedAt
+type Bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+type Decision struct {
+	Allowed bool
+	// RetryAfter é quanto falta para a próxima ficha quando a requisição é negada
+	RetryAfter time.Duration
+	Remaining  int
+}
+
+// NewBucket cria um balde cheio
+func NewBucket(limit Limit, now time.Time) Bucket {
+	return Bucket{Tokens: float64(limit.Burst), UpdatedAt: now}
+}
+
+// Take repõe as fichas acumuladas desde a última atualização e tenta consumir
+// uma. O balde é alterado mesmo quando a requisição é negada, para registrar
+// a reposição.
+func (b *Bucket) Take(limit Limit, now time.Time) Decision {
+	return b.TakeN(limit, 1, now)
+}
+
+// TakeN consome n fichas de uma vez, ou nenhuma. Um custo maior que o burst
+// nunca é atendido; nesse caso RetryAfter é o tempo até o balde encher.
+func (b *Bucket) TakeN(limit Limit, n int, now time.Time) Decision {
+	if elapsed := now.Sub(b.UpdatedAt).Seconds(); elapsed > 0 {
+		b.Tokens = math.Min(float64(limit.Burst), b.Tokens+elapsed*limit.Rate)
+		b.UpdatedAt = now
+	}
+
+	cost := float64(n)
+	if b.Tokens >= cost {
+		b.Tokens -= cost
+		return Decision{Allowed: true, Remaining: int(b.Tokens)}
+	}
+
+	missing := (math.Min(cost, float64(limit.Burst)) - b.Tokens) / limit.Rate
+	return Decision{
+		Allowed:    false,
+		RetryAfter: time.Duration(math.Ceil(missing * float64(time.Second))),
+	}
+}
+
+// IdleAfter é o tempo após o qual um balde sem uso volta a ficar cheio e
+// pode ser descartado sem alterar as decisões
+func (l Limit) IdleAfter() time.Duration {
+	return time.Duration(math.Ceil(float64(l.Burst) / l.Rate * float64(time.Second)))
+}
+
+// Key identifica o balde de um sujeito em uma regra. A rota fica de fora para
+// que o mesmo usuário, IP ou leilão divida o balde entre POST /bid e
+// POST /bids/batch.
+func Key(rule, subject string) string {
+	return rule + "|" + subject
+}
+
+// RateLimitStoreInterface guarda os baldes; implementações compartilhadas
+// entre instâncias devem consumir as fichas de forma atômica. cost é a
+// quantidade de fichas consumidas, como os itens de um envio em lote.
+type RateLimitStoreInterface interface {
+	Take(
+		ctx context.Context,
+		key string,
+		limit Limit,
+		cost int,
+		now time.Time) (*Decision, *internal_error.InternalError)
+}
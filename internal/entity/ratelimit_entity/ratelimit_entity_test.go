@@ -0,0 +1,76 @@
+package ratelimit_entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	limit, err := ParseLimit("10/1m")
+	require.NoError(t, err)
+	assert.InDelta(t, 10.0/60, limit.Rate, 1e-9)
+	assert.Equal(t, 10, limit.Burst)
+
+	limit, err = ParseLimit("5/1s:20")
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, limit.Rate)
+	assert.Equal(t, 20, limit.Burst)
+
+	limit, err = ParseLimit("")
+	require.NoError(t, err)
+	assert.True(t, limit.Disabled())
+
+	for _, invalid := range []string{"10", "10/abc", "x/1s", "10/1s:x", "10/0s"} {
+		_, err := ParseLimit(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestBucketTake(t *testing.T) {
+	now := time.Now()
+	limit := Limit{Rate: 1, Burst: 2}
+	bucket := NewBucket(limit, now)
+
+	assert.True(t, bucket.Take(limit, now).Allowed)
+	assert.True(t, bucket.Take(limit, now).Allowed)
+
+	denied := bucket.Take(limit, now)
+	assert.False(t, denied.Allowed)
+	assert.Equal(t, time.Second, denied.RetryAfter)
+
+	// Meia ficha reposta: ainda negado, faltando meio segundo
+	denied = bucket.Take(limit, now.Add(500*time.Millisecond))
+	assert.False(t, denied.Allowed)
+	assert.Equal(t, 500*time.Millisecond, denied.RetryAfter)
+
+	assert.True(t, bucket.Take(limit, now.Add(time.Second)).Allowed)
+
+	// A reposição nunca passa da capacidade
+	allowed := bucket.Take(limit, now.Add(time.Hour))
+	assert.True(t, allowed.Allowed)
+	assert.Equal(t, 1, allowed.Remaining)
+}
+
+func TestBucketTakeN(t *testing.T) {
+	now := time.Now()
+	limit := Limit{Rate: 1, Burst: 5}
+	bucket := NewBucket(limit, now)
+
+	allowed := bucket.TakeN(limit, 3, now)
+	assert.True(t, allowed.Allowed)
+	assert.Equal(t, 2, allowed.Remaining)
+
+	// Sem fichas para o custo inteiro, nada é consumido
+	denied := bucket.TakeN(limit, 3, now)
+	assert.False(t, denied.Allowed)
+	assert.Equal(t, time.Second, denied.RetryAfter)
+	assert.True(t, bucket.TakeN(limit, 2, now).Allowed)
+
+	// Um custo maior que o burst nunca passa
+	denied = bucket.TakeN(limit, 6, now.Add(time.Hour))
+	assert.False(t, denied.Allowed)
+	assert.Equal(t, time.Duration(0), denied.RetryAfter)
+}
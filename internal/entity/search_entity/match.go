@@ -0,0 +1,139 @@
+package search_entity
+
+import (
+	"html"
+	"math"
+	"strings"
+	"unicode"
+)
+
+const (
+	HighlightOpen  = "<mark>"
+	HighlightClose = "</mark>"
+)
+
+// Field é um campo de texto pesquisável com seu peso na relevância
+type Field struct {
+	Name   string
+	Text   string
+	Weight float64
+}
+
+// Score calcula a relevância do documento para a busca em memória, seguindo as
+// mesmas regras do índice textual: frases são obrigatórias, termos excluídos
+// eliminam o documento e os demais termos somam relevância ponderada pelo
+// peso do campo. Retorna zero quando o documento não corresponde à busca.
+func (q *Query) Score(fields []Field) float64 {
+	var score float64
+	matchedTerm := false
+
+	for _, phrase := range q.Phrases {
+		found := false
+		for _, field := range fields {
+			if strings.Contains(strings.Join(normalizeWords(field.Text), " "), phrase) {
+				found = true
+				score += field.Weight * float64(len(strings.Fields(phrase)))
+			}
+		}
+		if !found {
+			return 0
+		}
+	}
+
+	for _, field := range fields {
+		words := normalizeWords(field.Text)
+		for _, excluded := range q.Excluded {
+			if containsWord(words, excluded) {
+				return 0
+			}
+		}
+
+		for _, term := range q.Terms {
+			frequency := 0
+			for _, word := range words {
+				if word == term {
+					frequency++
+				}
+			}
+			if frequency > 0 {
+				matchedTerm = true
+				score += field.Weight * (1 + math.Log(float64(frequency)))
+			}
+		}
+	}
+
+	if len(q.Phrases) == 0 && !matchedTerm {
+		return 0
+	}
+
+	return score
+}
+
+// Highlight devolve o texto escapado para HTML com as palavras buscadas
+// envolvidas por <mark>. Retorna vazio quando nenhuma palavra corresponde.
+func (q *Query) Highlight(text string) string {
+	targets := make(map[string]bool)
+	for _, term := range q.Terms {
+		targets[term] = true
+	}
+	for _, phrase := range q.Phrases {
+		for _, word := range strings.Fields(phrase) {
+			targets[word] = true
+		}
+	}
+
+	var builder strings.Builder
+	matched := false
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			builder.WriteString(html.EscapeString(string(runes[i])))
+			i++
+			continue
+		}
+
+		end := i
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+
+		word := string(runes[i:end])
+		if targets[strings.ToLower(word)] {
+			matched = true
+			builder.WriteString(HighlightOpen + html.EscapeString(word) + HighlightClose)
+		} else {
+			builder.WriteString(html.EscapeString(word))
+		}
+		i = end
+	}
+
+	if !matched {
+		return ""
+	}
+	return builder.String()
+}
+
+// Highlights aplica Highlight em cada campo, mantendo apenas os que tiveram
+// correspondência
+func (q *Query) Highlights(fields []Field) map[string]string {
+	highlights := make(map[string]string)
+	for _, field := range fields {
+		if highlighted := q.Highlight(field.Text); highlighted != "" {
+			highlights[field.Name] = highlighted
+		}
+	}
+	return highlights
+}
+
+func containsWord(words []string, target string) bool {
+	for _, word := range words {
+		if word == target {
+			return true
+		}
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
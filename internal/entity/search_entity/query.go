@@ -0,0 +1,143 @@
+package search_entity
+
+import (
+	"fullcycle-auction_go/internal/internal_error"
+	"strings"
+	"unicode"
+)
+
+const (
+	MaxQueryLength = 200
+	MaxQueryTerms  = 10
+)
+
+// Query é a forma normalizada de uma busca textual. Termos soltos são
+// combinados com OU, frases entre aspas precisam aparecer no documento e
+// termos precedidos de '-' excluem o documento.
+type Query struct {
+	Terms    []string
+	Phrases  []string
+	Excluded []string
+}
+
+// ParseQuery interpreta a busca do usuário descartando qualquer caractere que
+// não seja letra ou dígito, de modo que o texto nunca é repassado ao banco como
+// expressão ou operador.
+func ParseQuery(raw string) (*Query, *internal_error.InternalError) {
+	if len(raw) > MaxQueryLength {
+		return nil, internal_error.NewBadRequestError("Search query is too long")
+	}
+
+	query := &Query{}
+	termCount := 0
+	for _, token := range tokenize(raw) {
+		if termCount >= MaxQueryTerms {
+			return nil, internal_error.NewBadRequestError("Search query has too many terms")
+		}
+
+		switch {
+		case token.phrase:
+			words := normalizeWords(token.text)
+			if len(words) == 0 {
+				continue
+			}
+			if len(words) == 1 {
+				query.Terms = appendUnique(query.Terms, words[0])
+			} else {
+				query.Phrases = appendUnique(query.Phrases, strings.Join(words, " "))
+			}
+		case token.excluded:
+			for _, word := range normalizeWords(token.text) {
+				query.Excluded = appendUnique(query.Excluded, word)
+			}
+		default:
+			for _, word := range normalizeWords(token.text) {
+				query.Terms = appendUnique(query.Terms, word)
+			}
+		}
+		termCount++
+	}
+
+	if len(query.Terms) == 0 && len(query.Phrases) == 0 {
+		return nil, internal_error.NewBadRequestError("Search query must contain at least one word")
+	}
+
+	return query, nil
+}
+
+// MongoSearch monta o valor de $search do operador $text a partir dos termos
+// já normalizados
+func (q *Query) MongoSearch() string {
+	var parts []string
+	for _, phrase := range q.Phrases {
+		parts = append(parts, `"`+phrase+`"`)
+	}
+	parts = append(parts, q.Terms...)
+	for _, excluded := range q.Excluded {
+		parts = append(parts, "-"+excluded)
+	}
+	return strings.Join(parts, " ")
+}
+
+// String devolve a busca normalizada, útil para ecoar ao cliente
+func (q *Query) String() string {
+	return q.MongoSearch()
+}
+
+type queryToken struct {
+	text     string
+	phrase   bool
+	excluded bool
+}
+
+func tokenize(raw string) []queryToken {
+	var tokens []queryToken
+	runes := []rune(raw)
+
+	for i := 0; i < len(runes); {
+		switch {
+		case unicode.IsSpace(runes[i]):
+			i++
+		case runes[i] == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			tokens = append(tokens, queryToken{text: string(runes[i+1 : end]), phrase: true})
+			i = end + 1
+		default:
+			excluded := runes[i] == '-'
+			start := i
+			if excluded {
+				start++
+			}
+			end := start
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '"' {
+				end++
+			}
+			if end > start {
+				tokens = append(tokens, queryToken{text: string(runes[start:end]), excluded: excluded})
+			}
+			i = end
+		}
+	}
+
+	return tokens
+}
+
+// normalizeWords quebra o texto em palavras compostas apenas por letras e
+// dígitos, em minúsculas
+func normalizeWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,51 @@
+package search_entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQueryStripsOperators(t *testing.T) {
+	query, err := ParseQuery(`Smart.*phone $where "Brand New" -used {"$gt": 1}`)
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"smart", "phone", "where", "gt", "1"}, query.Terms)
+	assert.Equal(t, []string{"brand new"}, query.Phrases)
+	assert.Equal(t, []string{"used"}, query.Excluded)
+	assert.Equal(t, `"brand new" smart phone where gt 1 -used`, query.MongoSearch())
+}
+
+func TestParseQueryRejectsEmptyAndLongQueries(t *testing.T) {
+	_, err := ParseQuery(`"" -only ***`)
+	assert.NotNil(t, err)
+
+	_, err = ParseQuery("a b c d e f g h i j k")
+	assert.NotNil(t, err)
+}
+
+func TestScoreFollowsTextSearchRules(t *testing.T) {
+	fields := []Field{
+		{Name: "product_name", Text: "Smartphone XYZ", Weight: 10},
+		{Name: "description", Text: "Brand new smartphone, never used", Weight: 1},
+	}
+
+	query, _ := ParseQuery("smartphone")
+	assert.Greater(t, query.Score(fields), 10.0)
+
+	query, _ = ParseQuery("smartphone -used")
+	assert.Equal(t, 0.0, query.Score(fields))
+
+	query, _ = ParseQuery(`"new smartphone" tablet`)
+	assert.Greater(t, query.Score(fields), 0.0)
+
+	query, _ = ParseQuery(`"used smartphone"`)
+	assert.Equal(t, 0.0, query.Score(fields))
+}
+
+func TestHighlightEscapesAndMarksTerms(t *testing.T) {
+	query, _ := ParseQuery("phone")
+
+	assert.Equal(t, "&lt;b&gt; <mark>Phone</mark> &amp; case", query.Highlight("<b> Phone & case"))
+	assert.Equal(t, "", query.Highlight("tablet"))
+}
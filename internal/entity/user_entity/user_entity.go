@@ -3,18 +3,150 @@ package user_entity
 import (
 	"context"
 	"fullcycle-auction_go/internal/internal_error"
+	"net/mail"
+	"strings"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type User struct {
-	Id   string
-	Name string
+	Id    string
+	Name  string
+	Email string
+	// PasswordHash guarda o hash bcrypt da senha; usuários antigos, criados
+	// antes da autenticação, não têm senha e não conseguem fazer login
+	PasswordHash string
+	Roles        []Role
+	// Suspended bloqueia todas as rotas autenticadas do usuário
+	Suspended bool
+}
+
+type Role string
+
+const (
+	RoleAdmin  Role = "admin"
+	RoleSeller Role = "seller"
+	RoleBidder Role = "bidder"
+)
+
+// DefaultRoles são concedidos no cadastro; o papel de vendedor e o de
+// administrador são concedidos por um administrador
+var DefaultRoles = []Role{RoleBidder}
+
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleAdmin, RoleSeller, RoleBidder:
+		return true
+	}
+	return false
+}
+
+const (
+	MinPasswordLength = 8
+	// bcrypt ignora tudo após 72 bytes
+	MaxPasswordLength = 72
+)
+
+func CreateUser(name, email, password string) (*User, *internal_error.InternalError) {
+	user := &User{
+		Id:    uuid.New().String(),
+		Name:  name,
+		Email: NormalizeEmail(email),
+		Roles: append([]Role{}, DefaultRoles...),
+	}
+
+	if err := user.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := user.SetPassword(password); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (u *User) Validate() *internal_error.InternalError {
+	if len(u.Name) < 3 {
+		return internal_error.NewBadRequestError("Name must have at least 3 characters")
+	}
+
+	if _, err := mail.ParseAddress(u.Email); err != nil {
+		return internal_error.NewBadRequestError("Email is not a valid address")
+	}
+
+	return nil
+}
+
+func (u *User) SetPassword(password string) *internal_error.InternalError {
+	if len(password) < MinPasswordLength || len(password) > MaxPasswordLength {
+		return internal_error.NewBadRequestError("Password must have between 8 and 72 characters")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return internal_error.NewInternalServerError("Error trying to hash password")
+	}
+
+	u.PasswordHash = string(hash)
+	return nil
+}
+
+// CheckPassword compara a senha informada com o hash em tempo constante
+func (u *User) CheckPassword(password string) bool {
+	if u.PasswordHash == "" {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) == nil
+}
+
+func (u *User) HasRole(role Role) bool {
+	for _, userRole := range u.Roles {
+		if userRole == role {
+			return true
+		}
+	}
+	return false
+}
+
+// GrantRole adiciona o papel e informa se houve mudança
+func (u *User) GrantRole(role Role) bool {
+	if u.HasRole(role) {
+		return false
+	}
+	u.Roles = append(u.Roles, role)
+	return true
+}
+
+// RevokeRole remove o papel e informa se houve mudança
+func (u *User) RevokeRole(role Role) bool {
+	for i, userRole := range u.Roles {
+		if userRole == role {
+			u.Roles = append(u.Roles[:i:i], u.Roles[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
 }
 
 type UserRepositoryInterface interface {
 	FindUserById(
 		ctx context.Context, userId string) (*User, *internal_error.InternalError)
 
-	// Adicionar este método
+	FindUserByEmail(
+		ctx context.Context, email string) (*User, *internal_error.InternalError)
+
 	CreateUser(
 		ctx context.Context, user *User) *internal_error.InternalError
+
+	UpdateUserRoles(
+		ctx context.Context, userId string, roles []Role) *internal_error.InternalError
+
+	SetUserSuspended(
+		ctx context.Context, userId string, suspended bool) *internal_error.InternalError
 }
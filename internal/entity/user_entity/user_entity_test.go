@@ -0,0 +1,46 @@
+package user_entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateUserHashesPassword(t *testing.T) {
+	user, err := CreateUser("Maria", " Maria@Example.com ", "correct-horse")
+	require.Nil(t, err)
+
+	assert.Equal(t, "maria@example.com", user.Email)
+	assert.NotEqual(t, "correct-horse", user.PasswordHash)
+	assert.True(t, user.CheckPassword("correct-horse"))
+	assert.False(t, user.CheckPassword("wrong-password"))
+}
+
+func TestCreateUserValidation(t *testing.T) {
+	_, err := CreateUser("Maria", "not-an-email", "correct-horse")
+	assert.NotNil(t, err)
+
+	_, err = CreateUser("Maria", "maria@example.com", "short")
+	assert.NotNil(t, err)
+
+	// Usuários antigos, sem senha, nunca são autenticados
+	legacy := &User{Id: "legacy", Name: "Legacy"}
+	assert.False(t, legacy.CheckPassword(""))
+}
+
+func TestGrantAndRevokeRoles(t *testing.T) {
+	user, err := CreateUser("Maria", "maria@example.com", "correct-horse")
+	require.Nil(t, err)
+	assert.Equal(t, []Role{RoleBidder}, user.Roles)
+
+	assert.True(t, user.GrantRole(RoleSeller))
+	assert.False(t, user.GrantRole(RoleSeller))
+	assert.True(t, user.HasRole(RoleSeller))
+
+	assert.True(t, user.RevokeRole(RoleBidder))
+	assert.False(t, user.RevokeRole(RoleBidder))
+	assert.Equal(t, []Role{RoleSeller}, user.Roles)
+
+	assert.False(t, Role("root").IsValid())
+}
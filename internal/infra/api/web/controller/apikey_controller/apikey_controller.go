@@ -0,0 +1,114 @@
+package apikey_controller
+
+import (
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/middleware"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
+	"fullcycle-auction_go/internal/usecase/apikey_usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+type APIKeyController struct {
+	apiKeyUseCase apikey_usecase.APIKeyUseCaseInterface
+}
+
+func NewAPIKeyController(apiKeyUseCase apikey_usecase.APIKeyUseCaseInterface) *APIKeyController {
+	return &APIKeyController{
+		apiKeyUseCase: apiKeyUseCase,
+	}
+}
+
+func (u *APIKeyController) CreateAPIKey(c *gin.Context) {
+	var createInputDTO apikey_usecase.CreateAPIKeyInputDTO
+
+	if err := c.ShouldBindJSON(&createInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	apiKeyOutput, err := u.apiKeyUseCase.CreateAPIKey(
+		c.Request.Context(), middleware.AuthenticatedUserId(c), createInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, apiKeyOutput)
+}
+
+func (u *APIKeyController) FindAPIKeys(c *gin.Context) {
+	apiKeysOutput, err := u.apiKeyUseCase.FindAPIKeys(c.Request.Context(), middleware.AuthenticatedUserId(c))
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, apiKeysOutput)
+}
+
+func (u *APIKeyController) RevokeAPIKey(c *gin.Context) {
+	keyId, ok := keyIdParam(c)
+	if !ok {
+		return
+	}
+
+	if err := u.apiKeyUseCase.RevokeAPIKey(
+		c.Request.Context(), middleware.AuthenticatedUserId(c), keyId); err != nil {
+		restErr := rest_err.ConvertError(err)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (u *APIKeyController) RotateAPIKey(c *gin.Context) {
+	keyId, ok := keyIdParam(c)
+	if !ok {
+		return
+	}
+
+	// O corpo é opcional
+	var rotateInputDTO apikey_usecase.RotateAPIKeyInputDTO
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&rotateInputDTO); err != nil {
+			restErr := validation.ValidateErr(err)
+			c.JSON(restErr.Code, restErr)
+			return
+		}
+	}
+
+	apiKeyOutput, err := u.apiKeyUseCase.RotateAPIKey(
+		c.Request.Context(), middleware.AuthenticatedUserId(c), keyId, rotateInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, apiKeyOutput)
+}
+
+// keyIdParam valida o parâmetro de rota, respondendo 400 quando inválido
+func keyIdParam(c *gin.Context) (string, bool) {
+	keyId := c.Param("keyId")
+
+	if err := uuid.Validate(keyId); err != nil {
+		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   "keyId",
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return "", false
+	}
+
+	return keyId, true
+}
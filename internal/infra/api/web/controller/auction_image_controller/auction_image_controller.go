@@ -0,0 +1,178 @@
+package auction_image_controller
+
+import (
+	"errors"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/middleware"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
+	"fullcycle-auction_go/internal/usecase/auction_usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"io"
+	"net/http"
+)
+
+type AuctionImageController struct {
+	auctionImageUseCase auction_usecase.AuctionImageUseCaseInterface
+}
+
+func NewAuctionImageController(
+	auctionImageUseCase auction_usecase.AuctionImageUseCaseInterface) *AuctionImageController {
+	return &AuctionImageController{
+		auctionImageUseCase: auctionImageUseCase,
+	}
+}
+
+// UploadImages recebe um multipart/form-data com um ou mais arquivos no campo "images"
+func (u *AuctionImageController) UploadImages(c *gin.Context) {
+	auctionId, ok := uuidParam(c, "auctionId")
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, u.auctionImageUseCase.MaxUploadSize())
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			restErr := rest_err.NewBadRequestError("Request body too large")
+			c.JSON(restErr.Code, restErr)
+			return
+		}
+
+		restErr := rest_err.NewBadRequestError("Invalid multipart form", rest_err.Causes{
+			Field:   "images",
+			Message: err.Error(),
+		})
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	var uploads []auction_usecase.ImageUploadInputDTO
+	for _, fileHeader := range form.File["images"] {
+		file, err := fileHeader.Open()
+		if err != nil {
+			restErr := rest_err.NewBadRequestError("Error reading uploaded file " + fileHeader.Filename)
+			c.JSON(restErr.Code, restErr)
+			return
+		}
+
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			restErr := rest_err.NewBadRequestError("Error reading uploaded file " + fileHeader.Filename)
+			c.JSON(restErr.Code, restErr)
+			return
+		}
+
+		uploads = append(uploads, auction_usecase.ImageUploadInputDTO{
+			FileName: fileHeader.Filename,
+			Data:     data,
+		})
+	}
+
+	imagesOutput, uploadErr := u.auctionImageUseCase.UploadImages(
+		c.Request.Context(), middleware.AuthenticatedUserId(c), auctionId, uploads)
+	if uploadErr != nil {
+		restErr := rest_err.ConvertError(uploadErr)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusCreated, imagesOutput)
+}
+
+func (u *AuctionImageController) FindImage(c *gin.Context) {
+	u.serveImage(c, false)
+}
+
+func (u *AuctionImageController) FindThumbnail(c *gin.Context) {
+	u.serveImage(c, true)
+}
+
+func (u *AuctionImageController) DeleteImage(c *gin.Context) {
+	auctionId, ok := uuidParam(c, "auctionId")
+	if !ok {
+		return
+	}
+
+	imageId, ok := uuidParam(c, "imageId")
+	if !ok {
+		return
+	}
+
+	if err := u.auctionImageUseCase.DeleteImage(
+		c.Request.Context(), middleware.AuthenticatedUserId(c), auctionId, imageId); err != nil {
+		restErr := rest_err.ConvertError(err)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (u *AuctionImageController) ReorderImages(c *gin.Context) {
+	auctionId, ok := uuidParam(c, "auctionId")
+	if !ok {
+		return
+	}
+
+	var reorderInputDTO auction_usecase.ReorderImagesInputDTO
+	if err := c.ShouldBindJSON(&reorderInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	imagesOutput, err := u.auctionImageUseCase.ReorderImages(
+		c.Request.Context(), middleware.AuthenticatedUserId(c), auctionId, reorderInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, imagesOutput)
+}
+
+func (u *AuctionImageController) serveImage(c *gin.Context, thumbnail bool) {
+	auctionId, ok := uuidParam(c, "auctionId")
+	if !ok {
+		return
+	}
+
+	imageId, ok := uuidParam(c, "imageId")
+	if !ok {
+		return
+	}
+
+	imageContent, err := u.auctionImageUseCase.OpenImage(c.Request.Context(), auctionId, imageId, thumbnail)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+	defer imageContent.Body.Close()
+
+	// As chaves das imagens nunca são reaproveitadas, então o conteúdo é imutável
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.DataFromReader(http.StatusOK, -1, imageContent.ContentType, imageContent.Body, nil)
+}
+
+// uuidParam valida o parâmetro de rota, respondendo 400 quando inválido
+func uuidParam(c *gin.Context, name string) (string, bool) {
+	value := c.Param(name)
+
+	if err := uuid.Validate(value); err != nil {
+		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   name,
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return "", false
+	}
+
+	return value, true
+}
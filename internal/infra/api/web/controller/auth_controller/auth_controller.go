@@ -0,0 +1,67 @@
+package auth_controller
+
+import (
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
+	"fullcycle-auction_go/internal/infra/auth"
+	"fullcycle-auction_go/internal/usecase/auth_usecase"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type AuthController struct {
+	authUseCase auth_usecase.AuthUseCaseInterface
+	keySet      *auth.KeySet
+}
+
+func NewAuthController(authUseCase auth_usecase.AuthUseCaseInterface, keySet *auth.KeySet) *AuthController {
+	return &AuthController{
+		authUseCase: authUseCase,
+		keySet:      keySet,
+	}
+}
+
+func (u *AuthController) Login(c *gin.Context) {
+	var loginInputDTO auth_usecase.LoginInputDTO
+
+	if err := c.ShouldBindJSON(&loginInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	tokenOutput, err := u.authUseCase.Login(c.Request.Context(), loginInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, tokenOutput)
+}
+
+func (u *AuthController) Refresh(c *gin.Context) {
+	var refreshInputDTO auth_usecase.RefreshInputDTO
+
+	if err := c.ShouldBindJSON(&refreshInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	tokenOutput, err := u.authUseCase.Refresh(c.Request.Context(), refreshInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, tokenOutput)
+}
+
+// JWKS publica as chaves públicas usadas para validar os tokens
+func (u *AuthController) JWKS(c *gin.Context) {
+	c.JSON(http.StatusOK, u.keySet.JWKS())
+}
@@ -1,8 +1,8 @@
 package bid_controller
 
 import (
-	"context"
 	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/middleware"
 	"fullcycle-auction_go/internal/infra/api/web/validation"
 	"fullcycle-auction_go/internal/usecase/bid_usecase"
 	"github.com/gin-gonic/gin"
@@ -28,11 +28,16 @@ func (u *BidController) CreateBid(c *gin.Context) {
 		c.JSON(restErr.Code, restErr)
 		return
 	}
+	bidInputDTO.UserId = middleware.AuthenticatedUserId(c)
 
-	err := u.bidUseCase.CreateBid(context.Background(), bidInputDTO)
+	err := u.bidUseCase.CreateBid(c.Request.Context(), bidInputDTO)
 	if err != nil {
 		restErr := rest_err.ConvertError(err)
 
+		// Fila cheia: o cliente pode tentar de novo em instantes
+		if restErr.Code == http.StatusServiceUnavailable {
+			c.Header("Retry-After", "1")
+		}
 		c.JSON(restErr.Code, restErr)
 		return
 	}
@@ -0,0 +1,41 @@
+package bid_controller
+
+import (
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/middleware"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
+	"fullcycle-auction_go/internal/usecase/bid_usecase"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// maxBulkBidBodySize limita o corpo do envio em lote antes da validação do tamanho da lista
+const maxBulkBidBodySize = 1 << 20
+
+// CreateBids atende POST /bids/batch
+func (u *BidController) CreateBids(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBulkBidBodySize)
+
+	var bidInputs []bid_usecase.BidInputDTO
+	if err := c.ShouldBindJSON(&bidInputs); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	userId := middleware.AuthenticatedUserId(c)
+	for i := range bidInputs {
+		bidInputs[i].UserId = userId
+	}
+
+	output, err := u.bidUseCase.CreateBids(c.Request.Context(), bidInputs)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
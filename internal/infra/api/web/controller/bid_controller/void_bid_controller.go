@@ -0,0 +1,40 @@
+package bid_controller
+
+import (
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
+	"fullcycle-auction_go/internal/usecase/bid_usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+func (u *BidController) VoidBid(c *gin.Context) {
+	bidId := c.Param("bidId")
+
+	if err := uuid.Validate(bidId); err != nil {
+		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   "bidId",
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	var voidBidInput bid_usecase.VoidBidInputDTO
+	if err := c.ShouldBindJSON(&voidBidInput); err != nil {
+		errRest := validation.ValidateErr(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	bidOutput, err := u.bidUseCase.VoidBid(c.Request.Context(), bidId, voidBidInput)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, bidOutput)
+}
@@ -0,0 +1,94 @@
+package category_controller
+
+import (
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
+	"fullcycle-auction_go/internal/usecase/category_usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+type CategoryController struct {
+	categoryUseCase category_usecase.CategoryUseCaseInterface
+}
+
+func NewCategoryController(categoryUseCase category_usecase.CategoryUseCaseInterface) *CategoryController {
+	return &CategoryController{
+		categoryUseCase: categoryUseCase,
+	}
+}
+
+func (u *CategoryController) CreateCategory(c *gin.Context) {
+	var categoryInputDTO category_usecase.CategoryInputDTO
+
+	if err := c.ShouldBindJSON(&categoryInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	categoryOutput, err := u.categoryUseCase.CreateCategory(c.Request.Context(), categoryInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusCreated, categoryOutput)
+}
+
+func (u *CategoryController) UpdateCategory(c *gin.Context) {
+	categoryId, ok := categoryIdParam(c)
+	if !ok {
+		return
+	}
+
+	var categoryInputDTO category_usecase.CategoryInputDTO
+	if err := c.ShouldBindJSON(&categoryInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	categoryOutput, err := u.categoryUseCase.UpdateCategory(c.Request.Context(), categoryId, categoryInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, categoryOutput)
+}
+
+func (u *CategoryController) DeleteCategory(c *gin.Context) {
+	categoryId, ok := categoryIdParam(c)
+	if !ok {
+		return
+	}
+
+	if err := u.categoryUseCase.DeleteCategory(c.Request.Context(), categoryId); err != nil {
+		restErr := rest_err.ConvertError(err)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// categoryIdParam valida o parâmetro de rota, respondendo 400 quando inválido
+func categoryIdParam(c *gin.Context) (string, bool) {
+	categoryId := c.Param("categoryId")
+
+	if err := uuid.Validate(categoryId); err != nil {
+		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   "categoryId",
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return "", false
+	}
+
+	return categoryId, true
+}
@@ -0,0 +1,34 @@
+package category_controller
+
+import (
+	"fullcycle-auction_go/configuration/rest_err"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func (u *CategoryController) FindCategoryById(c *gin.Context) {
+	categoryId, ok := categoryIdParam(c)
+	if !ok {
+		return
+	}
+
+	categoryData, err := u.categoryUseCase.FindCategoryById(c.Request.Context(), categoryId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, categoryData)
+}
+
+func (u *CategoryController) FindCategoryTree(c *gin.Context) {
+	categories, err := u.categoryUseCase.FindCategoryTree(c.Request.Context())
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
@@ -0,0 +1,36 @@
+package health_controller
+
+import (
+	"fullcycle-auction_go/internal/infra/health"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type HealthController struct {
+	checker *health.Checker
+}
+
+func NewHealthController(checker *health.Checker) *HealthController {
+	return &HealthController{checker: checker}
+}
+
+// Liveness só confirma que o processo responde; dependências fora do ar não
+// devem fazer o orquestrador reiniciar a aplicação
+func (u *HealthController) Liveness(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{"status": health.Up})
+}
+
+// Readiness verifica as dependências e responde 503 enquanto alguma estiver
+// fora do ar, para que a réplica deixe de receber tráfego
+func (u *HealthController) Readiness(c *gin.Context) {
+	report := u.checker.Check(c.Request.Context())
+
+	status := http.StatusOK
+	if report.Status != health.Up {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, report)
+}
@@ -0,0 +1,58 @@
+package logging_controller
+
+import (
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
+	"github.com/gin-gonic/gin"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// LoggingInputDTO altera a configuração do logger; campos vazios são mantidos
+type LoggingInputDTO struct {
+	Level  string `json:"level"`
+	Format string `json:"format"`
+}
+
+type LoggingController struct{}
+
+func NewLoggingController() *LoggingController {
+	return &LoggingController{}
+}
+
+func (u *LoggingController) FindSettings(c *gin.Context) {
+	c.JSON(http.StatusOK, logger.CurrentSettings())
+}
+
+// UpdateSettings muda o nível e o formato sem reiniciar a aplicação; a
+// mudança vale só para esta réplica e até o próximo reinício
+func (u *LoggingController) UpdateSettings(c *gin.Context) {
+	var loggingInputDTO LoggingInputDTO
+	if err := c.ShouldBindJSON(&loggingInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	if loggingInputDTO.Level != "" {
+		if err := logger.SetLevel(loggingInputDTO.Level); err != nil {
+			restErr := rest_err.NewBadRequestError(err.Error())
+			c.JSON(restErr.Code, restErr)
+			return
+		}
+	}
+	if loggingInputDTO.Format != "" {
+		if err := logger.SetFormat(loggingInputDTO.Format); err != nil {
+			restErr := rest_err.NewBadRequestError(err.Error())
+			c.JSON(restErr.Code, restErr)
+			return
+		}
+	}
+
+	settings := logger.CurrentSettings()
+	logger.InfoContext(c.Request.Context(), "Logging settings changed",
+		zap.String("level", settings.Level), zap.String("format", settings.Format))
+	c.JSON(http.StatusOK, settings)
+}
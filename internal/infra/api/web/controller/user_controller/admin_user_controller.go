@@ -0,0 +1,85 @@
+package user_controller
+
+import (
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+func (u *UserController) GrantRole(c *gin.Context) {
+	userId, ok := userIdParam(c)
+	if !ok {
+		return
+	}
+
+	userOutput, err := u.userUseCase.GrantRole(
+		c.Request.Context(), middleware.AuthenticatedUserId(c), userId, c.Param("role"))
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, userOutput)
+}
+
+func (u *UserController) RevokeRole(c *gin.Context) {
+	userId, ok := userIdParam(c)
+	if !ok {
+		return
+	}
+
+	userOutput, err := u.userUseCase.RevokeRole(
+		c.Request.Context(), middleware.AuthenticatedUserId(c), userId, c.Param("role"))
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, userOutput)
+}
+
+func (u *UserController) SuspendUser(c *gin.Context) {
+	u.setSuspended(c, true)
+}
+
+func (u *UserController) UnsuspendUser(c *gin.Context) {
+	u.setSuspended(c, false)
+}
+
+func (u *UserController) setSuspended(c *gin.Context, suspended bool) {
+	userId, ok := userIdParam(c)
+	if !ok {
+		return
+	}
+
+	userOutput, err := u.userUseCase.SetSuspended(
+		c.Request.Context(), middleware.AuthenticatedUserId(c), userId, suspended)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, userOutput)
+}
+
+// userIdParam valida o parâmetro de rota, respondendo 400 quando inválido
+func userIdParam(c *gin.Context) (string, bool) {
+	userId := c.Param("userId")
+
+	if err := uuid.Validate(userId); err != nil {
+		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   "userId",
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return "", false
+	}
+
+	return userId, true
+}
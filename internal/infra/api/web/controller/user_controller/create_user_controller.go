@@ -1,7 +1,6 @@
 package user_controller
 
 import (
-	"context"
 	"fullcycle-auction_go/configuration/rest_err"
 	"fullcycle-auction_go/internal/infra/api/web/validation"
 	"fullcycle-auction_go/internal/usecase/user_usecase"
@@ -18,7 +17,7 @@ func (u *UserController) CreateUser(c *gin.Context) {
 		return
 	}
 
-	userOutput, err := u.userUseCase.CreateUser(context.Background(), userInputDTO)
+	userOutput, err := u.userUseCase.CreateUser(c.Request.Context(), userInputDTO)
 	if err != nil {
 		restErr := rest_err.ConvertError(err)
 		c.JSON(restErr.Code, restErr)
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/entity/auth_entity"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+const (
+	authenticatedUserIdKey    = "authenticated_user_id"
+	authenticatedUserRolesKey = "authenticated_user_roles"
+	authenticatedAPIKeyIdKey  = "authenticated_api_key_id"
+)
+
+// bearerClaims valida o access token do cabeçalho "Authorization: Bearer <token>"
+func bearerClaims(
+	c *gin.Context,
+	tokenService auth_entity.TokenServiceInterface) (*auth_entity.Claims, *rest_err.RestErr) {
+	scheme, token, found := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		c.Header("WWW-Authenticate", `Bearer realm="auction"`)
+		return nil, rest_err.NewUnauthorizedError("Missing bearer token")
+	}
+
+	claims, err := tokenService.ParseToken(strings.TrimSpace(token), auth_entity.AccessToken)
+	if err != nil {
+		c.Header("WWW-Authenticate", `Bearer realm="auction", error="invalid_token"`)
+		return nil, rest_err.ConvertError(err)
+	}
+
+	return claims, nil
+}
+
+// AuthenticatedUserId retorna o usuário autenticado pela política de acesso
+func AuthenticatedUserId(c *gin.Context) string {
+	return c.GetString(authenticatedUserIdKey)
+}
+
+// AuthenticatedUserRoles retorna os papéis do usuário autenticado
+func AuthenticatedUserRoles(c *gin.Context) []user_entity.Role {
+	roles, _ := c.Get(authenticatedUserRolesKey)
+	userRoles, _ := roles.([]user_entity.Role)
+	return userRoles
+}
+
+// AuthenticatedAPIKeyId retorna a chave de API usada na requisição, ou vazio
+// quando o usuário se autenticou com um access token
+func AuthenticatedAPIKeyId(c *gin.Context) string {
+	return c.GetString(authenticatedAPIKeyIdKey)
+}
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"bytes"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/entity/idempotency_entity"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	maxIdempotentBodySize     = 1 << 20
+	defaultIdempotencyLockTTL = time.Minute
+)
+
+// Idempotency faz com que repetições de uma requisição com o mesmo
+// Idempotency-Key recebam a resposta original em vez de criar de novo. A
+// chave vale por usuário e rota; reutilizá-la com outro conteúdo é um conflito.
+// Requisições sem o cabeçalho seguem normalmente.
+type Idempotency struct {
+	repository idempotency_entity.IdempotencyRepositoryInterface
+	// ttl é por quanto tempo a resposta fica guardada
+	ttl time.Duration
+	// lockTTL é por quanto tempo a chave fica presa a uma requisição em
+	// andamento, caso a instância caia antes de responder
+	lockTTL time.Duration
+	now     func() time.Time
+}
+
+func NewIdempotency(
+	repository idempotency_entity.IdempotencyRepositoryInterface, ttl time.Duration) *Idempotency {
+	return &Idempotency{
+		repository: repository,
+		ttl:        ttl,
+		lockTTL:    defaultIdempotencyLockTTL,
+		now:        time.Now,
+	}
+}
+
+func (i *Idempotency) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idempotencyKey := c.GetHeader(IdempotencyKeyHeader)
+		if idempotencyKey == "" {
+			c.Next()
+			return
+		}
+		if len(idempotencyKey) > maxIdempotencyKeyLength {
+			restErr := rest_err.NewBadRequestError("Idempotency-Key must have at most 255 characters")
+			c.AbortWithStatusJSON(restErr.Code, restErr)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxIdempotentBodySize+1))
+		if err != nil || len(body) > maxIdempotentBodySize {
+			restErr := rest_err.NewBadRequestError("Request body is too large for an idempotent request")
+			c.AbortWithStatusJSON(restErr.Code, restErr)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		now := i.now()
+		record := &idempotency_entity.Record{
+			Key:         routeKey(c.Request.Method, c.FullPath()) + "|" + AuthenticatedUserId(c) + "|" + idempotencyKey,
+			Fingerprint: idempotency_entity.Fingerprint(c.Request.Method, c.Request.URL.RequestURI(), body),
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(i.lockTTL),
+		}
+
+		existing, reserveErr := i.repository.Reserve(c.Request.Context(), record, now)
+		if reserveErr != nil {
+			restErr := rest_err.ConvertError(reserveErr)
+			c.AbortWithStatusJSON(restErr.Code, restErr)
+			return
+		}
+		if existing != nil {
+			i.replay(c, existing, record.Fingerprint)
+			return
+		}
+
+		writer := &capturingResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		i.store(c, record, writer)
+	}
+}
+
+// replay responde uma repetição com o resultado da requisição original
+func (i *Idempotency) replay(c *gin.Context, existing *idempotency_entity.Record, fingerprint string) {
+	if existing.Fingerprint != fingerprint {
+		restErr := rest_err.NewConflictError("Idempotency-Key was already used with a different request")
+		c.AbortWithStatusJSON(restErr.Code, restErr)
+		return
+	}
+	if !existing.Completed {
+		c.Header("Retry-After", "1")
+		restErr := rest_err.NewConflictError("A request with this Idempotency-Key is still in progress")
+		c.AbortWithStatusJSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Header(IdempotentReplayedHeader, "true")
+	if len(existing.Body) == 0 {
+		c.AbortWithStatus(existing.StatusCode)
+		return
+	}
+	c.Abort()
+	c.Data(existing.StatusCode, existing.ContentType, existing.Body)
+}
+
+// store guarda a resposta para as repetições. Falhas do servidor e recusas
+// temporárias (429 e 503) liberam a chave para que o cliente tente de novo.
+func (i *Idempotency) store(
+	c *gin.Context, record *idempotency_entity.Record, writer *capturingResponseWriter) {
+	status := writer.Status()
+	if status >= http.StatusInternalServerError || status == http.StatusTooManyRequests {
+		if err := i.repository.Release(c.Request.Context(), record.Key); err != nil {
+			logger.ErrorContext(c.Request.Context(), "Error trying to release idempotency key", err)
+		}
+		return
+	}
+
+	record.Completed = true
+	record.StatusCode = status
+	record.ContentType = writer.Header().Get("Content-Type")
+	record.Body = writer.body.Bytes()
+	record.ExpiresAt = i.now().Add(i.ttl)
+
+	if err := i.repository.Complete(c.Request.Context(), record); err != nil {
+		// A resposta já foi enviada; uma repetição vai encontrar a chave presa
+		// até o fim do bloqueio
+		logger.ErrorContext(c.Request.Context(), "Error trying to store idempotent response", err,
+			zap.String("route", c.FullPath()))
+	}
+}
+
+// capturingResponseWriter copia o corpo da resposta enquanto ele é enviado
+type capturingResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *capturingResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *capturingResponseWriter) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
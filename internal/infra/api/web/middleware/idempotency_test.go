@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/idempotency_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryIdempotencyRepository struct {
+	mutex   sync.Mutex
+	records map[string]idempotency_entity.Record
+}
+
+func (r *memoryIdempotencyRepository) Reserve(
+	ctx context.Context,
+	record *idempotency_entity.Record,
+	now time.Time) (*idempotency_entity.Record, *internal_error.InternalError) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if existing, ok := r.records[record.Key]; ok && !existing.Expired(now) {
+		return &existing, nil
+	}
+	r.records[record.Key] = *record
+	return nil, nil
+}
+
+func (r *memoryIdempotencyRepository) Complete(
+	ctx context.Context, record *idempotency_entity.Record) *internal_error.InternalError {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.records[record.Key] = *record
+	return nil
+}
+
+func (r *memoryIdempotencyRepository) Release(
+	ctx context.Context, key string) *internal_error.InternalError {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.records, key)
+	return nil
+}
+
+// newIdempotentRouter responde com o número de execuções do handler, ou com
+// responseStatus quando ele é definido
+func newIdempotentRouter(now *time.Time, responseStatus *int) (*gin.Engine, *int) {
+	idempotency := NewIdempotency(
+		&memoryIdempotencyRepository{records: make(map[string]idempotency_entity.Record)}, time.Hour)
+	idempotency.now = func() time.Time { return *now }
+
+	executions := 0
+	router := gin.New()
+	router.POST("/auction", func(c *gin.Context) {
+		c.Set(authenticatedUserIdKey, c.GetHeader("X-User"))
+	}, idempotency.Handler(), func(c *gin.Context) {
+		executions++
+		if *responseStatus != 0 {
+			c.Status(*responseStatus)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"execution": executions})
+	})
+	return router, &executions
+}
+
+func postAuction(router *gin.Engine, userId, key, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/auction", strings.NewReader(body))
+	request.Header.Set("X-User", userId)
+	if key != "" {
+		request.Header.Set(IdempotencyKeyHeader, key)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestIdempotencyReplaysStoredResponse(t *testing.T) {
+	now := time.Now()
+	responseStatus := 0
+	router, executions := newIdempotentRouter(&now, &responseStatus)
+
+	first := postAuction(router, "user-1", "key-1", `{"product_name":"bike"}`)
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, `{"execution":1}`, first.Body.String())
+
+	retry := postAuction(router, "user-1", "key-1", `{"product_name":"bike"}`)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, `{"execution":1}`, retry.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", retry.Header().Get("Content-Type"))
+	assert.Equal(t, "true", retry.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, 1, *executions)
+
+	// A mesma chave de outro usuário, ou uma requisição sem chave, executa de novo
+	assert.Equal(t, `{"execution":2}`, postAuction(router, "user-2", "key-1", `{"product_name":"bike"}`).Body.String())
+	assert.Equal(t, `{"execution":3}`, postAuction(router, "user-1", "", `{"product_name":"bike"}`).Body.String())
+
+	// Depois do TTL a chave pode ser reutilizada
+	now = now.Add(time.Hour)
+	assert.Equal(t, `{"execution":4}`, postAuction(router, "user-1", "key-1", `{"product_name":"bike"}`).Body.String())
+}
+
+func TestIdempotencyRejectsReusedKeyWithDifferentPayload(t *testing.T) {
+	now := time.Now()
+	responseStatus := 0
+	router, executions := newIdempotentRouter(&now, &responseStatus)
+
+	assert.Equal(t, http.StatusCreated, postAuction(router, "user-1", "key-1", `{"product_name":"bike"}`).Code)
+
+	conflict := postAuction(router, "user-1", "key-1", `{"product_name":"car"}`)
+	assert.Equal(t, http.StatusConflict, conflict.Code)
+	assert.Equal(t, 1, *executions)
+}
+
+func TestIdempotencyReleasesKeyOnServerError(t *testing.T) {
+	now := time.Now()
+	responseStatus := http.StatusServiceUnavailable
+	router, executions := newIdempotentRouter(&now, &responseStatus)
+
+	assert.Equal(t, http.StatusServiceUnavailable, postAuction(router, "user-1", "key-1", `{}`).Code)
+
+	responseStatus = http.StatusCreated
+	retry := postAuction(router, "user-1", "key-1", `{}`)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Empty(t, retry.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, 2, *executions)
+
+	// Respostas sem corpo também são repetidas
+	replayed := postAuction(router, "user-1", "key-1", `{}`)
+	assert.Equal(t, http.StatusCreated, replayed.Code)
+	assert.Equal(t, "true", replayed.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, 2, *executions)
+}
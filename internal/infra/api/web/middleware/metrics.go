@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"fullcycle-auction_go/internal/infra/metrics"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute agrupa as requisições sem rota registrada, que de outra
+// forma criariam uma série por caminho recebido
+const unmatchedRoute = "unmatched"
+
+// Metrics conta as requisições e mede sua duração por rota. Deve ser o
+// primeiro middleware, para incluir as requisições recusadas pelos demais.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		started := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		metrics.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(started))
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"fullcycle-auction_go/internal/infra/metrics"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsLabelsRequestsByRoutePattern(t *testing.T) {
+	router := gin.New()
+	router.Use(Metrics())
+	router.GET("/metrics-test/:auctionId", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics-test/a1", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics-test/a2", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics-test-missing/a3", nil))
+
+	recorder := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := recorder.Body.String()
+
+	assert.Contains(t, body,
+		`auction_http_requests_total{method="GET",route="/metrics-test/:auctionId",status="204"} 2`)
+	assert.Contains(t, body, `route="unmatched",status="404"`)
+	assert.False(t, strings.Contains(body, "/metrics-test/a1"))
+}
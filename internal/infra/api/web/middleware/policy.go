@@ -0,0 +1,240 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/entity/apikey_entity"
+	"fullcycle-auction_go/internal/entity/auth_entity"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"github.com/gin-gonic/gin"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// APIKeyHeader é o cabeçalho usado pelos clientes automatizados
+const APIKeyHeader = "X-API-Key"
+
+// Rule descreve quem pode acessar uma rota. Chaves de API só são aceitas nas
+// rotas com escopo definido (WithScope) e precisam ter esse escopo.
+type Rule struct {
+	public bool
+	roles  []user_entity.Role
+	scope  apikey_entity.Scope
+}
+
+// Public libera a rota sem autenticação
+func Public() Rule {
+	return Rule{public: true}
+}
+
+// Authenticated exige apenas um usuário autenticado e não suspenso
+func Authenticated() Rule {
+	return Rule{}
+}
+
+// RequireRoles exige um usuário autenticado com ao menos um dos papéis
+func RequireRoles(roles ...user_entity.Role) Rule {
+	return Rule{roles: roles}
+}
+
+// WithScope permite o acesso por chave de API com o escopo informado
+func (r Rule) WithScope(scope apikey_entity.Scope) Rule {
+	r.scope = scope
+	return r
+}
+
+func (r Rule) allows(user *user_entity.User) bool {
+	if len(r.roles) == 0 {
+		return true
+	}
+	for _, role := range r.roles {
+		if user.HasRole(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// Policy associa cada rota (método e caminho registrados no gin) a uma regra.
+// Rotas sem regra são negadas, então uma rota nova nunca fica aberta por
+// esquecimento.
+type Policy struct {
+	rules            map[string]Rule
+	tokenService     auth_entity.TokenServiceInterface
+	userRepository   user_entity.UserRepositoryInterface
+	apiKeyRepository apikey_entity.APIKeyRepositoryInterface
+	now              func() time.Time
+}
+
+func NewPolicy(
+	tokenService auth_entity.TokenServiceInterface,
+	userRepository user_entity.UserRepositoryInterface,
+	apiKeyRepository apikey_entity.APIKeyRepositoryInterface) *Policy {
+	return &Policy{
+		rules:            make(map[string]Rule),
+		tokenService:     tokenService,
+		userRepository:   userRepository,
+		apiKeyRepository: apiKeyRepository,
+		now:              time.Now,
+	}
+}
+
+func (p *Policy) Set(method, path string, rule Rule) {
+	p.rules[routeKey(method, path)] = rule
+}
+
+// Verify confere se todas as rotas registradas no router têm regra
+func (p *Policy) Verify(routes gin.RoutesInfo) error {
+	var missing []string
+	for _, route := range routes {
+		if _, ok := p.rules[routeKey(route.Method, route.Path)]; !ok {
+			missing = append(missing, routeKey(route.Method, route.Path))
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("routes without access policy: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func (p *Policy) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.FullPath()
+		if path == "" {
+			// Rota inexistente: o gin responde 404
+			c.Next()
+			return
+		}
+
+		rule, ok := p.rules[routeKey(c.Request.Method, path)]
+		if !ok {
+			p.deny(c, rest_err.NewForbiddenError("Access denied"), "no_policy", "", nil)
+			return
+		}
+
+		apiKeySecret := c.GetHeader(APIKeyHeader)
+		if rule.public && apiKeySecret == "" {
+			c.Next()
+			return
+		}
+
+		var userId string
+		if apiKeySecret != "" {
+			apiKey, ok := p.authenticateAPIKey(c, apiKeySecret, rule)
+			if !ok {
+				return
+			}
+			userId = apiKey.UserId
+			c.Set(authenticatedAPIKeyIdKey, apiKey.Id)
+		} else {
+			claims, restErr := bearerClaims(c, p.tokenService)
+			if restErr != nil {
+				p.deny(c, restErr, "invalid_credentials", "", rule.roles)
+				return
+			}
+			userId = claims.UserId
+		}
+
+		user, err := p.userRepository.FindUserById(c.Request.Context(), userId)
+		if err != nil {
+			if err.Err == "not_found" {
+				p.deny(c, rest_err.NewUnauthorizedError("Invalid credentials"), "unknown_user", userId, rule.roles)
+				return
+			}
+			restErr := rest_err.ConvertError(err)
+			c.AbortWithStatusJSON(restErr.Code, restErr)
+			return
+		}
+
+		if user.Suspended {
+			p.deny(c, rest_err.NewForbiddenError("User is suspended"), "user_suspended", user.Id, rule.roles)
+			return
+		}
+
+		if !rule.allows(user) {
+			p.deny(c, rest_err.NewForbiddenError("Access denied"), "missing_role", user.Id, rule.roles)
+			return
+		}
+
+		c.Set(authenticatedUserIdKey, user.Id)
+		c.Request = c.Request.WithContext(logger.WithUserId(c.Request.Context(), user.Id))
+		c.Set(authenticatedUserRolesKey, user.Roles)
+		c.Next()
+	}
+}
+
+// authenticateAPIKey valida a chave e o escopo exigido pela rota, registrando o uso
+func (p *Policy) authenticateAPIKey(c *gin.Context, secret string, rule Rule) (*apikey_entity.APIKey, bool) {
+	apiKey, err := p.apiKeyRepository.FindAPIKeyByHash(c.Request.Context(), apikey_entity.HashSecret(secret))
+	if err != nil {
+		if err.Err == "not_found" {
+			p.deny(c, rest_err.NewUnauthorizedError("Invalid API key"), "invalid_api_key", "", rule.roles)
+			return nil, false
+		}
+		restErr := rest_err.ConvertError(err)
+		c.AbortWithStatusJSON(restErr.Code, restErr)
+		return nil, false
+	}
+
+	now := p.now()
+	if !apiKey.IsActive(now) {
+		p.deny(c, rest_err.NewUnauthorizedError("API key is expired or revoked"),
+			"inactive_api_key", apiKey.UserId, rule.roles)
+		return nil, false
+	}
+
+	if rule.scope == "" {
+		p.deny(c, rest_err.NewForbiddenError("This route does not accept API keys"),
+			"api_key_not_allowed", apiKey.UserId, rule.roles)
+		return nil, false
+	}
+
+	if !apiKey.HasScope(rule.scope) {
+		p.deny(c, rest_err.NewForbiddenError(fmt.Sprintf("API key is missing the %s scope", rule.scope)),
+			"missing_scope", apiKey.UserId, rule.roles)
+		return nil, false
+	}
+
+	// O registro de uso não deve atrasar a requisição
+	go func(keyId string) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		p.apiKeyRepository.TouchAPIKey(ctx, keyId, now)
+	}(apiKey.Id)
+
+	return apiKey, true
+}
+
+// deny registra o motivo da negação e interrompe a requisição
+func (p *Policy) deny(
+	c *gin.Context,
+	restErr *rest_err.RestErr,
+	reason, userId string,
+	requiredRoles []user_entity.Role) {
+	roles := make([]string, 0, len(requiredRoles))
+	for _, role := range requiredRoles {
+		roles = append(roles, string(role))
+	}
+
+	logger.WarnContext(c.Request.Context(), "Authorization denied",
+		zap.String("reason", reason),
+		zap.String("method", c.Request.Method),
+		zap.String("route", c.FullPath()),
+		zap.String("user_id", userId),
+		zap.Strings("required_roles", roles),
+		zap.String("client_ip", c.ClientIP()),
+		zap.Int("status", restErr.Code))
+
+	c.AbortWithStatusJSON(restErr.Code, restErr)
+}
+
+func routeKey(method, path string) string {
+	return method + " " + path
+}
@@ -0,0 +1,244 @@
+package middleware
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/apikey_entity"
+	"fullcycle-auction_go/internal/entity/auth_entity"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTokenService aceita tokens no formato "token-<userId>"
+type fakeTokenService struct{}
+
+func (fakeTokenService) IssueTokens(userId string) (*auth_entity.TokenPair, *internal_error.InternalError) {
+	return &auth_entity.TokenPair{AccessToken: "token-" + userId}, nil
+}
+
+func (fakeTokenService) ParseToken(
+	token string, tokenType auth_entity.TokenType) (*auth_entity.Claims, *internal_error.InternalError) {
+	userId, found := strings.CutPrefix(token, "token-")
+	if !found {
+		return nil, internal_error.NewUnauthorizedError("Invalid token")
+	}
+	return &auth_entity.Claims{UserId: userId, TokenType: tokenType}, nil
+}
+
+type fakeUserRepository struct {
+	users map[string]*user_entity.User
+}
+
+func (f *fakeUserRepository) FindUserById(
+	ctx context.Context, userId string) (*user_entity.User, *internal_error.InternalError) {
+	user, ok := f.users[userId]
+	if !ok {
+		return nil, internal_error.NewNotFoundError("User not found")
+	}
+	return user, nil
+}
+
+func (f *fakeUserRepository) FindUserByEmail(
+	ctx context.Context, email string) (*user_entity.User, *internal_error.InternalError) {
+	return nil, internal_error.NewNotFoundError("User not found")
+}
+
+func (f *fakeUserRepository) CreateUser(ctx context.Context, user *user_entity.User) *internal_error.InternalError {
+	return nil
+}
+
+func (f *fakeUserRepository) UpdateUserRoles(
+	ctx context.Context, userId string, roles []user_entity.Role) *internal_error.InternalError {
+	return nil
+}
+
+func (f *fakeUserRepository) SetUserSuspended(
+	ctx context.Context, userId string, suspended bool) *internal_error.InternalError {
+	return nil
+}
+
+type fakeAPIKeyRepository struct {
+	mutex   sync.Mutex
+	keys    map[string]*apikey_entity.APIKey
+	secrets map[string]string
+	touched []string
+}
+
+func (f *fakeAPIKeyRepository) CreateAPIKey(ctx context.Context, apiKey *apikey_entity.APIKey) *internal_error.InternalError {
+	return nil
+}
+
+func (f *fakeAPIKeyRepository) FindAPIKeyByHash(
+	ctx context.Context, hash string) (*apikey_entity.APIKey, *internal_error.InternalError) {
+	for _, apiKey := range f.keys {
+		if apiKey.Hash == hash {
+			return apiKey, nil
+		}
+	}
+	return nil, internal_error.NewNotFoundError("API key not found")
+}
+
+func (f *fakeAPIKeyRepository) FindAPIKeyById(
+	ctx context.Context, keyId string) (*apikey_entity.APIKey, *internal_error.InternalError) {
+	return nil, internal_error.NewNotFoundError("API key not found")
+}
+
+func (f *fakeAPIKeyRepository) FindAPIKeysByUserId(
+	ctx context.Context, userId string) ([]apikey_entity.APIKey, *internal_error.InternalError) {
+	return nil, nil
+}
+
+func (f *fakeAPIKeyRepository) RevokeAPIKey(
+	ctx context.Context, keyId string, revokedAt time.Time) *internal_error.InternalError {
+	return nil
+}
+
+func (f *fakeAPIKeyRepository) SetAPIKeyExpiration(
+	ctx context.Context, keyId string, expiresAt time.Time) *internal_error.InternalError {
+	return nil
+}
+
+func (f *fakeAPIKeyRepository) TouchAPIKey(
+	ctx context.Context, keyId string, usedAt time.Time) *internal_error.InternalError {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.touched = append(f.touched, keyId)
+	return nil
+}
+
+// addAPIKey cria uma chave no repositório falso e guarda o segredo pelo nome
+func (f *fakeAPIKeyRepository) addAPIKey(t *testing.T, name, userId string, scope apikey_entity.Scope) {
+	apiKey, secret, err := apikey_entity.CreateAPIKey(userId, name, []apikey_entity.Scope{scope}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.keys[apiKey.Id] = apiKey
+	f.secrets[name] = secret
+}
+
+func newTestRouter(t *testing.T) (*gin.Engine, *Policy, *fakeAPIKeyRepository) {
+	gin.SetMode(gin.TestMode)
+
+	apiKeyRepository := &fakeAPIKeyRepository{
+		keys:    map[string]*apikey_entity.APIKey{},
+		secrets: map[string]string{},
+	}
+	apiKeyRepository.addAPIKey(t, "bidder-bot", "bidder", apikey_entity.ScopeBidsWrite)
+	apiKeyRepository.addAPIKey(t, "reader-bot", "bidder", apikey_entity.ScopeAuctionsRead)
+	apiKeyRepository.addAPIKey(t, "suspended-bot", "suspended", apikey_entity.ScopeBidsWrite)
+
+	policy := NewPolicy(fakeTokenService{}, &fakeUserRepository{users: map[string]*user_entity.User{
+		"admin":     {Id: "admin", Roles: []user_entity.Role{user_entity.RoleAdmin}},
+		"bidder":    {Id: "bidder", Roles: []user_entity.Role{user_entity.RoleBidder}},
+		"suspended": {Id: "suspended", Roles: []user_entity.Role{user_entity.RoleBidder}, Suspended: true},
+	}}, apiKeyRepository)
+
+	router := gin.New()
+	router.Use(policy.Handler())
+
+	handler := func(c *gin.Context) {
+		c.String(http.StatusOK, AuthenticatedUserId(c))
+	}
+
+	policy.Set(http.MethodGet, "/public", Public().WithScope(apikey_entity.ScopeAuctionsRead))
+	router.GET("/public", handler)
+	policy.Set(http.MethodPost, "/bid", RequireRoles(user_entity.RoleBidder).WithScope(apikey_entity.ScopeBidsWrite))
+	router.POST("/bid", handler)
+	policy.Set(http.MethodPost, "/admin/void", RequireRoles(user_entity.RoleAdmin))
+	router.POST("/admin/void", handler)
+	// Rota registrada sem regra
+	router.GET("/forgotten", handler)
+
+	return router, policy, apiKeyRepository
+}
+
+func doRequest(router *gin.Engine, method, path, token string, headers ...string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		request.Header.Set(headers[i], headers[i+1])
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestPolicyEnforcesRoles(t *testing.T) {
+	router, _, _ := newTestRouter(t)
+
+	assert.Equal(t, http.StatusOK, doRequest(router, http.MethodGet, "/public", "").Code)
+
+	assert.Equal(t, http.StatusUnauthorized, doRequest(router, http.MethodPost, "/bid", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, doRequest(router, http.MethodPost, "/bid", "garbage").Code)
+	assert.Equal(t, http.StatusUnauthorized, doRequest(router, http.MethodPost, "/bid", "token-ghost").Code)
+
+	response := doRequest(router, http.MethodPost, "/bid", "token-bidder")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "bidder", response.Body.String())
+
+	assert.Equal(t, http.StatusForbidden, doRequest(router, http.MethodPost, "/admin/void", "token-bidder").Code)
+	assert.Equal(t, http.StatusOK, doRequest(router, http.MethodPost, "/admin/void", "token-admin").Code)
+
+	// Administradores não herdam automaticamente os demais papéis
+	assert.Equal(t, http.StatusForbidden, doRequest(router, http.MethodPost, "/bid", "token-admin").Code)
+}
+
+func TestPolicyDeniesSuspendedUsersAndRoutesWithoutRule(t *testing.T) {
+	router, policy, _ := newTestRouter(t)
+
+	assert.Equal(t, http.StatusForbidden, doRequest(router, http.MethodPost, "/bid", "token-suspended").Code)
+	assert.Equal(t, http.StatusForbidden, doRequest(router, http.MethodGet, "/forgotten", "token-admin").Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(router, http.MethodGet, "/missing", "").Code)
+
+	err := policy.Verify(router.Routes())
+	assert.ErrorContains(t, err, "GET /forgotten")
+}
+
+func TestPolicyAcceptsScopedAPIKeys(t *testing.T) {
+	router, _, apiKeyRepository := newTestRouter(t)
+
+	withKey := func(method, path, name string) *httptest.ResponseRecorder {
+		return doRequest(router, method, path, "", APIKeyHeader, apiKeyRepository.secrets[name])
+	}
+
+	response := withKey(http.MethodPost, "/bid", "bidder-bot")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "bidder", response.Body.String())
+
+	// Escopo errado, rota sem escopo e usuário suspenso
+	assert.Equal(t, http.StatusForbidden, withKey(http.MethodPost, "/bid", "reader-bot").Code)
+	assert.Equal(t, http.StatusForbidden, withKey(http.MethodPost, "/admin/void", "bidder-bot").Code)
+	assert.Equal(t, http.StatusForbidden, withKey(http.MethodPost, "/bid", "suspended-bot").Code)
+
+	// Em rotas públicas a chave é opcional, mas se enviada precisa ser válida
+	assert.Equal(t, http.StatusOK, withKey(http.MethodGet, "/public", "reader-bot").Code)
+	assert.Equal(t, http.StatusForbidden, withKey(http.MethodGet, "/public", "bidder-bot").Code)
+	assert.Equal(t, http.StatusUnauthorized,
+		doRequest(router, http.MethodGet, "/public", "", APIKeyHeader, "ak_unknown").Code)
+
+	// Chaves revogadas deixam de valer imediatamente
+	for _, apiKey := range apiKeyRepository.keys {
+		if apiKey.Name == "bidder-bot" {
+			revokedAt := time.Now()
+			apiKey.RevokedAt = &revokedAt
+		}
+	}
+	assert.Equal(t, http.StatusUnauthorized, withKey(http.MethodPost, "/bid", "bidder-bot").Code)
+
+	assert.Eventually(t, func() bool {
+		apiKeyRepository.mutex.Lock()
+		defer apiKeyRepository.mutex.Unlock()
+		return len(apiKeyRepository.touched) >= 2
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/entity/ratelimit_entity"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxRateLimitBodySize limita a leitura do corpo feita para extrair chaves;
+// corpos maiores seguem sem a chave e são validados pelo controller
+const maxRateLimitBodySize = 64 << 10
+
+// RateLimitRule limita as requisições que compartilham a mesma chave. Regras
+// cuja chave é vazia, ou cujo limite está desligado, são ignoradas.
+type RateLimitRule struct {
+	Name  string
+	Limit ratelimit_entity.Limit
+	Key   func(c *gin.Context) string
+	// Cost é a quantidade de fichas consumidas pela requisição; sem ele, cada
+	// requisição consome uma ficha
+	Cost func(c *gin.Context) int
+}
+
+// RateLimiter aplica um conjunto de regras de token bucket a uma rota. As
+// regras são avaliadas em ordem e a primeira que negar encerra a requisição
+// com 429 e o cabeçalho Retry-After. Os baldes são identificados pelo nome da
+// regra e pela chave, então rotas com regras de mesmo nome os compartilham.
+type RateLimiter struct {
+	store ratelimit_entity.RateLimitStoreInterface
+	rules []RateLimitRule
+	now   func() time.Time
+}
+
+func NewRateLimiter(
+	store ratelimit_entity.RateLimitStoreInterface, rules ...RateLimitRule) *RateLimiter {
+	return &RateLimiter{
+		store: store,
+		rules: rules,
+		now:   time.Now,
+	}
+}
+
+func (rl *RateLimiter) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		now := rl.now()
+
+		for _, rule := range rl.rules {
+			if rule.Limit.Disabled() {
+				continue
+			}
+
+			key := rule.Key(c)
+			if key == "" {
+				continue
+			}
+
+			cost := 1
+			if rule.Cost != nil {
+				cost = rule.Cost(c)
+			}
+
+			decision, err := rl.store.Take(
+				c.Request.Context(), ratelimit_entity.Key(rule.Name, key), rule.Limit, cost, now)
+			if err != nil {
+				// Uma falha no armazenamento dos baldes não pode derrubar os lances
+				logger.ErrorContext(c.Request.Context(), "Error trying to apply rate limit", err)
+				continue
+			}
+
+			if !decision.Allowed {
+				rl.reject(c, rule, decision.RetryAfter)
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+func (rl *RateLimiter) reject(c *gin.Context, rule RateLimitRule, retryAfter time.Duration) {
+	seconds := int(math.Max(1, math.Ceil(retryAfter.Seconds())))
+
+	logger.WarnContext(c.Request.Context(), "Rate limit exceeded",
+		zap.String("rule", rule.Name),
+		zap.String("method", c.Request.Method),
+		zap.String("route", c.FullPath()),
+		zap.String("user_id", AuthenticatedUserId(c)),
+		zap.String("client_ip", c.ClientIP()),
+		zap.Int("retry_after", seconds))
+
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	restErr := rest_err.NewTooManyRequestsError("Too many requests, try again later")
+	c.AbortWithStatusJSON(restErr.Code, restErr)
+}
+
+// UserRateLimitKey agrupa as requisições pelo usuário autenticado
+func UserRateLimitKey(c *gin.Context) string {
+	return AuthenticatedUserId(c)
+}
+
+// ClientIPRateLimitKey agrupa as requisições pelo IP de origem
+func ClientIPRateLimitKey(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// JSONFieldRateLimitKey agrupa as requisições pelo valor de um campo de texto
+// do corpo JSON. O corpo é restaurado para que o controller possa lê-lo.
+func JSONFieldRateLimitKey(field string) func(c *gin.Context) string {
+	return func(c *gin.Context) string {
+		body, ok := peekBody(c)
+		if !ok {
+			return ""
+		}
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return ""
+		}
+
+		var value string
+		if err := json.Unmarshal(fields[field], &value); err != nil {
+			return ""
+		}
+		return value
+	}
+}
+
+// JSONArrayRateLimitCost cobra uma ficha por item de um corpo JSON em lista,
+// para que um envio em lote custe o mesmo que os envios individuais. Corpos
+// grandes demais para a leitura custam maxCost; os que não são listas custam
+// uma ficha e são recusados pelo controller.
+func JSONArrayRateLimitCost(maxCost int) func(c *gin.Context) int {
+	return func(c *gin.Context) int {
+		body, ok := peekBody(c)
+		if !ok {
+			return maxCost
+		}
+
+		var items []json.RawMessage
+		if err := json.Unmarshal(body, &items); err != nil || len(items) == 0 {
+			return 1
+		}
+		return len(items)
+	}
+}
+
+// peekBody lê o corpo sem consumi-lo; corpos acima de maxRateLimitBodySize
+// não são retornados
+func peekBody(c *gin.Context) ([]byte, bool) {
+	if c.Request.Body == nil {
+		return nil, false
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxRateLimitBodySize+1))
+	c.Request.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), c.Request.Body), c.Request.Body}
+	if err != nil || len(body) > maxRateLimitBodySize {
+		return nil, false
+	}
+	return body, true
+}
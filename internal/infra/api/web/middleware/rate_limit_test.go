@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"fullcycle-auction_go/internal/entity/ratelimit_entity"
+	"fullcycle-auction_go/internal/infra/ratelimit"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRateLimitedRouter(now *time.Time) *gin.Engine {
+	limiter := NewRateLimiter(ratelimit.NewMemoryStore(),
+		RateLimitRule{Name: "user", Limit: ratelimit_entity.Limit{Rate: 1, Burst: 2}, Key: UserRateLimitKey},
+		RateLimitRule{Name: "auction", Limit: ratelimit_entity.Limit{Rate: 1, Burst: 3}, Key: JSONFieldRateLimitKey("auction_id")})
+	limiter.now = func() time.Time { return *now }
+
+	router := gin.New()
+	router.POST("/bid", func(c *gin.Context) {
+		c.Set(authenticatedUserIdKey, c.GetHeader("X-User"))
+	}, limiter.Handler(), func(c *gin.Context) {
+		// O controller ainda precisa receber o corpo completo
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusCreated, string(body))
+	})
+	return router
+}
+
+func postBid(router *gin.Engine, userId, auctionId string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/bid",
+		strings.NewReader(`{"auction_id":"`+auctionId+`","amount":10}`))
+	request.Header.Set("X-User", userId)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestRateLimiterThrottlesPerUser(t *testing.T) {
+	now := time.Now()
+	router := newRateLimitedRouter(&now)
+
+	first := postBid(router, "user-1", "auction-1")
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, `{"auction_id":"auction-1","amount":10}`, first.Body.String())
+	assert.Equal(t, http.StatusCreated, postBid(router, "user-1", "auction-2").Code)
+
+	throttled := postBid(router, "user-1", "auction-3")
+	assert.Equal(t, http.StatusTooManyRequests, throttled.Code)
+	assert.Equal(t, "1", throttled.Header().Get("Retry-After"))
+
+	// Outros usuários não são afetados, e as fichas voltam com o tempo
+	assert.Equal(t, http.StatusCreated, postBid(router, "user-2", "auction-3").Code)
+	now = now.Add(time.Second)
+	assert.Equal(t, http.StatusCreated, postBid(router, "user-1", "auction-3").Code)
+}
+
+func TestRateLimiterThrottlesPerAuction(t *testing.T) {
+	now := time.Now()
+	router := newRateLimitedRouter(&now)
+
+	for _, userId := range []string{"user-1", "user-2", "user-3"} {
+		assert.Equal(t, http.StatusCreated, postBid(router, userId, "auction-1").Code)
+	}
+
+	assert.Equal(t, http.StatusTooManyRequests, postBid(router, "user-4", "auction-1").Code)
+	assert.Equal(t, http.StatusCreated, postBid(router, "user-4", "auction-2").Code)
+}
+
+func TestRateLimiterChargesBatchPerItem(t *testing.T) {
+	now := time.Now()
+	limiter := NewRateLimiter(ratelimit.NewMemoryStore(),
+		RateLimitRule{Name: "user", Limit: ratelimit_entity.Limit{Rate: 1, Burst: 5}, Key: UserRateLimitKey,
+			Cost: JSONArrayRateLimitCost(100)})
+	limiter.now = func() time.Time { return now }
+
+	router := gin.New()
+	router.POST("/bids/batch", func(c *gin.Context) {
+		c.Set(authenticatedUserIdKey, "user-1")
+	}, limiter.Handler(), func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	})
+
+	postBatch := func(items int) *httptest.ResponseRecorder {
+		body := "[" + strings.TrimSuffix(strings.Repeat(`{"auction_id":"a","amount":10},`, items), ",") + "]"
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/bids/batch", strings.NewReader(body)))
+		return recorder
+	}
+
+	first := postBatch(3)
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, 3, strings.Count(first.Body.String(), "auction_id"))
+
+	// Restam duas fichas: um lote de três é recusado inteiro
+	assert.Equal(t, http.StatusTooManyRequests, postBatch(3).Code)
+	assert.Equal(t, http.StatusOK, postBatch(2).Code)
+
+	// Um lote maior que o burst nunca passa
+	now = now.Add(time.Hour)
+	assert.Equal(t, http.StatusTooManyRequests, postBatch(6).Code)
+}
+
+func TestRateLimiterSharesBucketsAcrossRoutes(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	rule := RateLimitRule{Name: "user", Limit: ratelimit_entity.Limit{Rate: 1, Burst: 3}, Key: UserRateLimitKey}
+	single := NewRateLimiter(store, rule)
+	rule.Cost = JSONArrayRateLimitCost(100)
+	batch := NewRateLimiter(store, rule)
+
+	router := gin.New()
+	authenticate := func(c *gin.Context) { c.Set(authenticatedUserIdKey, "user-1") }
+	created := func(c *gin.Context) { c.Status(http.StatusCreated) }
+	router.POST("/bid", authenticate, single.Handler(), created)
+	router.POST("/bids/batch", authenticate, batch.Handler(), created)
+
+	post := func(path, body string) int {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return recorder.Code
+	}
+
+	// O lote consome as fichas que o lance avulso usaria
+	assert.Equal(t, http.StatusCreated, post("/bids/batch", `[{"amount":1},{"amount":2}]`))
+	assert.Equal(t, http.StatusCreated, post("/bid", `{"amount":3}`))
+	assert.Equal(t, http.StatusTooManyRequests, post("/bid", `{"amount":4}`))
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"fullcycle-auction_go/configuration/rest_err"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadinessState informa se a recuperação da inicialização terminou
+type ReadinessState interface {
+	Ready() bool
+}
+
+// RequireReady recusa com 503 as requisições que não podem ser atendidas
+// enquanto a recuperação está em andamento, como novos lances
+func RequireReady(state ReadinessState) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !state.Ready() {
+			c.Header("Retry-After", "5")
+			restErr := rest_err.NewServiceUnavailableError("Service is recovering, try again later")
+			c.AbortWithStatusJSON(restErr.Code, restErr)
+			return
+		}
+
+		c.Next()
+	}
+}
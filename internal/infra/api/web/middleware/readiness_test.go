@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"fullcycle-auction_go/internal/infra/readiness"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireReadyRejectsRequestsDuringRecovery(t *testing.T) {
+	state := readiness.NewState()
+
+	router := gin.New()
+	router.POST("/bid", RequireReady(state), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	recovering := httptest.NewRecorder()
+	router.ServeHTTP(recovering, httptest.NewRequest(http.MethodPost, "/bid", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recovering.Code)
+	assert.Equal(t, "5", recovering.Header().Get("Retry-After"))
+
+	state.MarkReady()
+
+	ready := httptest.NewRecorder()
+	router.ServeHTTP(ready, httptest.NewRequest(http.MethodPost, "/bid", nil))
+	assert.Equal(t, http.StatusCreated, ready.Code)
+}
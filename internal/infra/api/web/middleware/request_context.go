@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"fullcycle-auction_go/configuration/logger"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const RequestIdHeader = "X-Request-Id"
+
+// validRequestId limita o id recebido do cliente, que vai para os logs
+var validRequestId = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestContext guarda no contexto da requisição os campos usados nos logs:
+// o id da requisição (o recebido em X-Request-Id ou um novo) e o leilão da
+// rota, quando houver. O id é devolvido no cabeçalho da resposta.
+func RequestContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := c.GetHeader(RequestIdHeader)
+		if !validRequestId.MatchString(requestId) {
+			requestId = uuid.NewString()
+		}
+		c.Header(RequestIdHeader, requestId)
+
+		ctx := logger.WithRequestId(c.Request.Context(), requestId)
+		if auctionId := c.Param("auctionId"); auctionId != "" {
+			ctx = logger.WithAuctionId(ctx, auctionId)
+		}
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("request_id", requestId))
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// AccessLog registra cada requisição atendida, com os campos do contexto
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		started := time.Now()
+		c.Next()
+
+		logger.InfoContext(c.Request.Context(), "HTTP request",
+			zap.String("method", c.Request.Method),
+			zap.String("route", c.FullPath()),
+			zap.String("path", c.Request.URL.Path),
+			zap.Int("status", c.Writer.Status()),
+			zap.Duration("latency", time.Since(started)),
+			zap.String("client_ip", c.ClientIP()))
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"fullcycle-auction_go/configuration/logger"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestContextKeepsValidRequestId(t *testing.T) {
+	var seen string
+	router := gin.New()
+	router.Use(RequestContext())
+	router.GET("/auction/:auctionId", func(c *gin.Context) {
+		seen = logger.RequestId(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/auction/a1", nil)
+	request.Header.Set(RequestIdHeader, "client-req.42")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	assert.Equal(t, "client-req.42", seen)
+	assert.Equal(t, "client-req.42", recorder.Header().Get(RequestIdHeader))
+}
+
+func TestRequestContextReplacesInvalidRequestId(t *testing.T) {
+	router := gin.New()
+	router.Use(RequestContext())
+	router.GET("/auction", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	for _, received := range []string{"", "bad id with spaces", strings.Repeat("a", 200)} {
+		request := httptest.NewRequest(http.MethodGet, "/auction", nil)
+		request.Header.Set(RequestIdHeader, received)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		generated := recorder.Header().Get(RequestIdHeader)
+		assert.NotEqual(t, received, generated)
+		assert.Len(t, generated, 36)
+	}
+}
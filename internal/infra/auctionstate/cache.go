@@ -0,0 +1,118 @@
+package auctionstate
+
+import (
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"sync"
+	"time"
+)
+
+// State é o necessário para decidir se um leilão aceita lances
+type State struct {
+	Status  auction_entity.AuctionStatus
+	EndTime time.Time
+}
+
+// AcceptsBids informa se um lance feito em at entra no leilão: basta que o
+// leilão não tenha sido cancelado e que at não tenha passado do término. Um
+// leilão já fechado ainda recebe os lances feitos antes do término que
+// estavam na fila ou no write-ahead log.
+func (s State) AcceptsBids(at time.Time) bool {
+	return s.Status != auction_entity.Cancelled && !at.After(s.EndTime)
+}
+
+type entry struct {
+	state    State
+	hasState bool
+	storedAt time.Time
+	// version é a versão global do último evento aplicado ao leilão
+	version uint64
+}
+
+// Cache guarda o estado dos leilões consultados pelo repositório de lances.
+// Os eventos do repositório de leilões atualizam as entradas na hora; o TTL
+// limita por quanto tempo um estado lido do banco é usado sem notificação,
+// o que cobre mudanças feitas em outras réplicas sem change stream.
+type Cache struct {
+	mutex   sync.Mutex
+	ttl     time.Duration
+	entries map[string]*entry
+	version uint64
+}
+
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:     ttl,
+		entries: make(map[string]*entry),
+	}
+}
+
+// Get retorna o estado em cache e a versão a informar em Store caso ele
+// precise ser lido do banco
+func (c *Cache) Get(auctionId string, now time.Time) (State, uint64, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	current, ok := c.entries[auctionId]
+	if !ok || !current.hasState || (c.ttl > 0 && now.Sub(current.storedAt) >= c.ttl) {
+		return State{}, c.version, false
+	}
+	return current.state, c.version, true
+}
+
+// Store guarda um estado lido do banco. Se algum evento do leilão chegou
+// depois de Get, a leitura pode estar desatualizada e é descartada.
+func (c *Cache) Store(auctionId string, state State, version uint64, now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	current, ok := c.entries[auctionId]
+	if ok && current.version > version {
+		return
+	}
+	c.entries[auctionId] = &entry{state: state, hasState: true, storedAt: now, version: version}
+}
+
+// Apply atualiza o cache com um evento do repositório de leilões
+func (c *Cache) Apply(event Event) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.version++
+	current, ok := c.entries[event.AuctionId]
+	if !ok {
+		current = &entry{}
+		c.entries[event.AuctionId] = current
+	}
+	current.version = c.version
+	current.storedAt = time.Now()
+
+	switch event.Type {
+	case Created, Changed:
+		current.state = State{Status: event.Status, EndTime: event.EndTime}
+		current.hasState = true
+	case Closed:
+		current.state.Status = auction_entity.Completed
+		current.state.EndTime = event.EndTime
+		current.hasState = true
+	case Extended:
+		// Sem o status em cache, a próxima consulta lê o leilão do banco
+		current.state.EndTime = event.EndTime
+	case Cancelled, Removed:
+		current.state = State{}
+		current.hasState = false
+	}
+}
+
+// Len retorna quantos leilões têm estado em cache
+func (c *Cache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	count := 0
+	for _, current := range c.entries {
+		if current.hasState {
+			count++
+		}
+	}
+	return count
+}
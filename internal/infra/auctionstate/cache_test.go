@@ -0,0 +1,69 @@
+package auctionstate
+
+import (
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheAppliesPublishedEvents(t *testing.T) {
+	now := time.Now()
+	cache := NewCache(time.Minute)
+	bus := NewBus()
+	bus.Subscribe(cache.Apply)
+
+	bus.Publish(Event{AuctionId: "a", Type: Created, Status: auction_entity.Active, EndTime: now.Add(time.Minute)})
+	state, _, ok := cache.Get("a", now)
+	assert.True(t, ok)
+	assert.True(t, state.AcceptsBids(now))
+
+	// Uma prorrogação mantém o leilão aberto depois do término original
+	bus.Publish(Event{AuctionId: "a", Type: Extended, EndTime: now.Add(time.Hour)})
+	state, _, _ = cache.Get("a", now)
+	assert.True(t, state.AcceptsBids(now.Add(30*time.Minute)))
+
+	bus.Publish(Event{AuctionId: "a", Type: Closed, EndTime: now})
+	state, _, ok = cache.Get("a", now)
+	assert.True(t, ok)
+	assert.False(t, state.AcceptsBids(now.Add(time.Millisecond)))
+
+	// Lances feitos antes do término e gravados depois do fechamento continuam valendo
+	assert.True(t, state.AcceptsBids(now.Add(-time.Second)))
+
+	// O cancelamento descarta o estado; a próxima consulta vai ao banco
+	bus.Publish(Event{AuctionId: "a", Type: Cancelled})
+	_, _, ok = cache.Get("a", now)
+	assert.False(t, ok)
+}
+
+func TestCacheDiscardsReadsOlderThanEvents(t *testing.T) {
+	now := time.Now()
+	cache := NewCache(time.Minute)
+
+	_, version, ok := cache.Get("a", now)
+	assert.False(t, ok)
+
+	// O leilão é fechado enquanto o estado ainda ativo era lido do banco
+	cache.Apply(Event{AuctionId: "a", Type: Closed})
+	cache.Store("a", State{Status: auction_entity.Active, EndTime: now.Add(time.Hour)}, version, now)
+
+	state, _, ok := cache.Get("a", now)
+	assert.True(t, ok)
+	assert.Equal(t, auction_entity.Completed, state.Status)
+}
+
+func TestCacheExpiresStoredStates(t *testing.T) {
+	now := time.Now()
+	cache := NewCache(time.Minute)
+
+	_, version, _ := cache.Get("a", now)
+	cache.Store("a", State{Status: auction_entity.Active, EndTime: now.Add(time.Hour)}, version, now)
+
+	_, _, ok := cache.Get("a", now.Add(59*time.Second))
+	assert.True(t, ok)
+	_, _, ok = cache.Get("a", now.Add(time.Minute))
+	assert.False(t, ok)
+	assert.Equal(t, 1, cache.Len())
+}
@@ -0,0 +1,68 @@
+package auctionstate
+
+import (
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"sync"
+	"time"
+)
+
+type EventType string
+
+const (
+	Created EventType = "created"
+	// Closed traz o término gravado, que limita os lances ainda aceitos
+	Closed EventType = "closed"
+	// Extended muda o horário de término de um leilão ativo
+	Extended  EventType = "extended"
+	Cancelled EventType = "cancelled"
+	// Changed traz o estado completo lido do banco, como nas notificações do change stream
+	Changed EventType = "changed"
+	// Removed indica que o leilão não existe mais
+	Removed EventType = "removed"
+)
+
+// Event descreve uma mudança no estado de um leilão. Status e EndTime só são
+// usados pelos tipos que os conhecem.
+type Event struct {
+	AuctionId string
+	Type      EventType
+	Status    auction_entity.AuctionStatus
+	EndTime   time.Time
+}
+
+// Bus entrega os eventos publicados a todos os assinantes, na mesma goroutine
+// de quem publica, para que o cache esteja atualizado quando Publish retornar
+type Bus struct {
+	mutex       sync.RWMutex
+	subscribers map[int]func(Event)
+	nextId      int
+}
+
+func NewBus() *Bus {
+	return &Bus{subscribers: make(map[int]func(Event))}
+}
+
+// Subscribe registra handler e retorna a função que cancela a assinatura
+func (b *Bus) Subscribe(handler func(Event)) func() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	id := b.nextId
+	b.nextId++
+	b.subscribers[id] = handler
+
+	return func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		delete(b.subscribers, id)
+	}
+}
+
+func (b *Bus) Publish(event Event) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for _, handler := range b.subscribers {
+		handler(event)
+	}
+}
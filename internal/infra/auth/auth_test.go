@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fullcycle-auction_go/internal/entity/auth_entity"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTokenService(t *testing.T) (*TokenService, *KeySet) {
+	key, err := GenerateSigningKey()
+	require.NoError(t, err)
+
+	keySet := NewKeySet(key)
+	return NewTokenService(keySet, "test-issuer", 15*time.Minute, time.Hour), keySet
+}
+
+func TestIssueAndParseTokens(t *testing.T) {
+	tokenService, _ := newTestTokenService(t)
+
+	tokens, err := tokenService.IssueTokens("user-1")
+	require.Nil(t, err)
+
+	claims, err := tokenService.ParseToken(tokens.AccessToken, auth_entity.AccessToken)
+	require.Nil(t, err)
+	assert.Equal(t, "user-1", claims.UserId)
+	assert.Equal(t, auth_entity.AccessToken, claims.TokenType)
+
+	// Um refresh token não pode ser usado como access token, e vice-versa
+	_, err = tokenService.ParseToken(tokens.RefreshToken, auth_entity.AccessToken)
+	assert.NotNil(t, err)
+	_, err = tokenService.ParseToken(tokens.AccessToken, auth_entity.RefreshToken)
+	assert.NotNil(t, err)
+}
+
+func TestParseTokenRejectsExpiredAndForeignTokens(t *testing.T) {
+	tokenService, _ := newTestTokenService(t)
+
+	tokens, err := tokenService.IssueTokens("user-1")
+	require.Nil(t, err)
+
+	tokenService.now = func() time.Time { return time.Now().Add(16 * time.Minute) }
+	_, err = tokenService.ParseToken(tokens.AccessToken, auth_entity.AccessToken)
+	require.NotNil(t, err)
+	assert.Equal(t, "unauthorized", err.Err)
+	assert.Equal(t, "Token has expired", err.Message)
+
+	// Token assinado por outro conjunto de chaves
+	otherService, _ := newTestTokenService(t)
+	otherTokens, err := otherService.IssueTokens("user-1")
+	require.Nil(t, err)
+
+	tokenService.now = time.Now
+	_, err = tokenService.ParseToken(otherTokens.AccessToken, auth_entity.AccessToken)
+	assert.NotNil(t, err)
+
+	_, err = tokenService.ParseToken("not-a-token", auth_entity.AccessToken)
+	assert.NotNil(t, err)
+}
+
+func TestKeyRotationKeepsPreviousTokensValid(t *testing.T) {
+	tokenService, keySet := newTestTokenService(t)
+	oldKeyId := keySet.Active().Id
+
+	oldTokens, err := tokenService.IssueTokens("user-1")
+	require.Nil(t, err)
+
+	newKey, keyErr := GenerateSigningKey()
+	require.NoError(t, keyErr)
+	keySet.Rotate(newKey)
+
+	newTokens, err := tokenService.IssueTokens("user-1")
+	require.Nil(t, err)
+
+	_, err = tokenService.ParseToken(oldTokens.AccessToken, auth_entity.AccessToken)
+	assert.Nil(t, err)
+	_, err = tokenService.ParseToken(newTokens.AccessToken, auth_entity.AccessToken)
+	assert.Nil(t, err)
+
+	jwks := keySet.JWKS()
+	assert.Len(t, jwks.Keys, 2)
+
+	assert.Error(t, keySet.Retire(newKey.Id))
+	require.NoError(t, keySet.Retire(oldKeyId))
+
+	_, err = tokenService.ParseToken(oldTokens.AccessToken, auth_entity.AccessToken)
+	assert.NotNil(t, err)
+	assert.Len(t, keySet.JWKS().Keys, 1)
+}
+
+func TestLoadKeySetFromDirectory(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, keyId := range []string{"2024-01", "2024-02"} {
+		key, err := GenerateSigningKey()
+		require.NoError(t, err)
+
+		der, err := x509.MarshalECPrivateKey(key.PrivateKey)
+		require.NoError(t, err)
+
+		data := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+		require.NoError(t, os.WriteFile(filepath.Join(dir, keyId+".pem"), data, 0600))
+	}
+
+	keySet, err := LoadKeySet(dir, "")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-02", keySet.Active().Id)
+	assert.Len(t, keySet.JWKS().Keys, 2)
+
+	keySet, err = LoadKeySet(dir, "2024-01")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01", keySet.Active().Id)
+
+	_, err = LoadKeySet(dir, "missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,237 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// SigningKey é uma chave ECDSA P-256 usada para assinar tokens (ES256)
+type SigningKey struct {
+	Id         string
+	PrivateKey *ecdsa.PrivateKey
+}
+
+// JWK é a representação pública de uma chave no formato JSON Web Key
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// KeySet mantém a chave ativa, usada para assinar novos tokens, e as chaves
+// anteriores, aceitas apenas na validação. Assim uma rotação não invalida os
+// tokens já emitidos: a chave antiga só deve ser retirada depois que o maior
+// tempo de vida de um token tiver passado.
+type KeySet struct {
+	mutex    sync.RWMutex
+	activeId string
+	keys     map[string]SigningKey
+}
+
+func NewKeySet(active SigningKey, previous ...SigningKey) *KeySet {
+	keySet := &KeySet{
+		keys: make(map[string]SigningKey),
+	}
+
+	for _, key := range previous {
+		keySet.keys[key.Id] = key
+	}
+	keySet.keys[active.Id] = active
+	keySet.activeId = active.Id
+
+	return keySet
+}
+
+// LoadOrGenerateKeySet carrega as chaves de keysDir. Sem diretório
+// configurado é gerada uma chave temporária, e os tokens deixam de valer
+// quando a aplicação reinicia.
+func LoadOrGenerateKeySet(keysDir, activeKeyId string) (*KeySet, error) {
+	if keysDir == "" {
+		logger.Info("Auth keys directory not set, generating an ephemeral signing key")
+		key, err := GenerateSigningKey()
+		if err != nil {
+			return nil, err
+		}
+		return NewKeySet(key), nil
+	}
+
+	return LoadKeySet(keysDir, activeKeyId)
+}
+
+// LoadKeySet lê todos os arquivos .pem do diretório; o nome do arquivo (sem
+// extensão) é o id da chave. A chave ativa é activeKeyId ou, quando vazio, a
+// última em ordem alfabética.
+func LoadKeySet(dir, activeKeyId string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no signing keys found in %s", dir)
+	}
+	sort.Strings(paths)
+
+	var keys []SigningKey
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		privateKey, err := ParsePrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signing key %s: %w", path, err)
+		}
+
+		keys = append(keys, SigningKey{
+			Id:         strings.TrimSuffix(filepath.Base(path), ".pem"),
+			PrivateKey: privateKey,
+		})
+	}
+
+	if activeKeyId == "" {
+		activeKeyId = keys[len(keys)-1].Id
+	}
+
+	for i, key := range keys {
+		if key.Id == activeKeyId {
+			previous := append(append([]SigningKey{}, keys[:i]...), keys[i+1:]...)
+			return NewKeySet(key, previous...), nil
+		}
+	}
+
+	return nil, fmt.Errorf("active signing key %s not found in %s", activeKeyId, dir)
+}
+
+func GenerateSigningKey() (SigningKey, error) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	return SigningKey{
+		Id:         uuid.New().String(),
+		PrivateKey: privateKey,
+	}, nil
+}
+
+// ParsePrivateKey aceita chaves P-256 em PEM nos formatos SEC 1 ("EC PRIVATE KEY") e PKCS #8
+func ParsePrivateKey(data []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	if privateKey, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return checkCurve(privateKey)
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("key is not an ECDSA key")
+	}
+
+	return checkCurve(privateKey)
+}
+
+func checkCurve(privateKey *ecdsa.PrivateKey) (*ecdsa.PrivateKey, error) {
+	if privateKey.Curve != elliptic.P256() {
+		return nil, errors.New("key must use the P-256 curve")
+	}
+	return privateKey, nil
+}
+
+// Active retorna a chave usada para assinar novos tokens
+func (ks *KeySet) Active() SigningKey {
+	ks.mutex.RLock()
+	defer ks.mutex.RUnlock()
+
+	return ks.keys[ks.activeId]
+}
+
+// PublicKey retorna a chave de validação pelo id informado no cabeçalho "kid"
+func (ks *KeySet) PublicKey(keyId string) (*ecdsa.PublicKey, bool) {
+	ks.mutex.RLock()
+	defer ks.mutex.RUnlock()
+
+	key, ok := ks.keys[keyId]
+	if !ok {
+		return nil, false
+	}
+	return &key.PrivateKey.PublicKey, true
+}
+
+// Rotate passa a assinar com a nova chave, mantendo as anteriores para validação
+func (ks *KeySet) Rotate(key SigningKey) {
+	ks.mutex.Lock()
+	defer ks.mutex.Unlock()
+
+	ks.keys[key.Id] = key
+	ks.activeId = key.Id
+}
+
+// Retire remove uma chave anterior; a chave ativa não pode ser retirada
+func (ks *KeySet) Retire(keyId string) error {
+	ks.mutex.Lock()
+	defer ks.mutex.Unlock()
+
+	if keyId == ks.activeId {
+		return errors.New("the active signing key cannot be retired")
+	}
+
+	delete(ks.keys, keyId)
+	return nil
+}
+
+// JWKS retorna as chaves públicas em ordem de id, para publicação em /.well-known/jwks.json
+func (ks *KeySet) JWKS() JWKSet {
+	ks.mutex.RLock()
+	defer ks.mutex.RUnlock()
+
+	keySet := JWKSet{Keys: []JWK{}}
+	for _, key := range ks.keys {
+		publicKey := key.PrivateKey.PublicKey
+		keySet.Keys = append(keySet.Keys, JWK{
+			Kty: "EC",
+			Crv: "P-256",
+			Kid: key.Id,
+			Use: "sig",
+			Alg: "ES256",
+			X:   base64.RawURLEncoding.EncodeToString(publicKey.X.FillBytes(make([]byte, 32))),
+			Y:   base64.RawURLEncoding.EncodeToString(publicKey.Y.FillBytes(make([]byte, 32))),
+		})
+	}
+
+	sort.Slice(keySet.Keys, func(i, j int) bool {
+		return keySet.Keys[i].Kid < keySet.Keys[j].Kid
+	})
+
+	return keySet
+}
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"errors"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auth_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	TokenType auth_entity.TokenType `json:"token_type"`
+}
+
+// TokenService emite e valida tokens JWT assinados com a chave ativa do KeySet
+type TokenService struct {
+	keySet          *KeySet
+	issuer          string
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+	now             func() time.Time
+}
+
+func NewTokenService(
+	keySet *KeySet,
+	issuer string,
+	accessTokenTTL, refreshTokenTTL time.Duration) *TokenService {
+	return &TokenService{
+		keySet:          keySet,
+		issuer:          issuer,
+		accessTokenTTL:  accessTokenTTL,
+		refreshTokenTTL: refreshTokenTTL,
+		now:             time.Now,
+	}
+}
+
+func (ts *TokenService) IssueTokens(userId string) (*auth_entity.TokenPair, *internal_error.InternalError) {
+	now := ts.now()
+
+	accessToken, accessExpiresAt, err := ts.sign(userId, auth_entity.AccessToken, now, ts.accessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, refreshExpiresAt, err := ts.sign(userId, auth_entity.RefreshToken, now, ts.refreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &auth_entity.TokenPair{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshExpiresAt: refreshExpiresAt,
+	}, nil
+}
+
+func (ts *TokenService) ParseToken(
+	token string, tokenType auth_entity.TokenType) (*auth_entity.Claims, *internal_error.InternalError) {
+	claims := &tokenClaims{}
+
+	_, err := jwt.ParseWithClaims(token, claims, ts.verificationKey,
+		jwt.WithValidMethods([]string{jwt.SigningMethodES256.Alg()}),
+		jwt.WithIssuer(ts.issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(ts.now))
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, internal_error.NewUnauthorizedError("Token has expired")
+		}
+		return nil, internal_error.NewUnauthorizedError("Invalid token")
+	}
+
+	if claims.TokenType != tokenType {
+		return nil, internal_error.NewUnauthorizedError("Invalid token type")
+	}
+
+	return &auth_entity.Claims{
+		UserId:    claims.Subject,
+		TokenType: claims.TokenType,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
+}
+
+func (ts *TokenService) sign(
+	userId string,
+	tokenType auth_entity.TokenType,
+	now time.Time,
+	ttl time.Duration) (string, time.Time, *internal_error.InternalError) {
+	expiresAt := now.Add(ttl)
+	key := ts.keySet.Active()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, tokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Issuer:    ts.issuer,
+			Subject:   userId,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		TokenType: tokenType,
+	})
+	token.Header["kid"] = key.Id
+
+	signed, err := token.SignedString(key.PrivateKey)
+	if err != nil {
+		logger.Error("Error trying to sign token", err)
+		return "", time.Time{}, internal_error.NewInternalServerError("Error trying to sign token")
+	}
+
+	return signed, expiresAt, nil
+}
+
+func (ts *TokenService) verificationKey(token *jwt.Token) (interface{}, error) {
+	keyId, _ := token.Header["kid"].(string)
+
+	publicKey, ok := ts.keySet.PublicKey(keyId)
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+	return publicKey, nil
+}
@@ -0,0 +1,56 @@
+package blobstore
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/internal_error"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// BlobStore guarda arquivos binários (imagens dos leilões) identificados por
+// chave. As chaves usam '/' como separador e nunca contêm "..".
+type BlobStore interface {
+	Put(
+		ctx context.Context,
+		key string,
+		body io.Reader,
+		size int64,
+		contentType string) *internal_error.InternalError
+
+	// Get retorna o conteúdo do objeto; o chamador deve fechar o leitor
+	Get(
+		ctx context.Context, key string) (io.ReadCloser, *internal_error.InternalError)
+
+	Delete(
+		ctx context.Context, key string) *internal_error.InternalError
+}
+
+var keyPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9/_.-]*$`)
+
+// ValidateKey impede chaves que escapem do diretório ou bucket configurado
+func ValidateKey(key string) *internal_error.InternalError {
+	if !keyPattern.MatchString(key) || strings.Contains(key, "..") || strings.Contains(key, "//") {
+		return internal_error.NewBadRequestError("Invalid blob key")
+	}
+	return nil
+}
+
+// NewBlobStore escolhe a implementação pelo tipo: "local", padrão, guarda os
+// arquivos em localDir, e "s3" usa o bucket de s3Config
+func NewBlobStore(storeType, localDir string, s3Config S3Config) (BlobStore, error) {
+	switch storeType {
+	case "s3":
+		store, err := NewS3BlobStore(s3Config)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	default:
+		store, err := NewLocalBlobStore(localDir)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	}
+}
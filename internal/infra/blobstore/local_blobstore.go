@@ -0,0 +1,107 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/internal_error"
+	"io"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+)
+
+// LocalBlobStore guarda os objetos como arquivos abaixo de um diretório raiz
+type LocalBlobStore struct {
+	root string
+}
+
+func NewLocalBlobStore(root string) (*LocalBlobStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("creating blob directory %s: %w", root, err)
+	}
+
+	return &LocalBlobStore{root: root}, nil
+}
+
+// Put grava em um arquivo temporário e o renomeia ao final, para que leitores
+// nunca vejam um objeto pela metade
+func (ls *LocalBlobStore) Put(
+	ctx context.Context,
+	key string,
+	body io.Reader,
+	size int64,
+	contentType string) *internal_error.InternalError {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+
+	path := ls.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		logger.ErrorContext(ctx, "Error trying to create directory for blob", err, zap.String("blob_key", key))
+		return internal_error.NewInternalServerError("Error trying to store file")
+	}
+
+	tempFile, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to create blob", err, zap.String("blob_key", key))
+		return internal_error.NewInternalServerError("Error trying to store file")
+	}
+	defer os.Remove(tempFile.Name())
+
+	if _, err := io.Copy(tempFile, body); err != nil {
+		tempFile.Close()
+		logger.ErrorContext(ctx, "Error trying to write blob", err, zap.String("blob_key", key))
+		return internal_error.NewInternalServerError("Error trying to store file")
+	}
+
+	if err := tempFile.Close(); err != nil {
+		logger.ErrorContext(ctx, "Error trying to write blob", err, zap.String("blob_key", key))
+		return internal_error.NewInternalServerError("Error trying to store file")
+	}
+
+	if err := os.Rename(tempFile.Name(), path); err != nil {
+		logger.ErrorContext(ctx, "Error trying to store blob", err, zap.String("blob_key", key))
+		return internal_error.NewInternalServerError("Error trying to store file")
+	}
+
+	return nil
+}
+
+func (ls *LocalBlobStore) Get(
+	ctx context.Context, key string) (io.ReadCloser, *internal_error.InternalError) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(ls.path(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, internal_error.NewNotFoundError("File not found")
+		}
+		logger.ErrorContext(ctx, "Error trying to read blob", err, zap.String("blob_key", key))
+		return nil, internal_error.NewInternalServerError("Error trying to read file")
+	}
+
+	return file, nil
+}
+
+func (ls *LocalBlobStore) Delete(
+	ctx context.Context, key string) *internal_error.InternalError {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+
+	if err := os.Remove(ls.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.ErrorContext(ctx, "Error trying to delete blob", err, zap.String("blob_key", key))
+		return internal_error.NewInternalServerError("Error trying to delete file")
+	}
+
+	return nil
+}
+
+func (ls *LocalBlobStore) path(key string) string {
+	return filepath.Join(ls.root, filepath.FromSlash(key))
+}
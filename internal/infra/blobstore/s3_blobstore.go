@@ -0,0 +1,228 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/internal_error"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type S3Config struct {
+	// Endpoint inclui o esquema, por exemplo http://minio:9000
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
+// S3BlobStore fala diretamente a API REST do S3 com assinatura SigV4 e URLs no
+// estilo path (endpoint/bucket/chave), o que a torna compatível com MinIO e
+// outros serviços S3.
+type S3BlobStore struct {
+	config S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+func NewS3BlobStore(config S3Config) (*S3BlobStore, error) {
+	if config.Endpoint == "" || config.Bucket == "" {
+		return nil, errors.New("S3_ENDPOINT and S3_BUCKET are required for the s3 blob store")
+	}
+	if config.AccessKey == "" || config.SecretKey == "" {
+		return nil, errors.New("S3_ACCESS_KEY and S3_SECRET_KEY are required for the s3 blob store")
+	}
+
+	return &S3BlobStore{
+		config: config,
+		client: &http.Client{Timeout: 30 * time.Second},
+		now:    time.Now,
+	}, nil
+}
+
+func (ss *S3BlobStore) Put(
+	ctx context.Context,
+	key string,
+	body io.Reader,
+	size int64,
+	contentType string) *internal_error.InternalError {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+
+	// O corpo é lido por inteiro para calcular o hash exigido pela assinatura;
+	// o tamanho já foi limitado por quem chama
+	payload, err := io.ReadAll(body)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to read blob", err, zap.String("blob_key", key))
+		return internal_error.NewInternalServerError("Error trying to store file")
+	}
+
+	request, err := ss.newRequest(ctx, http.MethodPut, key, payload)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to build request for blob", err, zap.String("blob_key", key))
+		return internal_error.NewInternalServerError("Error trying to store file")
+	}
+	request.Header.Set("Content-Type", contentType)
+
+	response, err := ss.client.Do(request)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to upload blob", err, zap.String("blob_key", key))
+		return internal_error.NewInternalServerError("Error trying to store file")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		logger.ErrorContext(ctx, "Error trying to upload blob", s3Error(response), zap.String("blob_key", key))
+		return internal_error.NewInternalServerError("Error trying to store file")
+	}
+
+	return nil
+}
+
+func (ss *S3BlobStore) Get(
+	ctx context.Context, key string) (io.ReadCloser, *internal_error.InternalError) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+
+	request, err := ss.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to build request for blob", err, zap.String("blob_key", key))
+		return nil, internal_error.NewInternalServerError("Error trying to read file")
+	}
+
+	response, err := ss.client.Do(request)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to download blob", err, zap.String("blob_key", key))
+		return nil, internal_error.NewInternalServerError("Error trying to read file")
+	}
+
+	switch response.StatusCode {
+	case http.StatusOK:
+		return response.Body, nil
+	case http.StatusNotFound:
+		response.Body.Close()
+		return nil, internal_error.NewNotFoundError("File not found")
+	default:
+		defer response.Body.Close()
+		logger.ErrorContext(ctx, "Error trying to download blob", s3Error(response), zap.String("blob_key", key))
+		return nil, internal_error.NewInternalServerError("Error trying to read file")
+	}
+}
+
+func (ss *S3BlobStore) Delete(
+	ctx context.Context, key string) *internal_error.InternalError {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+
+	request, err := ss.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to build request for blob", err, zap.String("blob_key", key))
+		return internal_error.NewInternalServerError("Error trying to delete file")
+	}
+
+	response, err := ss.client.Do(request)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to delete blob", err, zap.String("blob_key", key))
+		return internal_error.NewInternalServerError("Error trying to delete file")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusNoContent &&
+		response.StatusCode != http.StatusOK &&
+		response.StatusCode != http.StatusNotFound {
+		logger.ErrorContext(ctx, "Error trying to delete blob", s3Error(response), zap.String("blob_key", key))
+		return internal_error.NewInternalServerError("Error trying to delete file")
+	}
+
+	return nil
+}
+
+func (ss *S3BlobStore) newRequest(
+	ctx context.Context, method, key string, payload []byte) (*http.Request, error) {
+	endpoint, err := url.Parse(strings.TrimSuffix(ss.config.Endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+	endpoint.Path = "/" + ss.config.Bucket + "/" + key
+
+	request, err := http.NewRequestWithContext(ctx, method, endpoint.String(), bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	request.ContentLength = int64(len(payload))
+
+	ss.sign(request, payload)
+	return request, nil
+}
+
+// sign aplica a assinatura AWS Signature Version 4 à requisição
+func (ss *S3BlobStore) sign(request *http.Request, payload []byte) {
+	now := ss.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	payloadHash := sha256Hex(payload)
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		request.URL.RawQuery,
+		"host:" + request.URL.Host + "\n" +
+			"x-amz-content-sha256:" + payloadHash + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + ss.config.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+ss.config.SecretKey), date)
+	signingKey = hmacSHA256(signingKey, ss.config.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		ss.config.AccessKey, scope, signedHeaders, signature))
+}
+
+func s3Error(response *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	return fmt.Errorf("s3 responded with status %d: %s", response.StatusCode, strings.TrimSpace(string(body)))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,235 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/apikey_entity"
+	"fullcycle-auction_go/internal/infra/database/instrument"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// lastUsedResolution limita a frequência de escrita do último uso: uma chave
+// usada várias vezes por segundo só é atualizada uma vez por minuto
+const lastUsedResolution = time.Minute
+
+type APIKeyEntityMongo struct {
+	Id         string                `bson:"_id"`
+	UserId     string                `bson:"user_id"`
+	Name       string                `bson:"name"`
+	Prefix     string                `bson:"prefix"`
+	Hash       string                `bson:"hash"`
+	Scopes     []apikey_entity.Scope `bson:"scopes"`
+	CreatedAt  int64                 `bson:"created_at"` // milissegundos desde a época Unix
+	ExpiresAt  int64                 `bson:"expires_at,omitempty"`
+	LastUsedAt int64                 `bson:"last_used_at,omitempty"`
+	RevokedAt  int64                 `bson:"revoked_at,omitempty"`
+}
+
+type APIKeyRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewAPIKeyRepository(database *mongo.Database) *APIKeyRepository {
+	repo := &APIKeyRepository{
+		Collection: database.Collection("api_keys"),
+	}
+
+	// Cria os índices de hash único e de usuário
+	go repo.ensureIndexes(context.Background())
+
+	return repo
+}
+
+func (ar *APIKeyRepository) ensureIndexes(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	_, err := ar.Collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to create api key indexes", err)
+	}
+}
+
+func (ar *APIKeyRepository) CreateAPIKey(
+	ctx context.Context, apiKey *apikey_entity.APIKey) *internal_error.InternalError {
+	ctx, done := instrument.Operation(ctx, "apikey", "CreateAPIKey")
+	defer done()
+
+	if _, err := ar.Collection.InsertOne(ctx, toAPIKeyEntityMongo(apiKey)); err != nil {
+		logger.ErrorContext(ctx, "Error trying to insert api key", err)
+		return internal_error.NewInternalServerError("Error trying to insert api key")
+	}
+
+	return nil
+}
+
+func (ar *APIKeyRepository) FindAPIKeyByHash(
+	ctx context.Context, hash string) (*apikey_entity.APIKey, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "apikey", "FindAPIKeyByHash")
+	defer done()
+
+	return ar.findOne(ctx, bson.M{"hash": hash})
+}
+
+func (ar *APIKeyRepository) FindAPIKeyById(
+	ctx context.Context, keyId string) (*apikey_entity.APIKey, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "apikey", "FindAPIKeyById")
+	defer done()
+
+	return ar.findOne(ctx, bson.M{"_id": keyId})
+}
+
+func (ar *APIKeyRepository) FindAPIKeysByUserId(
+	ctx context.Context, userId string) ([]apikey_entity.APIKey, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "apikey", "FindAPIKeysByUserId")
+	defer done()
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := ar.Collection.Find(ctx, bson.M{"user_id": userId}, opts)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find api keys", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find api keys")
+	}
+	defer cursor.Close(ctx)
+
+	var apiKeysMongo []APIKeyEntityMongo
+	if err := cursor.All(ctx, &apiKeysMongo); err != nil {
+		logger.ErrorContext(ctx, "Error trying to decode api keys", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find api keys")
+	}
+
+	apiKeys := make([]apikey_entity.APIKey, 0, len(apiKeysMongo))
+	for _, apiKeyMongo := range apiKeysMongo {
+		apiKeys = append(apiKeys, *toAPIKeyEntity(apiKeyMongo))
+	}
+
+	return apiKeys, nil
+}
+
+func (ar *APIKeyRepository) RevokeAPIKey(
+	ctx context.Context, keyId string, revokedAt time.Time) *internal_error.InternalError {
+	ctx, done := instrument.Operation(ctx, "apikey", "RevokeAPIKey")
+	defer done()
+
+	return ar.updateOne(ctx, keyId, bson.M{"$set": bson.M{"revoked_at": revokedAt.UnixMilli()}})
+}
+
+func (ar *APIKeyRepository) SetAPIKeyExpiration(
+	ctx context.Context, keyId string, expiresAt time.Time) *internal_error.InternalError {
+	ctx, done := instrument.Operation(ctx, "apikey", "SetAPIKeyExpiration")
+	defer done()
+
+	return ar.updateOne(ctx, keyId, bson.M{"$set": bson.M{"expires_at": expiresAt.UnixMilli()}})
+}
+
+func (ar *APIKeyRepository) TouchAPIKey(
+	ctx context.Context, keyId string, usedAt time.Time) *internal_error.InternalError {
+	ctx, done := instrument.Operation(ctx, "apikey", "TouchAPIKey")
+	defer done()
+
+	filter := bson.M{
+		"_id": keyId,
+		"$or": bson.A{
+			bson.M{"last_used_at": bson.M{"$exists": false}},
+			bson.M{"last_used_at": bson.M{"$lt": usedAt.Add(-lastUsedResolution).UnixMilli()}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"last_used_at": usedAt.UnixMilli()}}
+
+	if _, err := ar.Collection.UpdateOne(ctx, filter, update); err != nil {
+		logger.ErrorContext(ctx, "Error trying to update last use of api key", err, zap.String("api_key_id", keyId))
+		return internal_error.NewInternalServerError("Error trying to update api key")
+	}
+
+	return nil
+}
+
+func (ar *APIKeyRepository) findOne(
+	ctx context.Context, filter bson.M) (*apikey_entity.APIKey, *internal_error.InternalError) {
+	var apiKeyMongo APIKeyEntityMongo
+	if err := ar.Collection.FindOne(ctx, filter).Decode(&apiKeyMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError("API key not found")
+		}
+		logger.ErrorContext(ctx, "Error trying to find api key", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find api key")
+	}
+
+	return toAPIKeyEntity(apiKeyMongo), nil
+}
+
+func (ar *APIKeyRepository) updateOne(
+	ctx context.Context, keyId string, update bson.M) *internal_error.InternalError {
+	result, err := ar.Collection.UpdateOne(ctx, bson.M{"_id": keyId}, update)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to update api key", err, zap.String("api_key_id", keyId))
+		return internal_error.NewInternalServerError("Error trying to update api key")
+	}
+
+	if result.MatchedCount == 0 {
+		return internal_error.NewNotFoundError("API key not found")
+	}
+
+	return nil
+}
+
+func toAPIKeyEntityMongo(apiKey *apikey_entity.APIKey) *APIKeyEntityMongo {
+	return &APIKeyEntityMongo{
+		Id:         apiKey.Id,
+		UserId:     apiKey.UserId,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Hash:       apiKey.Hash,
+		Scopes:     apiKey.Scopes,
+		CreatedAt:  apiKey.CreatedAt.UnixMilli(),
+		ExpiresAt:  optionalMillis(apiKey.ExpiresAt),
+		LastUsedAt: optionalMillis(apiKey.LastUsedAt),
+		RevokedAt:  optionalMillis(apiKey.RevokedAt),
+	}
+}
+
+func toAPIKeyEntity(apiKeyMongo APIKeyEntityMongo) *apikey_entity.APIKey {
+	return &apikey_entity.APIKey{
+		Id:         apiKeyMongo.Id,
+		UserId:     apiKeyMongo.UserId,
+		Name:       apiKeyMongo.Name,
+		Prefix:     apiKeyMongo.Prefix,
+		Hash:       apiKeyMongo.Hash,
+		Scopes:     apiKeyMongo.Scopes,
+		CreatedAt:  time.UnixMilli(apiKeyMongo.CreatedAt),
+		ExpiresAt:  optionalTime(apiKeyMongo.ExpiresAt),
+		LastUsedAt: optionalTime(apiKeyMongo.LastUsedAt),
+		RevokedAt:  optionalTime(apiKeyMongo.RevokedAt),
+	}
+}
+
+func optionalMillis(value *time.Time) int64 {
+	if value == nil {
+		return 0
+	}
+	return value.UnixMilli()
+}
+
+func optionalTime(value int64) *time.Time {
+	if value == 0 {
+		return nil
+	}
+	t := time.UnixMilli(value)
+	return &t
+}
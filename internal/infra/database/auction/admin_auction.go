@@ -0,0 +1,131 @@
+package auction
+
+import (
+	"context"
+	"errors"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/infra/auctionstate"
+	"fullcycle-auction_go/internal/infra/database/instrument"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// ForceCloseAuction encerra um leilão ativo agora, registrando o horário do
+// encerramento como término. O lance vencedor é escolhido como num fechamento
+// automático.
+func (ar *AuctionRepository) ForceCloseAuction(
+	ctx context.Context, auctionID string) (*auction_entity.Auction, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "auction", "ForceCloseAuction")
+	defer done()
+
+	now := time.Now()
+	auctionEntity, err := ar.updateActiveAuction(ctx, auctionID, nil, bson.M{
+		"status":   auction_entity.Completed,
+		"end_time": now.UnixMilli(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ar.events.Publish(auctionstate.Event{AuctionId: auctionID, Type: auctionstate.Closed, EndTime: now})
+	if closeScheduler := ar.getCloseScheduler(); closeScheduler != nil {
+		closeScheduler.Cancel(auctionID)
+	}
+
+	return auctionEntity, nil
+}
+
+// CancelAuction encerra um leilão ativo sem vencedor; o motivo fica gravado no documento
+func (ar *AuctionRepository) CancelAuction(
+	ctx context.Context, auctionID, reason string) (*auction_entity.Auction, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "auction", "CancelAuction")
+	defer done()
+
+	auctionEntity, err := ar.updateActiveAuction(ctx, auctionID, nil, bson.M{
+		"status":        auction_entity.Cancelled,
+		"cancel_reason": reason,
+		"cancelled_at":  time.Now().UnixMilli(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ar.CancelAuctionClose(auctionID)
+	return auctionEntity, nil
+}
+
+// ExtendAuction adia o término de um leilão ativo. O filtro recusa um término
+// anterior ao atual, o que também protege contra prorrogações concorrentes.
+func (ar *AuctionRepository) ExtendAuction(
+	ctx context.Context,
+	auctionID string,
+	endTime time.Time) (*auction_entity.Auction, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "auction", "ExtendAuction")
+	defer done()
+
+	earlier := &condition{
+		filter: bson.M{"$or": bson.A{
+			bson.M{"end_time": bson.M{"$lt": endTime.UnixMilli()}},
+			bson.M{"end_time": bson.M{"$exists": false}},
+		}},
+		message: "New end time must be after the current end time",
+	}
+	auctionEntity, err := ar.updateActiveAuction(ctx, auctionID, earlier, bson.M{"end_time": endTime.UnixMilli()})
+	if err != nil {
+		return nil, err
+	}
+
+	ar.RescheduleAuctionClose(auctionID, endTime)
+	return auctionEntity, nil
+}
+
+// condition é uma restrição extra da atualização, com a mensagem do erro
+// retornado quando o leilão não a atende
+type condition struct {
+	filter  bson.M
+	message string
+}
+
+// updateActiveAuction aplica set a um leilão ativo que também atenda a extra,
+// se informada, e retorna o documento atualizado. Quando nada é alterado, o
+// erro explica se o leilão não existe, não está ativo ou não atende a extra.
+func (ar *AuctionRepository) updateActiveAuction(
+	ctx context.Context,
+	auctionID string,
+	extra *condition,
+	set bson.M) (*auction_entity.Auction, *internal_error.InternalError) {
+	filter := bson.M{"_id": auctionID, "status": auction_entity.Active}
+	if extra != nil {
+		for key, value := range extra.filter {
+			filter[key] = value
+		}
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var auctionMongo AuctionEntityMongo
+	err := ar.Collection.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&auctionMongo)
+	if err == nil {
+		auctionEntity := ar.toAuctionEntity(auctionMongo)
+		return &auctionEntity, nil
+	}
+
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		logger.ErrorContext(ctx, "Error trying to update auction", err, zap.String("auction_id", auctionID))
+		return nil, internal_error.NewInternalServerError("Error trying to update auction")
+	}
+
+	current, findErr := ar.FindAuctionById(ctx, auctionID)
+	if findErr != nil {
+		return nil, findErr
+	}
+	if current.Status != auction_entity.Active || extra == nil {
+		return nil, internal_error.NewBadRequestError("Auction is not active")
+	}
+	return nil, internal_error.NewBadRequestError(extra.message)
+}
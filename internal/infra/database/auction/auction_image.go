@@ -0,0 +1,128 @@
+package auction
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/infra/database/instrument"
+	"fullcycle-auction_go/internal/infra/database/storedtime"
+	"fullcycle-auction_go/internal/internal_error"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type AuctionImageMongo struct {
+	Id           string `bson:"id"`
+	Position     int    `bson:"position"`
+	ContentType  string `bson:"content_type"`
+	Size         int64  `bson:"size"`
+	Width        int    `bson:"width"`
+	Height       int    `bson:"height"`
+	ObjectKey    string `bson:"object_key"`
+	ThumbnailKey string `bson:"thumbnail_key"`
+	UploadedAt   int64  `bson:"uploaded_at"` // milissegundos desde a época Unix
+}
+
+func (ar *AuctionRepository) AddAuctionImages(
+	ctx context.Context,
+	auctionId string,
+	images []auction_entity.AuctionImage,
+	maxImages int) *internal_error.InternalError {
+	ctx, done := instrument.Operation(ctx, "auction", "AddAuctionImages")
+	defer done()
+
+	freeSlots := maxImages - len(images)
+	if freeSlots < 0 {
+		return internal_error.NewBadRequestError(
+			fmt.Sprintf("An auction can have at most %d images", maxImages))
+	}
+
+	// O filtro só casa se a posição freeSlots da lista ainda não existe, ou seja,
+	// se cabem todas as novas imagens; a verificação e a escrita são atômicas
+	filter := bson.M{
+		"_id":                               auctionId,
+		fmt.Sprintf("images.%d", freeSlots): bson.M{"$exists": false},
+	}
+	update := bson.M{"$push": bson.M{"images": bson.M{"$each": toAuctionImagesMongo(images)}}}
+
+	result, err := ar.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to add auction images", err)
+		return internal_error.NewInternalServerError("Error trying to add auction images")
+	}
+
+	if result.MatchedCount == 0 {
+		if _, err := ar.FindAuctionById(ctx, auctionId); err != nil {
+			return err
+		}
+		return internal_error.NewBadRequestError(
+			fmt.Sprintf("An auction can have at most %d images", maxImages))
+	}
+
+	return nil
+}
+
+func (ar *AuctionRepository) UpdateAuctionImages(
+	ctx context.Context,
+	auctionId string,
+	images []auction_entity.AuctionImage) *internal_error.InternalError {
+	ctx, done := instrument.Operation(ctx, "auction", "UpdateAuctionImages")
+	defer done()
+
+	update := bson.M{"$set": bson.M{"images": toAuctionImagesMongo(images)}}
+
+	result, err := ar.Collection.UpdateOne(ctx, bson.M{"_id": auctionId}, update)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to update auction images", err)
+		return internal_error.NewInternalServerError("Error trying to update auction images")
+	}
+
+	if result.MatchedCount == 0 {
+		return internal_error.NewNotFoundError("Auction not found")
+	}
+
+	return nil
+}
+
+func toAuctionImagesMongo(images []auction_entity.AuctionImage) []AuctionImageMongo {
+	imagesMongo := make([]AuctionImageMongo, 0, len(images))
+	for _, image := range images {
+		imagesMongo = append(imagesMongo, AuctionImageMongo{
+			Id:           image.Id,
+			Position:     image.Position,
+			ContentType:  image.ContentType,
+			Size:         image.Size,
+			Width:        image.Width,
+			Height:       image.Height,
+			ObjectKey:    image.ObjectKey,
+			ThumbnailKey: image.ThumbnailKey,
+			UploadedAt:   image.UploadedAt.UnixMilli(),
+		})
+	}
+	return imagesMongo
+}
+
+func toAuctionImages(imagesMongo []AuctionImageMongo) []auction_entity.AuctionImage {
+	images := make([]auction_entity.AuctionImage, 0, len(imagesMongo))
+	for _, imageMongo := range imagesMongo {
+		images = append(images, auction_entity.AuctionImage{
+			Id:           imageMongo.Id,
+			Position:     imageMongo.Position,
+			ContentType:  imageMongo.ContentType,
+			Size:         imageMongo.Size,
+			Width:        imageMongo.Width,
+			Height:       imageMongo.Height,
+			ObjectKey:    imageMongo.ObjectKey,
+			ThumbnailKey: imageMongo.ThumbnailKey,
+			UploadedAt:   storedtime.ToTime(imageMongo.UploadedAt),
+		})
+	}
+
+	sort.SliceStable(images, func(i, j int) bool {
+		return images[i].Position < images[j].Position
+	})
+
+	return images
+}
@@ -3,11 +3,15 @@ package auction
 import (
 	"context"
 	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/pagination_entity"
+	"fullcycle-auction_go/internal/entity/search_entity"
+	"fullcycle-auction_go/internal/infra/scheduler"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -43,14 +47,10 @@ func TestAutomaticAuctionClosing(t *testing.T) {
 		t.Skip("Skipping integration test in short mode")
 	}
 
-	// Definir um timeout curto para o teste
-	os.Setenv("AUCTION_INTERVAL", "3s")
-	defer os.Unsetenv("AUCTION_INTERVAL")
-
 	db, cleanup := SetupTestDatabase(t)
 	defer cleanup()
 
-	repo := NewAuctionRepository(db)
+	repo := NewAuctionRepository(db, AuctionRepositoryConfig{AuctionDuration: 3 * time.Second})
 	defer repo.Cleanup()
 
 	// Criar leilão com timestamp no passado para que expire rapidamente
@@ -77,3 +77,133 @@ func TestAutomaticAuctionClosing(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, auction_entity.Completed, auctionFromDB.Status, "Auction should be automatically closed")
 }
+
+func TestSearchAuctionsAndProductNameFilter(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := SetupTestDatabase(t)
+	defer cleanup()
+
+	repo := NewAuctionRepository(db, AuctionRepositoryConfig{})
+	defer repo.Cleanup()
+
+	ctx := context.Background()
+	for _, auction := range []*auction_entity.Auction{
+		{Id: "search-1", ProductName: "Smartphone XYZ", Category: "Electronics",
+			Description: "Brand new smartphone with great features", Condition: auction_entity.New, Timestamp: time.Now()},
+		{Id: "search-2", ProductName: "Wooden Chair", Category: "Furniture",
+			Description: "Old chair, works with any smartphone stand", Condition: auction_entity.Used, Timestamp: time.Now()},
+	} {
+		assert.Nil(t, repo.CreateAuction(ctx, auction))
+	}
+
+	// Caracteres de regex no filtro são tratados literalmente
+	page, err := repo.FindAuctions(ctx, auction_entity.AuctionFilter{ProductName: "phone xy"}, pagination_entity.PageRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(page.Auctions))
+
+	page, err = repo.FindAuctions(ctx, auction_entity.AuctionFilter{ProductName: ".*"}, pagination_entity.PageRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(page.Auctions))
+
+	query, _ := search_entity.ParseQuery("smartphone")
+	hits, err := repo.SearchAuctions(ctx, query, auction_entity.AuctionFilter{}, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(hits))
+	assert.Equal(t, "search-1", hits[0].Auction.Id, "Product name matches should rank first")
+	assert.Contains(t, hits[0].Highlights["product_name"], "<mark>Smartphone</mark>")
+}
+
+func TestAdminAuctionOperations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := SetupTestDatabase(t)
+	defer cleanup()
+
+	repo := NewAuctionRepository(db, AuctionRepositoryConfig{DisableAutoClose: true})
+	defer repo.Cleanup()
+
+	ctx := context.Background()
+	now := time.Now()
+	for _, id := range []string{"admin-1", "admin-2"} {
+		assert.Nil(t, repo.CreateAuction(ctx, &auction_entity.Auction{
+			Id: id, ProductName: "Wooden Chair", Category: "Furniture",
+			Description: "Old chair in good condition", Condition: auction_entity.Used,
+			Status: auction_entity.Active, Timestamp: now, EndTime: now.Add(time.Minute),
+		}))
+	}
+
+	extended, err := repo.ExtendAuction(ctx, "admin-1", now.Add(time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, now.Add(time.Hour).UnixMilli(), extended.EndTime.UnixMilli())
+
+	_, err = repo.ExtendAuction(ctx, "admin-1", now.Add(30*time.Minute))
+	assert.NotNil(t, err, "The end time cannot be brought forward")
+
+	// O prazo antigo ainda agendado não fecha um leilão prorrogado
+	closed, err := repo.closeAuction(ctx, "admin-1", now.Add(2*time.Minute), 0)
+	assert.Nil(t, err)
+	assert.False(t, closed)
+
+	cancelled, err := repo.CancelAuction(ctx, "admin-1", "Fraudulent listing")
+	assert.Nil(t, err)
+	assert.Equal(t, auction_entity.Cancelled, cancelled.Status)
+	assert.Equal(t, "Fraudulent listing", cancelled.CancelReason)
+
+	_, err = repo.ForceCloseAuction(ctx, "admin-1")
+	assert.Equal(t, "bad_request", err.Err)
+
+	forced, err := repo.ForceCloseAuction(ctx, "admin-2")
+	assert.Nil(t, err)
+	assert.Equal(t, auction_entity.Completed, forced.Status)
+
+	page, err := repo.FindAuctions(ctx, auction_entity.AuctionFilter{
+		Statuses: []auction_entity.AuctionStatus{auction_entity.Cancelled},
+	}, pagination_entity.PageRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(page.Auctions))
+	assert.Equal(t, "admin-1", page.Auctions[0].Id)
+}
+
+func TestStaleLeaderCannotCloseFencedAuction(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := SetupTestDatabase(t)
+	defer cleanup()
+
+	repo := NewAuctionRepository(db, AuctionRepositoryConfig{DisableAutoClose: true})
+	defer repo.Cleanup()
+
+	ctx := context.Background()
+	now := time.Now()
+	assert.Nil(t, repo.CreateAuction(ctx, &auction_entity.Auction{
+		Id: "fenced", ProductName: "Wooden Chair", Category: "Furniture",
+		Description: "Old chair in good condition", Condition: auction_entity.Used,
+		Status: auction_entity.Active, Timestamp: now.Add(-time.Hour), EndTime: now.Add(-time.Minute),
+	}))
+
+	// A nova líder, com o token 5, assume o leilão ao carregá-lo
+	loaded, err := repo.scheduleActiveAuctions(ctx,
+		scheduler.New(scheduler.RealClock{}, func(string) {}), bson.M{"_id": "fenced"}, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, loaded)
+
+	closed, closeErr := repo.closeAuction(ctx, "fenced", now, 3)
+	assert.Nil(t, closeErr)
+	assert.False(t, closed, "A leader with an older token cannot close the auction")
+
+	closed, closeErr = repo.closeAuction(ctx, "fenced", now, 5)
+	assert.Nil(t, closeErr)
+	assert.True(t, closed)
+
+	var stored AuctionEntityMongo
+	assert.NoError(t, repo.Collection.FindOne(ctx, bson.M{"_id": "fenced"}).Decode(&stored))
+	assert.Equal(t, auction_entity.Completed, stored.Status)
+	assert.EqualValues(t, 5, stored.CloserToken)
+}
@@ -2,223 +2,682 @@ package auction
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"fullcycle-auction_go/configuration/logger"
 	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/infra/auctionstate"
+	"fullcycle-auction_go/internal/infra/database/instrument"
+	"fullcycle-auction_go/internal/infra/database/lease"
+	"fullcycle-auction_go/internal/infra/database/storedtime"
+	"fullcycle-auction_go/internal/infra/leader"
+	"fullcycle-auction_go/internal/infra/metrics"
+	"fullcycle-auction_go/internal/infra/scheduler"
 	"fullcycle-auction_go/internal/internal_error"
-	"os"
 	"sync"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
 )
 
 type AuctionEntityMongo struct {
 	Id          string                          `bson:"_id"`
+	SellerId    string                          `bson:"seller_id,omitempty"`
 	ProductName string                          `bson:"product_name"`
+	CategoryId  string                          `bson:"category_id,omitempty"`
 	Category    string                          `bson:"category"`
 	Description string                          `bson:"description"`
 	Condition   auction_entity.ProductCondition `bson:"condition"`
 	Status      auction_entity.AuctionStatus    `bson:"status"`
-	Timestamp   int64                           `bson:"timestamp"`
+	Timestamp   int64                           `bson:"timestamp"` // milissegundos desde a época Unix
+	// EndTime (em milissegundos) e CurrentPrice são mantidos no documento para
+	// permitir filtros e ordenação na listagem
+	EndTime      int64               `bson:"end_time"`
+	CurrentPrice float64             `bson:"current_price"`
+	BidCount     int64               `bson:"bid_count"`
+	Images       []AuctionImageMongo `bson:"images,omitempty"`
+	// Gravados apenas no cancelamento e no relançamento pela administração
+	CancelReason string `bson:"cancel_reason,omitempty"`
+	CancelledAt  int64  `bson:"cancelled_at,omitempty"`
+	RelistedFrom string `bson:"relisted_from,omitempty"`
+	// CloserToken é o maior fencing token de líder que agendou ou fechou o
+	// leilão; fechamentos com um token menor são recusados
+	CloserToken int64 `bson:"closer_token,omitempty"`
 }
 
+// optionalTimeFromStorage converte timestamps opcionais, em que zero é ausência
+func optionalTimeFromStorage(value int64) time.Time {
+	if value == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(value)
+}
+
+// closeRetryDelay é a espera antes de tentar de novo um fechamento que falhou
+const closeRetryDelay = 10 * time.Second
+
+// closeTimeout limita cada fechamento automático: o scheduler executa os
+// fechamentos um de cada vez, e um banco lento não pode atrasar os seguintes
+// indefinidamente. Um fechamento que estoura o prazo é reagendado.
+const closeTimeout = 5 * time.Second
+
+// auctionCloserLease é a concessão disputada pelas réplicas; só a líder fecha leilões
+const auctionCloserLease = "auction-closer"
+
 type AuctionRepository struct {
 	Collection     *mongo.Collection
 	auctionTimeout time.Duration
-	auctionsMutex  sync.RWMutex
-	activeAuctions map[string]time.Time // mapa de leilões ativos e seus timestamps
-	closeChan      chan struct{}
-	ctx            context.Context
-	cancel         context.CancelFunc
+	// events notifica as mudanças de estado dos leilões; states é o cache
+	// compartilhado com o repositório de lances, atualizado por esses eventos
+	events *auctionstate.Bus
+	states *auctionstate.Cache
+	// elector garante que uma única réplica execute o fechamento automático
+	elector *leader.Elector
+	// syncInterval é o intervalo em que a líder busca leilões criados em
+	// outras réplicas que terminam em breve
+	syncInterval time.Duration
+	// closeScheduler fecha cada leilão ativo no seu horário de término; só
+	// existe enquanto esta réplica é a líder
+	schedulerMutex sync.RWMutex
+	closeScheduler *scheduler.Scheduler
+	// closerLoaded é fechado quando a primeira carga dos leilões ativos termina
+	closerLoaded chan struct{}
+	loadedOnce   sync.Once
+	closeChan    chan struct{}
+	closeOnce    sync.Once
+	// closerDone é fechado quando a goroutine de fechamento automático termina
+	closerDone chan struct{}
+	ctx        context.Context
+	cancel     context.CancelFunc
+}
+
+// AuctionRepositoryConfig define a duração dos leilões e o fechamento
+// automático; valores zerados usam o padrão
+type AuctionRepositoryConfig struct {
+	// AuctionDuration é quanto tempo um leilão fica aberto
+	AuctionDuration time.Duration
+	// CloserSyncInterval é o intervalo em que a líder busca leilões criados
+	// em outras réplicas que terminam em breve
+	CloserSyncInterval time.Duration
+	// StateCacheTTL é por quanto tempo um estado lido do banco é usado sem
+	// notificação de mudança
+	StateCacheTTL time.Duration
+	// ChangeStream acompanha as mudanças feitas nas demais réplicas
+	ChangeStream bool
+	// Concessão disputada pelas réplicas para o fechamento automático
+	LeaseTTL           time.Duration
+	LeaseRenewInterval time.Duration
+	LeaseRetryInterval time.Duration
+	// DisableAutoClose deixa o repositório fora da disputa de liderança, para
+	// processos que só operam leilões, como a CLI de administração
+	DisableAutoClose bool
 }
 
-func NewAuctionRepository(database *mongo.Database) *AuctionRepository {
+func (c AuctionRepositoryConfig) normalized() AuctionRepositoryConfig {
+	if c.AuctionDuration <= 0 {
+		c.AuctionDuration = 5 * time.Minute
+	}
+	if c.CloserSyncInterval <= 0 {
+		c.CloserSyncInterval = 10 * time.Second
+	}
+	if c.StateCacheTTL <= 0 {
+		c.StateCacheTTL = 30 * time.Second
+	}
+	return c
+}
+
+func NewAuctionRepository(database *mongo.Database, config AuctionRepositoryConfig) *AuctionRepository {
+	config = config.normalized()
 	ctx, cancel := context.WithCancel(context.Background())
 	repo := &AuctionRepository{
 		Collection:     database.Collection("auctions"),
-		auctionTimeout: getAuctionTimeout(),
-		activeAuctions: make(map[string]time.Time),
-		auctionsMutex:  sync.RWMutex{},
-		closeChan:      make(chan struct{}),
-		ctx:            ctx,
-		cancel:         cancel,
+		auctionTimeout: config.AuctionDuration,
+		events:         auctionstate.NewBus(),
+		states:         auctionstate.NewCache(config.StateCacheTTL),
+		elector: leader.NewElector(
+			lease.NewLeaseRepository(database),
+			leader.NewConfig(auctionCloserLease,
+				config.LeaseTTL, config.LeaseRenewInterval, config.LeaseRetryInterval),
+			scheduler.RealClock{}),
+		syncInterval: config.CloserSyncInterval,
+		closerLoaded: make(chan struct{}),
+		closeChan:    make(chan struct{}),
+		closerDone:   make(chan struct{}),
+		ctx:          ctx,
+		cancel:       cancel,
 	}
 
-	// Carrega leilões ativos existentes no banco de dados
-	go repo.loadExistingActiveAuctions(ctx)
+	repo.events.Subscribe(repo.states.Apply)
 
-	// Inicia a goroutine que verificará os leilões expirados
-	go repo.checkExpiredAuctions()
+	// Cria os índices usados pela busca textual e pelo fechamento automático
+	go repo.ensureIndexes(ctx)
+
+	// Com várias réplicas, as mudanças feitas nas demais chegam pelo change stream
+	if config.ChangeStream {
+		go repo.watchAuctionChanges(ctx)
+	}
+
+	// Disputa a liderança; a líder carrega os leilões ativos e os fecha no
+	// horário de término
+	if config.DisableAutoClose {
+		close(repo.closerDone)
+	} else {
+		go repo.runAuctionCloser()
+	}
 
 	return repo
 }
 
 // loadExistingActiveAuctions carrega leilões ativos existentes no banco de dados
-func (ar *AuctionRepository) loadExistingActiveAuctions(ctx context.Context) {
+func (ar *AuctionRepository) loadExistingActiveAuctions(
+	ctx context.Context, closeScheduler *scheduler.Scheduler, token int64) {
 	// Criar filtro para leilões ativos
 	filter := bson.M{"status": auction_entity.Active}
 
-	// Encontrar todos os leilões ativos
-	cursor, err := ar.Collection.Find(ctx, filter)
+	loaded, err := ar.scheduleActiveAuctions(ctx, closeScheduler, filter, token)
 	if err != nil {
-		logger.Error("Error trying to load existing active auctions", err)
+		logger.ErrorContext(ctx, "Error trying to load existing active auctions", err)
 		return
 	}
+
+	logger.InfoContext(ctx, "Loaded existing active auctions", zap.Int("count", loaded))
+}
+
+// syncEndingAuctions agenda periodicamente os leilões ativos que terminam
+// antes da próxima sincronização. Leilões criados nesta réplica já são
+// agendados na criação; a busca cobre os criados nas demais.
+func (ar *AuctionRepository) syncEndingAuctions(
+	closeScheduler *scheduler.Scheduler, token int64, stop <-chan struct{}) {
+	ticker := time.NewTicker(ar.syncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			horizon := time.Now().Add(2 * ar.syncInterval)
+			filter := bson.M{
+				"status":   auction_entity.Active,
+				"end_time": bson.M{"$lte": horizon.UnixMilli()},
+			}
+
+			if _, err := ar.scheduleActiveAuctions(ar.ctx, closeScheduler, filter, token); err != nil {
+				logger.Error("Error trying to sync ending auctions", err)
+			}
+
+		case <-stop:
+			return
+		}
+	}
+}
+
+// scheduleActiveAuctions agenda o fechamento dos leilões encontrados por
+// filter. Antes, grava neles o fencing token da líder: a partir daí uma líder
+// antiga, com token menor, não consegue mais fechá-los.
+func (ar *AuctionRepository) scheduleActiveAuctions(
+	ctx context.Context,
+	closeScheduler *scheduler.Scheduler,
+	filter bson.M,
+	token int64) (int, error) {
+	if token > 0 {
+		if _, err := ar.Collection.UpdateMany(ctx, filter, bson.M{"$max": bson.M{"closer_token": token}}); err != nil {
+			return 0, err
+		}
+	}
+
+	// Encontrar os leilões ativos
+	cursor, err := ar.Collection.Find(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
 	defer cursor.Close(ctx)
 
 	var auctionsMongo []AuctionEntityMongo
 	if err := cursor.All(ctx, &auctionsMongo); err != nil {
-		logger.Error("Error trying to decode active auctions", err)
-		return
+		return 0, err
 	}
 
-	// Adicionar leilões ao mapa
+	// Agendar o fechamento de cada leilão
 	now := time.Now()
-	ar.auctionsMutex.Lock()
-	defer ar.auctionsMutex.Unlock()
-
 	for _, auction := range auctionsMongo {
+		// Já agendado por esta réplica, possivelmente com um horário mais recente
+		if _, ok := closeScheduler.Deadline(auction.Id); ok {
+			continue
+		}
+
 		// Calcular quando o leilão deve terminar
-		creationTime := time.Unix(auction.Timestamp, 0)
-		endTime := creationTime.Add(ar.auctionTimeout)
+		endTime := ar.toAuctionEntity(auction).EndTime
 
-		// Se o leilão já expirou, marque-o para fechamento imediato
-		// Caso contrário, adicione-o ao mapa com seu tempo de expiração
+		// Se o leilão já expirou, agende-o para fechamento imediato
+		// Caso contrário, agende-o para o seu tempo de expiração
 		if now.After(endTime) {
-			// Leilão já deveria estar fechado, agende para fechamento imediato
-			// Usando um tempo ligeiramente no futuro para permitir que o sistema inicialize
-			ar.activeAuctions[auction.Id] = now.Add(5 * time.Second)
-			logger.Info(fmt.Sprintf("Found expired auction %s, scheduling for immediate closure", auction.Id))
+			closeScheduler.Schedule(auction.Id, now)
+			logger.InfoContext(ctx, "Found expired auction, scheduling for immediate closure", zap.String("auction_id", auction.Id))
 		} else {
-			// Leilão ainda está ativo, adicione com seu tempo de expiração normal
-			ar.activeAuctions[auction.Id] = endTime
-			logger.Info(fmt.Sprintf("Loaded active auction %s, will expire at %s", auction.Id, endTime.Format(time.RFC3339)))
+			// Leilão ainda está ativo, agende com seu tempo de expiração normal
+			closeScheduler.Schedule(auction.Id, endTime)
+			logger.InfoContext(ctx, "Loaded active auction", zap.String("auction_id", auction.Id), zap.Time("end_time", endTime))
 		}
 	}
 
-	logger.Info(fmt.Sprintf("Loaded %d existing active auctions", len(auctionsMongo)))
+	return len(auctionsMongo), nil
+}
+
+// runAuctionCloser disputa a liderança até o repositório ser encerrado
+func (ar *AuctionRepository) runAuctionCloser() {
+	defer close(ar.closerDone)
+
+	ar.elector.Run(ar.closeChan, ar.leadAuctionCloser)
 }
 
-// getAuctionTimeout obtém o tempo de duração do leilão a partir de variáveis de ambiente
-func getAuctionTimeout() time.Duration {
-	auctionInterval := os.Getenv("AUCTION_INTERVAL")
-	duration, err := time.ParseDuration(auctionInterval)
+// leadAuctionCloser executa o fechamento automático enquanto esta réplica é
+// a líder. Cada mandato começa com um scheduler novo, carregado do banco, já
+// que a réplica anterior pode ter fechado ou agendado outros leilões.
+func (ar *AuctionRepository) leadAuctionCloser(leaderStop <-chan struct{}) {
+	// O token do mandato é gravado nos leilões carregados e em cada fechamento
+	token, _ := ar.elector.Token()
+
+	closeScheduler := scheduler.New(scheduler.RealClock{}, ar.closeExpiredAuction)
+	ar.setCloseScheduler(closeScheduler)
+	defer ar.setCloseScheduler(nil)
+
+	ar.loadExistingActiveAuctions(ar.ctx, closeScheduler, token)
+	ar.loadedOnce.Do(func() { close(ar.closerLoaded) })
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ar.syncEndingAuctions(closeScheduler, token, leaderStop)
+	}()
+
+	closeScheduler.Run(leaderStop)
+	wg.Wait()
+}
+
+// closeExpiredAuction é chamado pelo scheduler no horário de término do
+// leilão; em caso de falha o fechamento é reagendado
+func (ar *AuctionRepository) closeExpiredAuction(auctionID string) {
+	// A liderança pode ter vencido desde que o prazo foi agendado
+	token, ok := ar.elector.Token()
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ar.ctx, closeTimeout)
+	defer cancel()
+
+	closed, err := ar.closeAuction(ctx, auctionID, time.Now(), token)
 	if err != nil {
-		logger.Error("Error parsing AUCTION_INTERVAL, using default value of 5 minutes", err)
-		return time.Minute * 5
+		logger.Error("Failed to close auction", err, zap.String("auction_id", auctionID))
+		if closeScheduler := ar.getCloseScheduler(); closeScheduler != nil {
+			closeScheduler.Schedule(auctionID, time.Now().Add(closeRetryDelay))
+		}
+		return
+	}
+
+	if closed {
+		logger.Info("Auction closed automatically", zap.String("auction_id", auctionID),
+			zap.Int64("leader_token", token))
+	}
+}
+
+// RescheduleAuctionClose muda o horário de fechamento automático de um
+// leilão ativo, usado quando o leilão é prorrogado, e avisa o cache de
+// estado. Fora da líder o agendamento não muda: a líder encontra o novo
+// horário na próxima sincronização.
+func (ar *AuctionRepository) RescheduleAuctionClose(auctionID string, endTime time.Time) {
+	ar.events.Publish(auctionstate.Event{AuctionId: auctionID, Type: auctionstate.Extended, EndTime: endTime})
+	ar.scheduleClose(auctionID, endTime)
+}
+
+// CancelAuctionClose tira o leilão do fechamento automático, usado quando o
+// leilão é cancelado; informa se ele estava agendado nesta réplica
+func (ar *AuctionRepository) CancelAuctionClose(auctionID string) bool {
+	ar.events.Publish(auctionstate.Event{AuctionId: auctionID, Type: auctionstate.Cancelled})
+
+	if closeScheduler := ar.getCloseScheduler(); closeScheduler != nil {
+		return closeScheduler.Cancel(auctionID)
 	}
-	return duration
+	return false
 }
 
-func getAuctionCheckInterval() time.Duration {
-	checkInterval := os.Getenv("AUCTION_CHECK_INTERVAL")
-	duration, err := time.ParseDuration(checkInterval)
+// AuctionEvents é o barramento das mudanças de estado dos leilões
+func (ar *AuctionRepository) AuctionEvents() *auctionstate.Bus {
+	return ar.events
+}
+
+// AuctionStates é o cache de estado usado na aceitação de lances
+func (ar *AuctionRepository) AuctionStates() *auctionstate.Cache {
+	return ar.states
+}
+
+func (ar *AuctionRepository) scheduleClose(auctionID string, endTime time.Time) {
+	if closeScheduler := ar.getCloseScheduler(); closeScheduler != nil {
+		closeScheduler.Schedule(auctionID, endTime)
+	}
+}
+
+func (ar *AuctionRepository) getCloseScheduler() *scheduler.Scheduler {
+	ar.schedulerMutex.RLock()
+	defer ar.schedulerMutex.RUnlock()
+	return ar.closeScheduler
+}
+
+func (ar *AuctionRepository) setCloseScheduler(closeScheduler *scheduler.Scheduler) {
+	ar.schedulerMutex.Lock()
+	defer ar.schedulerMutex.Unlock()
+	ar.closeScheduler = closeScheduler
+}
+
+// closeAuction marca o leilão como completo se o término já passou em now. O
+// filtro por status torna o fechamento idempotente: repetir o fechamento não
+// altera nada. O filtro por término mantém aberto um leilão prorrogado por
+// outro processo, que a sincronização reagenda. Com um fencing token, o
+// fechamento é recusado se uma líder mais nova já assumiu o leilão, e o token
+// fica gravado; token zero fecha sem essa verificação, como na recuperação.
+// Informa se o leilão foi fechado agora.
+func (ar *AuctionRepository) closeAuction(
+	ctx context.Context, auctionID string, now time.Time, token int64) (bool, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "auction", "closeAuction")
+	defer done()
+
+	filter := bson.M{"_id": auctionID, "status": auction_entity.Active, "$or": endedBy(now)}
+	set := bson.M{"status": auction_entity.Completed}
+	if token > 0 {
+		// $not também aceita documentos ainda sem closer_token
+		filter["closer_token"] = bson.M{"$not": bson.M{"$gt": token}}
+		set["closer_token"] = token
+	}
+	update := bson.M{"$set": set}
+	// O término previsto vem do documento anterior, para medir o atraso do fechamento
+	opts := options.FindOneAndUpdate().SetProjection(bson.M{"timestamp": 1, "end_time": 1})
+
+	var auctionMongo AuctionEntityMongo
+	err := ar.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&auctionMongo)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		logger.ErrorContext(ctx, "Error closing auction", err)
+		return false, internal_error.NewInternalServerError(fmt.Sprintf("Error closing auction %s", auctionID))
+	}
+
 	if err != nil {
-		logger.Error("Error parsing AUCTION_CHECK_INTERVAL, using default value of 10 seconds", err)
-		return time.Second * 10
+		// Outro processo fechou, cancelou ou prorrogou o leilão; o cache local
+		// é corrigido com o estado atual
+		ar.publishStoredState(ctx, auctionID)
+		return false, nil
 	}
-	return duration
+
+	endTime := ar.toAuctionEntity(auctionMongo).EndTime
+	ar.events.Publish(auctionstate.Event{AuctionId: auctionID, Type: auctionstate.Closed, EndTime: endTime})
+	metrics.ObserveAuctionClose(endTime, time.Now())
+	return true, nil
 }
 
-// checkExpiredAuctions verifica periodicamente os leilões expirados e os fecha
-func (ar *AuctionRepository) checkExpiredAuctions() {
-	ticker := time.NewTicker(getAuctionCheckInterval())
-	defer ticker.Stop()
+// endedBy seleciona os leilões cujo término já passou em now; documentos
+// antigos sem end_time têm o término calculado na conversão
+func endedBy(now time.Time) bson.A {
+	return bson.A{
+		bson.M{"end_time": bson.M{"$lte": now.UnixMilli()}},
+		bson.M{"end_time": bson.M{"$exists": false}},
+	}
+}
 
-	for {
-		select {
-		case <-ticker.C:
-			now := time.Now()
-			var expiredAuctions []string
-
-			// Identificar leilões expirados (com lock de leitura)
-			ar.auctionsMutex.RLock()
-			for auctionID, endTime := range ar.activeAuctions {
-				if now.After(endTime) {
-					expiredAuctions = append(expiredAuctions, auctionID)
-				}
-			}
-			ar.auctionsMutex.RUnlock()
-
-			// Fechar leilões expirados
-			for _, auctionID := range expiredAuctions {
-				if err := ar.closeAuction(ar.ctx, auctionID); err != nil {
-					logger.Error(fmt.Sprintf("Failed to close auction %s", auctionID), err)
-				} else {
-					// Remover do mapa após fechar com sucesso (com lock de escrita)
-					ar.auctionsMutex.Lock()
-					delete(ar.activeAuctions, auctionID)
-					ar.auctionsMutex.Unlock()
-					logger.Info(fmt.Sprintf("Auction %s closed automatically", auctionID))
-				}
-			}
+// openAt seleciona os leilões cujo término ainda não passou em at, o oposto
+// de endedBy. Documentos antigos sem end_time terminam em timestamp mais a
+// duração configurada, como na conversão, com o timestamp em segundos ou em
+// milissegundos.
+func (ar *AuctionRepository) openAt(at time.Time) bson.A {
+	createdAfter := at.Add(-ar.auctionTimeout)
+	return bson.A{
+		bson.M{"end_time": bson.M{"$gte": at.UnixMilli()}},
+		bson.M{"end_time": bson.M{"$exists": false}, "timestamp": bson.M{
+			"$gte": createdAfter.UnixMilli(),
+		}},
+		bson.M{"end_time": bson.M{"$exists": false}, "timestamp": bson.M{
+			"$gte": (createdAfter.UnixMilli() + 999) / 1000,
+			"$lt":  storedtime.LegacySecondsThreshold,
+		}},
+	}
+}
 
-		case <-ar.closeChan:
-			return
-		}
+// publishStoredState publica o estado do leilão lido do banco
+func (ar *AuctionRepository) publishStoredState(ctx context.Context, auctionID string) {
+	var auctionMongo AuctionEntityMongo
+	err := ar.Collection.FindOne(ctx, bson.M{"_id": auctionID}).Decode(&auctionMongo)
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		ar.events.Publish(auctionstate.Event{AuctionId: auctionID, Type: auctionstate.Removed})
+	case err != nil:
+		logger.ErrorContext(ctx, "Error trying to refresh auction state", err, zap.String("auction_id", auctionID))
+	default:
+		auctionEntity := ar.toAuctionEntity(auctionMongo)
+		ar.events.Publish(auctionstate.Event{
+			AuctionId: auctionID,
+			Type:      auctionstate.Changed,
+			Status:    auctionEntity.Status,
+			EndTime:   auctionEntity.EndTime,
+		})
 	}
 }
 
-// closeAuction atualiza o status do leilão para completo no banco de dados
-func (ar *AuctionRepository) closeAuction(ctx context.Context, auctionID string) *internal_error.InternalError {
-	filter := bson.M{"_id": auctionID}
-	update := bson.M{"$set": bson.M{"status": auction_entity.Completed}}
+// CountActiveAuctions conta os leilões com status ativo
+func (ar *AuctionRepository) CountActiveAuctions(ctx context.Context) (int64, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "auction", "CountActiveAuctions")
+	defer done()
 
-	_, err := ar.Collection.UpdateOne(ctx, filter, update)
+	count, err := ar.Collection.CountDocuments(ctx, bson.M{"status": auction_entity.Active})
 	if err != nil {
-		logger.Error(fmt.Sprintf("Error closing auction %s", auctionID), err)
-		return internal_error.NewInternalServerError(fmt.Sprintf("Error closing auction %s", auctionID))
+		logger.ErrorContext(ctx, "Error trying to count active auctions", err)
+		return 0, internal_error.NewInternalServerError("Error trying to count active auctions")
+	}
+	return count, nil
+}
+
+// BidRegistration é o registro de um lance no leilão, com o necessário para
+// desfazê-lo se a gravação do lance falhar
+type BidRegistration struct {
+	// Sequence é o novo contador de lances, usado como número de sequência
+	Sequence int64
+	// PreviousPrice é o preço corrente antes do lance
+	PreviousPrice float64
+}
+
+// RegisterAcceptedBid incrementa atomicamente o contador de lances do leilão
+// e atualiza o preço corrente. O banco decide se o leilão aceita o lance: a
+// atualização só acontece se bidTime, o horário em que o lance foi feito, não
+// passou do término e o leilão não foi cancelado. Um leilão já fechado ainda
+// recebe os lances anteriores ao término gravados com atraso, como os da fila
+// ou os relidos do write-ahead log. O cache de estados é só um atalho para
+// recusar antes. Leilões que não aceitam o lance resultam em bad_request.
+func (ar *AuctionRepository) RegisterAcceptedBid(
+	ctx context.Context, auctionID string, amount float64, bidTime time.Time) (*BidRegistration, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "auction", "RegisterAcceptedBid")
+	defer done()
+
+	filter := bson.M{
+		"_id":    auctionID,
+		"status": bson.M{"$in": bson.A{auction_entity.Active, auction_entity.Completed}},
+		"$or":    ar.openAt(bidTime),
+	}
+	update := bson.M{
+		"$inc": bson.M{"bid_count": 1},
+		"$max": bson.M{"current_price": amount},
+	}
+	// O documento anterior à atualização traz o preço a restaurar num desfazimento
+	opts := options.FindOneAndUpdate().
+		SetReturnDocument(options.Before).
+		SetProjection(bson.M{"bid_count": 1, "current_price": 1})
+
+	var auctionMongo AuctionEntityMongo
+	if err := ar.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&auctionMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			// O cache aceitou o lance com um estado desatualizado
+			ar.publishStoredState(ctx, auctionID)
+			return nil, internal_error.NewBadRequestError("Auction is not accepting bids")
+		}
+		logger.ErrorContext(ctx, "Error assigning bid sequence", err)
+		return nil, internal_error.NewInternalServerError("Error assigning bid sequence")
+	}
+
+	return &BidRegistration{
+		Sequence:      auctionMongo.BidCount + 1,
+		PreviousPrice: auctionMongo.CurrentPrice,
+	}, nil
+}
+
+// UndoBidRegistration desfaz RegisterAcceptedBid quando o lance não chegou a
+// ser gravado. O contador só volta se nenhum lance foi registrado depois,
+// deixando uma lacuna na sequência em vez de repetir números, e o preço só é
+// restaurado se ainda for o do lance desfeito.
+func (ar *AuctionRepository) UndoBidRegistration(
+	ctx context.Context,
+	auctionID string,
+	registration BidRegistration,
+	amount float64) *internal_error.InternalError {
+	ctx, done := instrument.Operation(ctx, "auction", "UndoBidRegistration")
+	defer done()
+
+	filter := bson.M{"_id": auctionID, "bid_count": registration.Sequence}
+	if _, err := ar.Collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"bid_count": -1}}); err != nil {
+		logger.ErrorContext(ctx, "Error trying to undo bid registration", err)
+		return internal_error.NewInternalServerError("Error trying to undo bid registration")
+	}
+
+	if amount > registration.PreviousPrice {
+		return ar.ResetCurrentPrice(ctx, auctionID, amount, registration.PreviousPrice)
+	}
+	return nil
+}
+
+// ResetCurrentPrice substitui o preço corrente apenas se ele ainda for
+// expectedPrice, evitando sobrescrever um lance maior aceito em paralelo
+func (ar *AuctionRepository) ResetCurrentPrice(
+	ctx context.Context, auctionID string, expectedPrice, price float64) *internal_error.InternalError {
+	ctx, done := instrument.Operation(ctx, "auction", "ResetCurrentPrice")
+	defer done()
+
+	filter := bson.M{"_id": auctionID, "current_price": expectedPrice}
+	update := bson.M{"$set": bson.M{"current_price": price}}
+
+	if _, err := ar.Collection.UpdateOne(ctx, filter, update); err != nil {
+		logger.ErrorContext(ctx, "Error resetting auction current price", err)
+		return internal_error.NewInternalServerError("Error resetting auction current price")
 	}
 
 	return nil
 }
 
+// toAuctionEntity converte o documento persistido; documentos antigos sem
+// end_time têm o término calculado a partir da duração configurada
+func (ar *AuctionRepository) toAuctionEntity(auctionMongo AuctionEntityMongo) auction_entity.Auction {
+	timestamp := storedtime.ToTime(auctionMongo.Timestamp)
+
+	endTime := timestamp.Add(ar.auctionTimeout)
+	if auctionMongo.EndTime > 0 {
+		endTime = storedtime.ToTime(auctionMongo.EndTime)
+	}
+
+	return auction_entity.Auction{
+		Id:           auctionMongo.Id,
+		SellerId:     auctionMongo.SellerId,
+		ProductName:  auctionMongo.ProductName,
+		CategoryId:   auctionMongo.CategoryId,
+		Category:     auctionMongo.Category,
+		Description:  auctionMongo.Description,
+		Condition:    auctionMongo.Condition,
+		Status:       auctionMongo.Status,
+		Timestamp:    timestamp,
+		EndTime:      endTime,
+		CurrentPrice: auctionMongo.CurrentPrice,
+		BidCount:     auctionMongo.BidCount,
+		Images:       toAuctionImages(auctionMongo.Images),
+		CancelReason: auctionMongo.CancelReason,
+		CancelledAt:  optionalTimeFromStorage(auctionMongo.CancelledAt),
+		RelistedFrom: auctionMongo.RelistedFrom,
+	}
+}
+
 // Cleanup encerra as goroutines e recursos associados
 func (ar *AuctionRepository) Cleanup() {
-	close(ar.closeChan)
+	ar.closeOnce.Do(func() { close(ar.closeChan) })
 	ar.cancel()
 }
 
+// Shutdown para o fechamento automático de leilões, esperando a rodada em
+// andamento terminar até o prazo de ctx; só então as operações pendentes são
+// canceladas
+func (ar *AuctionRepository) Shutdown(ctx context.Context) error {
+	ar.closeOnce.Do(func() { close(ar.closeChan) })
+	defer ar.cancel()
+
+	select {
+	case <-ar.closerDone:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (ar *AuctionRepository) CreateAuction(
 	ctx context.Context,
 	auctionEntity *auction_entity.Auction) *internal_error.InternalError {
+	ctx, done := instrument.Operation(ctx, "auction", "CreateAuction")
+	defer done()
+
+	if auctionEntity.EndTime.IsZero() {
+		auctionEntity.EndTime = auctionEntity.Timestamp.Add(ar.auctionTimeout)
+	}
+
 	auctionEntityMongo := &AuctionEntityMongo{
-		Id:          auctionEntity.Id,
-		ProductName: auctionEntity.ProductName,
-		Category:    auctionEntity.Category,
-		Description: auctionEntity.Description,
-		Condition:   auctionEntity.Condition,
-		Status:      auctionEntity.Status,
-		Timestamp:   auctionEntity.Timestamp.Unix(),
+		Id:           auctionEntity.Id,
+		SellerId:     auctionEntity.SellerId,
+		ProductName:  auctionEntity.ProductName,
+		CategoryId:   auctionEntity.CategoryId,
+		Category:     auctionEntity.Category,
+		Description:  auctionEntity.Description,
+		Condition:    auctionEntity.Condition,
+		Status:       auctionEntity.Status,
+		Timestamp:    auctionEntity.Timestamp.UnixMilli(),
+		EndTime:      auctionEntity.EndTime.UnixMilli(),
+		CurrentPrice: auctionEntity.CurrentPrice,
+		BidCount:     auctionEntity.BidCount,
+		RelistedFrom: auctionEntity.RelistedFrom,
 	}
 
 	_, err := ar.Collection.InsertOne(ctx, auctionEntityMongo)
 	if err != nil {
-		logger.Error("Error trying to insert auction", err)
+		logger.ErrorContext(ctx, "Error trying to insert auction", err)
 		return internal_error.NewInternalServerError("Error trying to insert auction")
 	}
 
-	// Registra o leilão no mapa de leilões ativos com seu tempo de expiração
-	endTime := auctionEntity.Timestamp.Add(ar.auctionTimeout)
-
-	ar.auctionsMutex.Lock()
-	ar.activeAuctions[auctionEntity.Id] = endTime
-	ar.auctionsMutex.Unlock()
+	// Agenda o fechamento automático para o tempo de expiração do leilão; nas
+	// demais réplicas a líder encontra o leilão na próxima sincronização
+	endTime := auctionEntity.EndTime
+	ar.events.Publish(auctionstate.Event{
+		AuctionId: auctionEntity.Id,
+		Type:      auctionstate.Created,
+		Status:    auctionEntity.Status,
+		EndTime:   endTime,
+	})
+	ar.scheduleClose(auctionEntity.Id, endTime)
 
-	logger.Info(fmt.Sprintf("Auction %s created, will expire at %s", auctionEntity.Id, endTime.Format(time.RFC3339)))
+	logger.InfoContext(ctx, "Auction created", zap.Time("end_time", endTime))
 
 	return nil
 }
 
 // FindActiveAuctions retorna todos os leilões ativos (para testes)
 func (ar *AuctionRepository) FindActiveAuctions(ctx context.Context) ([]auction_entity.Auction, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "auction", "FindActiveAuctions")
+	defer done()
+
 	filter := bson.M{"status": auction_entity.Active}
 
 	cursor, err := ar.Collection.Find(ctx, filter)
 	if err != nil {
-		logger.Error("Error trying to find active auctions", err)
+		logger.ErrorContext(ctx, "Error trying to find active auctions", err)
 		return nil, internal_error.NewInternalServerError("Error trying to find active auctions")
 	}
 	defer cursor.Close(ctx)
@@ -227,20 +686,12 @@ func (ar *AuctionRepository) FindActiveAuctions(ctx context.Context) ([]auction_
 	var auctionsMongo []AuctionEntityMongo
 
 	if err := cursor.All(ctx, &auctionsMongo); err != nil {
-		logger.Error("Error trying to decode auctions", err)
+		logger.ErrorContext(ctx, "Error trying to decode auctions", err)
 		return nil, internal_error.NewInternalServerError("Error trying to decode auctions")
 	}
 
 	for _, a := range auctionsMongo {
-		auctions = append(auctions, auction_entity.Auction{
-			Id:          a.Id,
-			ProductName: a.ProductName,
-			Category:    a.Category,
-			Description: a.Description,
-			Condition:   a.Condition,
-			Status:      a.Status,
-			Timestamp:   time.Unix(a.Timestamp, 0),
-		})
+		auctions = append(auctions, ar.toAuctionEntity(a))
 	}
 
 	return auctions, nil
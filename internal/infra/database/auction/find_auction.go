@@ -4,16 +4,32 @@ import (
 	"context"
 	"fullcycle-auction_go/configuration/logger"
 	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/pagination_entity"
+	"fullcycle-auction_go/internal/infra/database/instrument"
+	"fullcycle-auction_go/internal/infra/database/pagination"
 	"fullcycle-auction_go/internal/internal_error"
+	"regexp"
+
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"time"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// auctionSortFields mapeia as ordenações expostas para os campos do documento
+var auctionSortFields = map[auction_entity.AuctionSortField]string{
+	auction_entity.SortByCreatedAt:    "timestamp",
+	auction_entity.SortByEndTime:      "end_time",
+	auction_entity.SortByCurrentPrice: "current_price",
+	auction_entity.SortByBidCount:     "bid_count",
+}
+
 // FindAuctionById busca um leilão pelo ID
 func (ar *AuctionRepository) FindAuctionById(
 	ctx context.Context, id string) (*auction_entity.Auction, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "auction", "FindAuctionById")
+	defer done()
+
 	filter := bson.M{"_id": id}
 
 	var auctionMongo AuctionEntityMongo
@@ -22,65 +38,148 @@ func (ar *AuctionRepository) FindAuctionById(
 		if err == mongo.ErrNoDocuments {
 			return nil, internal_error.NewNotFoundError("Auction not found")
 		}
-		logger.Error("Error finding auction by ID", err)
+		logger.ErrorContext(ctx, "Error finding auction by ID", err)
 		return nil, internal_error.NewInternalServerError("Error finding auction by ID")
 	}
 
-	return &auction_entity.Auction{
-		Id:          auctionMongo.Id,
-		ProductName: auctionMongo.ProductName,
-		Category:    auctionMongo.Category,
-		Description: auctionMongo.Description,
-		Condition:   auctionMongo.Condition,
-		Status:      auctionMongo.Status,
-		Timestamp:   time.Unix(auctionMongo.Timestamp, 0),
-	}, nil
+	auctionEntity := ar.toAuctionEntity(auctionMongo)
+	return &auctionEntity, nil
 }
 
 func (repo *AuctionRepository) FindAuctions(
 	ctx context.Context,
-	status auction_entity.AuctionStatus,
-	category string,
-	productName string) ([]auction_entity.Auction, *internal_error.InternalError) {
-	filter := bson.M{}
+	auctionFilter auction_entity.AuctionFilter,
+	page pagination_entity.PageRequest) (*auction_entity.AuctionPage, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "auction", "FindAuctions")
+	defer done()
 
-	if status != 0 {
-		filter["status"] = status
+	if page.SortBy == "" {
+		page.SortBy = string(auction_entity.SortByCreatedAt)
 	}
 
-	if category != "" {
-		filter["category"] = category
+	sortField, ok := auctionSortFields[auction_entity.AuctionSortField(page.SortBy)]
+	if !ok {
+		return nil, internal_error.NewBadRequestError("Invalid sort field")
 	}
 
-	if productName != "" {
-		filter["productName"] = primitive.Regex{Pattern: productName, Options: "i"}
+	cursorPosition, tokenErr := pagination.DecodeToken(page)
+	if tokenErr != nil {
+		return nil, tokenErr
 	}
 
-	cursor, err := repo.Collection.Find(ctx, filter)
+	filter := buildAuctionFilter(auctionFilter)
+	limit := page.EffectiveLimit()
+
+	// Busca um documento a mais para saber se existe próxima página
+	opts := options.Find().
+		SetSort(pagination.Sort(sortField, page.Descending)).
+		SetLimit(int64(limit + 1))
+
+	cursor, err := repo.Collection.Find(ctx, pagination.Apply(filter, sortField, cursorPosition), opts)
 	if err != nil {
-		logger.Error("Error finding auctions", err)
+		logger.ErrorContext(ctx, "Error finding auctions", err)
 		return nil, internal_error.NewInternalServerError("Error finding auctions")
 	}
 	defer cursor.Close(ctx)
 
 	var auctionsMongo []AuctionEntityMongo
 	if err := cursor.All(ctx, &auctionsMongo); err != nil {
-		logger.Error("Error decoding auctions", err)
+		logger.ErrorContext(ctx, "Error decoding auctions", err)
 		return nil, internal_error.NewInternalServerError("Error decoding auctions")
 	}
 
-	var auctionsEntity []auction_entity.Auction
-	for _, auction := range auctionsMongo {
-		auctionsEntity = append(auctionsEntity, auction_entity.Auction{
-			Id:          auction.Id,
-			ProductName: auction.ProductName,
-			Category:    auction.Category,
-			Status:      auction.Status,
-			Description: auction.Description,
-			Condition:   auction.Condition,
-			Timestamp:   time.Unix(auction.Timestamp, 0),
+	auctionPage := &auction_entity.AuctionPage{}
+	if len(auctionsMongo) > limit {
+		auctionsMongo = auctionsMongo[:limit]
+		last := auctionsMongo[limit-1]
+		auctionPage.NextToken = pagination.EncodeToken(pagination.Cursor{
+			SortBy:     page.SortBy,
+			Descending: page.Descending,
+			Value:      auctionSortValue(last, sortField),
+			Id:         last.Id,
 		})
 	}
 
-	return auctionsEntity, nil
+	for _, auction := range auctionsMongo {
+		auctionPage.Auctions = append(auctionPage.Auctions, repo.toAuctionEntity(auction))
+	}
+
+	if page.Token == "" {
+		total, err := repo.Collection.CountDocuments(ctx, filter)
+		if err != nil {
+			logger.ErrorContext(ctx, "Error counting auctions", err)
+			return nil, internal_error.NewInternalServerError("Error counting auctions")
+		}
+		auctionPage.Total = &total
+	}
+
+	return auctionPage, nil
+}
+
+func buildAuctionFilter(auctionFilter auction_entity.AuctionFilter) bson.M {
+	filter := bson.M{}
+
+	if auctionFilter.Status != 0 {
+		filter["status"] = auctionFilter.Status
+	}
+
+	if len(auctionFilter.Statuses) > 0 {
+		filter["status"] = bson.M{"$in": auctionFilter.Statuses}
+	}
+
+	if auctionFilter.Category != "" {
+		filter["category"] = auctionFilter.Category
+	}
+
+	if len(auctionFilter.CategoryIds) > 0 {
+		filter["category_id"] = bson.M{"$in": auctionFilter.CategoryIds}
+	}
+
+	if auctionFilter.ProductName != "" {
+		filter["product_name"] = primitive.Regex{
+			Pattern: regexp.QuoteMeta(auctionFilter.ProductName),
+			Options: "i",
+		}
+	}
+
+	if auctionFilter.Condition != 0 {
+		filter["condition"] = auctionFilter.Condition
+	}
+
+	priceRange := bson.M{}
+	if auctionFilter.MinPrice != nil {
+		priceRange["$gte"] = *auctionFilter.MinPrice
+	}
+	if auctionFilter.MaxPrice != nil {
+		priceRange["$lte"] = *auctionFilter.MaxPrice
+	}
+	if len(priceRange) > 0 {
+		filter["current_price"] = priceRange
+	}
+
+	endTimeRange := bson.M{}
+	if auctionFilter.EndsAfter != nil {
+		endTimeRange["$gte"] = auctionFilter.EndsAfter.UnixMilli()
+	}
+	if auctionFilter.EndsBefore != nil {
+		endTimeRange["$lte"] = auctionFilter.EndsBefore.UnixMilli()
+	}
+	if len(endTimeRange) > 0 {
+		filter["end_time"] = endTimeRange
+	}
+
+	return filter
+}
+
+func auctionSortValue(auctionMongo AuctionEntityMongo, sortField string) interface{} {
+	switch sortField {
+	case "end_time":
+		return auctionMongo.EndTime
+	case "current_price":
+		return auctionMongo.CurrentPrice
+	case "bid_count":
+		return auctionMongo.BidCount
+	default:
+		return auctionMongo.Timestamp
+	}
 }
@@ -0,0 +1,140 @@
+package auction
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+// AuctionRecovery resume o estado dos leilões encontrado na inicialização
+type AuctionRecovery struct {
+	// ClosedOverdue são os leilões que já tinham terminado e foram fechados agora
+	ClosedOverdue int `json:"closed_overdue"`
+	// Active são os leilões que continuam abertos
+	Active int64 `json:"active"`
+	// Leader informa se esta réplica ficou com o fechamento automático
+	Leader bool `json:"leader"`
+	// ScheduledCloses são os fechamentos agendados nesta réplica
+	ScheduledCloses int `json:"scheduled_closes"`
+}
+
+// RecoverAuctions fecha, antes de qualquer lance ser aceito, os leilões que
+// terminaram enquanto a aplicação estava parada, e espera o fechamento
+// automático ser reconstruído: a primeira disputa de liderança e, se esta
+// réplica venceu, a carga dos leilões ativos no scheduler. O fechamento é
+// condicional ao status, então várias réplicas podem executá-lo ao mesmo tempo.
+func (ar *AuctionRepository) RecoverAuctions(
+	ctx context.Context) (*AuctionRecovery, *internal_error.InternalError) {
+	now := time.Now()
+
+	filter := bson.M{"status": auction_entity.Active, "$or": endedBy(now)}
+
+	cursor, err := ar.Collection.Find(ctx, filter)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find overdue auctions", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find overdue auctions")
+	}
+	defer cursor.Close(ctx)
+
+	var auctionsMongo []AuctionEntityMongo
+	if err := cursor.All(ctx, &auctionsMongo); err != nil {
+		logger.ErrorContext(ctx, "Error trying to decode overdue auctions", err)
+		return nil, internal_error.NewInternalServerError("Error trying to decode overdue auctions")
+	}
+
+	recovery := &AuctionRecovery{}
+	for _, auctionMongo := range auctionsMongo {
+		if now.Before(ar.toAuctionEntity(auctionMongo).EndTime) {
+			continue
+		}
+
+		// Só fecha leilões já vencidos no banco, o mesmo que qualquer líder faria
+		closed, err := ar.closeAuction(ctx, auctionMongo.Id, now, 0)
+		if err != nil {
+			return nil, err
+		}
+		if closed {
+			recovery.ClosedOverdue++
+			logger.InfoContext(ctx, "Overdue auction closed during recovery", zap.String("auction_id", auctionMongo.Id))
+		}
+	}
+
+	active, countErr := ar.CountActiveAuctions(ctx)
+	if countErr != nil {
+		return nil, countErr
+	}
+	recovery.Active = active
+
+	if err := ar.waitForAuctionCloser(ctx); err != nil {
+		return nil, err
+	}
+
+	recovery.Leader = ar.elector.IsLeader()
+	if closeScheduler := ar.getCloseScheduler(); closeScheduler != nil {
+		recovery.ScheduledCloses = closeScheduler.Len()
+	}
+
+	return recovery, nil
+}
+
+// waitForAuctionCloser espera a primeira disputa de liderança e, se esta
+// réplica for a líder, a carga dos leilões ativos
+func (ar *AuctionRepository) waitForAuctionCloser(ctx context.Context) *internal_error.InternalError {
+	select {
+	case <-ar.elector.Attempted():
+	case <-ctx.Done():
+		return internal_error.NewInternalServerError("Timed out waiting for the auction closer election")
+	}
+
+	if !ar.elector.IsLeader() {
+		return nil
+	}
+
+	select {
+	case <-ar.closerLoaded:
+		return nil
+	case <-ctx.Done():
+		return internal_error.NewInternalServerError("Timed out waiting for active auctions to be scheduled")
+	}
+}
+
+// AuctionCloserStatus descreve o fechamento automático para a verificação de prontidão
+type AuctionCloserStatus struct {
+	Leader          bool      `json:"leader"`
+	LastHeartbeat   time.Time `json:"last_heartbeat"`
+	ScheduledCloses int       `json:"scheduled_closes"`
+}
+
+// CheckAuctionCloser verifica se a disputa de liderança do fechamento
+// automático continua executando, pelo último heartbeat do elector. Uma
+// réplica seguidora também precisa dele: é ela que assume se a líder cair.
+func (ar *AuctionRepository) CheckAuctionCloser(now time.Time) (*AuctionCloserStatus, *internal_error.InternalError) {
+	status := &AuctionCloserStatus{
+		Leader:        ar.elector.IsLeader(),
+		LastHeartbeat: ar.elector.Heartbeat(),
+	}
+	if closeScheduler := ar.getCloseScheduler(); closeScheduler != nil {
+		status.ScheduledCloses = closeScheduler.Len()
+	}
+
+	select {
+	case <-ar.closerDone:
+		return status, internal_error.NewServiceUnavailableError("Auction closer is stopped")
+	default:
+	}
+
+	if status.LastHeartbeat.IsZero() {
+		return status, internal_error.NewServiceUnavailableError("Auction closer has not started")
+	}
+	if age := now.Sub(status.LastHeartbeat); age > ar.elector.HeartbeatTimeout() {
+		return status, internal_error.NewServiceUnavailableError(
+			fmt.Sprintf("Auction closer heartbeat is %s old", age.Round(time.Millisecond)))
+	}
+	return status, nil
+}
@@ -0,0 +1,115 @@
+package auction
+
+import (
+	"context"
+	"errors"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/search_entity"
+	"fullcycle-auction_go/internal/infra/database/instrument"
+	"fullcycle-auction_go/internal/infra/search"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	textIndexName = "auction_text_search"
+	// indexNotFoundCode é retornado pelo MongoDB quando $text é usado sem índice textual
+	indexNotFoundCode = 27
+)
+
+type auctionSearchResultMongo struct {
+	AuctionEntityMongo `bson:",inline"`
+	Score              float64 `bson:"score"`
+}
+
+// ensureIndexes cria o índice textual usado pela busca e o índice por término
+// dos leilões ativos. O índice textual não tem idioma padrão, para que as
+// palavras sejam indexadas como escritas, igual à busca em memória.
+func (ar *AuctionRepository) ensureIndexes(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	textIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "product_name", Value: "text"},
+			{Key: "category", Value: "text"},
+			{Key: "description", Value: "text"},
+		},
+		Options: options.Index().
+			SetName(textIndexName).
+			SetDefaultLanguage("none").
+			SetWeights(bson.D{
+				{Key: "product_name", Value: auction_entity.ProductNameSearchWeight},
+				{Key: "category", Value: auction_entity.CategorySearchWeight},
+				{Key: "description", Value: auction_entity.DescriptionSearchWeight},
+			}),
+	}
+
+	if _, err := ar.Collection.Indexes().CreateOne(ctx, textIndex); err != nil {
+		logger.ErrorContext(ctx, "Error trying to create auction text index", err)
+	}
+
+	// Usado pela sincronização do fechamento automático
+	closerIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "end_time", Value: 1}},
+	}
+	if _, err := ar.Collection.Indexes().CreateOne(ctx, closerIndex); err != nil {
+		logger.ErrorContext(ctx, "Error trying to create auction end time index", err)
+	}
+}
+
+// SearchAuctions usa o índice textual do MongoDB e recorre à busca em memória
+// caso o índice ainda não exista
+func (ar *AuctionRepository) SearchAuctions(
+	ctx context.Context,
+	query *search_entity.Query,
+	auctionFilter auction_entity.AuctionFilter,
+	limit int) ([]auction_entity.AuctionSearchHit, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "auction", "SearchAuctions")
+	defer done()
+
+	filter := buildAuctionFilter(auctionFilter)
+	filter["$text"] = bson.M{"$search": query.MongoSearch()}
+
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := ar.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		var serverErr mongo.ServerError
+		if errors.As(err, &serverErr) && serverErr.HasErrorCode(indexNotFoundCode) {
+			logger.InfoContext(ctx, "Auction text index not available, falling back to in-memory search")
+			return search.NewMemoryAuctionSearch(ar).SearchAuctions(ctx, query, auctionFilter, limit)
+		}
+
+		logger.ErrorContext(ctx, "Error searching auctions", err)
+		return nil, internal_error.NewInternalServerError("Error searching auctions")
+	}
+	defer cursor.Close(ctx)
+
+	var results []auctionSearchResultMongo
+	if err := cursor.All(ctx, &results); err != nil {
+		logger.ErrorContext(ctx, "Error decoding auction search results", err)
+		return nil, internal_error.NewInternalServerError("Error decoding auction search results")
+	}
+
+	hits := make([]auction_entity.AuctionSearchHit, 0, len(results))
+	for _, result := range results {
+		auction := ar.toAuctionEntity(result.AuctionEntityMongo)
+		hits = append(hits, auction_entity.AuctionSearchHit{
+			Auction:    auction,
+			Score:      result.Score,
+			Highlights: query.Highlights(auction.SearchFields()),
+		})
+	}
+
+	return hits, nil
+}
@@ -0,0 +1,79 @@
+package auction
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/infra/auctionstate"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// changeStreamRetryDelay é a espera antes de reabrir o change stream
+const changeStreamRetryDelay = 10 * time.Second
+
+type auctionChangeEvent struct {
+	OperationType string `bson:"operationType"`
+	DocumentKey   struct {
+		Id string `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument *AuctionEntityMongo `bson:"fullDocument"`
+}
+
+// watchAuctionChanges publica no barramento as mudanças feitas na coleção de
+// leilões, inclusive por outras réplicas. Change streams exigem um replica
+// set; sem ele, o erro é registrado e o TTL do cache limita o atraso.
+func (ar *AuctionRepository) watchAuctionChanges(ctx context.Context) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"operationType": bson.M{"$in": bson.A{"insert", "update", "replace", "delete"}},
+		}}},
+	}
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+
+	for {
+		stream, err := ar.Collection.Watch(ctx, pipeline, opts)
+		if err != nil {
+			logger.ErrorContext(ctx, "Error trying to watch auction changes", err)
+		} else {
+			ar.publishAuctionChanges(ctx, stream)
+		}
+
+		select {
+		case <-time.After(changeStreamRetryDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (ar *AuctionRepository) publishAuctionChanges(ctx context.Context, stream *mongo.ChangeStream) {
+	defer stream.Close(context.Background())
+
+	for stream.Next(ctx) {
+		var change auctionChangeEvent
+		if err := stream.Decode(&change); err != nil {
+			logger.ErrorContext(ctx, "Error trying to decode auction change", err)
+			continue
+		}
+
+		if change.OperationType == "delete" || change.FullDocument == nil {
+			ar.events.Publish(auctionstate.Event{AuctionId: change.DocumentKey.Id, Type: auctionstate.Removed})
+			continue
+		}
+
+		auctionEntity := ar.toAuctionEntity(*change.FullDocument)
+		ar.events.Publish(auctionstate.Event{
+			AuctionId: auctionEntity.Id,
+			Type:      auctionstate.Changed,
+			Status:    auctionEntity.Status,
+			EndTime:   auctionEntity.EndTime,
+		})
+	}
+
+	if err := stream.Err(); err != nil && ctx.Err() == nil {
+		logger.ErrorContext(ctx, "Auction change stream interrupted", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fullcycle-auction_go/internal/entity/auction_entity"
 	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/pagination_entity"
 	"fullcycle-auction_go/internal/entity/user_entity"
 	"fullcycle-auction_go/internal/infra/database/auction"
 	"fullcycle-auction_go/internal/infra/database/user"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -53,7 +55,7 @@ func TestBidCreationAndRetrieval(t *testing.T) {
 
 	// Configurar os repositórios
 	userRepo := user.NewUserRepository(db)
-	auctionRepo := auction.NewAuctionRepository(db)
+	auctionRepo := auction.NewAuctionRepository(db, auction.AuctionRepositoryConfig{})
 	bidRepo := NewBidRepository(db, auctionRepo)
 
 	// 1. Criar um usuário
@@ -102,9 +104,9 @@ func TestBidCreationAndRetrieval(t *testing.T) {
 	assert.Nil(t, err, "Should create bids without errors")
 
 	// 4. Encontrar lances por ID do leilão
-	foundBids, err := bidRepo.FindBidByAuctionId(context.Background(), auctionId)
+	foundBids, err := bidRepo.FindBidByAuctionId(context.Background(), auctionId, pagination_entity.PageRequest{})
 	assert.Nil(t, err, "Should find bids without errors")
-	assert.Equal(t, 2, len(foundBids), "Should find 2 bids")
+	assert.Equal(t, 2, len(foundBids.Bids), "Should find 2 bids")
 
 	// 5. Verificar lance vencedor (maior valor)
 	winningBid, err := bidRepo.FindWinningBidByAuctionId(context.Background(), auctionId)
@@ -122,7 +124,7 @@ func TestBidRejectionForClosedAuction(t *testing.T) {
 
 	// Configurar os repositórios
 	userRepo := user.NewUserRepository(db)
-	auctionRepo := auction.NewAuctionRepository(db)
+	auctionRepo := auction.NewAuctionRepository(db, auction.AuctionRepositoryConfig{})
 	bidRepo := NewBidRepository(db, auctionRepo)
 
 	// 1. Criar um usuário
@@ -162,7 +164,329 @@ func TestBidRejectionForClosedAuction(t *testing.T) {
 	assert.Nil(t, err) // O método não retorna erro, apenas rejeita silenciosamente
 
 	// Verificar que o lance não foi registrado
-	foundBids, err := bidRepo.FindBidByAuctionId(context.Background(), auctionId)
+	foundBids, err := bidRepo.FindBidByAuctionId(context.Background(), auctionId, pagination_entity.PageRequest{})
 	assert.Nil(t, err)
-	assert.Equal(t, 0, len(foundBids), "No bids should be accepted for closed auction")
+	assert.Equal(t, 0, len(foundBids.Bids), "No bids should be accepted for closed auction")
+}
+
+func TestWinningBidTieGoesToEarliestBid(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDatabase(t)
+	defer cleanup()
+
+	auctionRepo := auction.NewAuctionRepository(db, auction.AuctionRepositoryConfig{})
+	defer auctionRepo.Cleanup()
+	bidRepo := NewBidRepository(db, auctionRepo)
+
+	auctionId := uuid.New().String()
+	err := auctionRepo.CreateAuction(context.Background(), &auction_entity.Auction{
+		Id:          auctionId,
+		ProductName: "Tie Test Product",
+		Category:    "Test Category",
+		Description: "Test Description is longer than 10 chars",
+		Condition:   auction_entity.New,
+		Status:      auction_entity.Active,
+		Timestamp:   time.Now(),
+	})
+	assert.Nil(t, err)
+
+	// Dois lances de mesmo valor no mesmo segundo, separados por milissegundos
+	now := time.Now()
+	first := bid_entity.Bid{
+		Id:        uuid.New().String(),
+		UserId:    uuid.New().String(),
+		AuctionId: auctionId,
+		Amount:    200.0,
+		Timestamp: now,
+	}
+	second := bid_entity.Bid{
+		Id:        uuid.New().String(),
+		UserId:    uuid.New().String(),
+		AuctionId: auctionId,
+		Amount:    200.0,
+		Timestamp: now.Add(5 * time.Millisecond),
+	}
+
+	err = bidRepo.CreateBid(context.Background(), []bid_entity.Bid{second, first})
+	assert.Nil(t, err)
+
+	foundBids, err := bidRepo.FindBidByAuctionId(context.Background(), auctionId, pagination_entity.PageRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(foundBids.Bids))
+	assert.Equal(t, first.Id, foundBids.Bids[0].Id)
+	assert.Equal(t, int64(1), foundBids.Bids[0].Sequence)
+	assert.Equal(t, int64(2), foundBids.Bids[1].Sequence)
+
+	winningBid, err := bidRepo.FindWinningBidByAuctionId(context.Background(), auctionId)
+	assert.Nil(t, err)
+	assert.Equal(t, first.Id, winningBid.Id, "Tie should go to the earliest bid")
+
+	// Um lance anterior gravado depois, como um lance enfileirado que chega
+	// após um envio em lote, recebe uma sequência maior mas ainda vence
+	earlier := bid_entity.Bid{
+		Id:        uuid.New().String(),
+		UserId:    uuid.New().String(),
+		AuctionId: auctionId,
+		Amount:    300.0,
+		Timestamp: now.Add(time.Millisecond),
+	}
+	later := earlier
+	later.Id = uuid.New().String()
+	later.UserId = uuid.New().String()
+	later.Timestamp = now.Add(10 * time.Millisecond)
+
+	assert.Nil(t, bidRepo.CreateBid(context.Background(), []bid_entity.Bid{later}))
+	assert.Nil(t, bidRepo.CreateBid(context.Background(), []bid_entity.Bid{earlier}))
+
+	winningBid, err = bidRepo.FindWinningBidByAuctionId(context.Background(), auctionId)
+	assert.Nil(t, err)
+	assert.Equal(t, earlier.Id, winningBid.Id, "Tie should go to the earliest bid, not the lowest sequence")
+}
+
+func TestGroupBidsByAuctionOrdersByTimestamp(t *testing.T) {
+	now := time.Now()
+	auctionId := uuid.New().String()
+	bids := []bid_entity.Bid{
+		{Id: "c", AuctionId: auctionId, Timestamp: now.Add(2 * time.Millisecond)},
+		{Id: "b", AuctionId: auctionId, Timestamp: now},
+		{Id: "a", AuctionId: auctionId, Timestamp: now},
+		{Id: "d", AuctionId: uuid.New().String(), Timestamp: now},
+	}
+
+	groups := groupBidsByAuction(bids)
+
+	assert.Equal(t, 2, len(groups))
+	ordered := groups[auctionId]
+	assert.Equal(t, []string{"a", "b", "c"}, []string{ordered[0].Id, ordered[1].Id, ordered[2].Id})
+}
+
+func TestCreateBidsWithResultsReportsRejections(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDatabase(t)
+	defer cleanup()
+
+	auctionRepo := auction.NewAuctionRepository(db, auction.AuctionRepositoryConfig{})
+	bidRepo := NewBidRepository(db, auctionRepo)
+
+	auctionId := uuid.New().String()
+	testAuction := &auction_entity.Auction{
+		Id:          auctionId,
+		ProductName: "Test Product",
+		Category:    "Test Category",
+		Description: "Test Description is longer than 10 chars",
+		Condition:   auction_entity.New,
+		Status:      auction_entity.Active,
+		Timestamp:   time.Now(),
+		EndTime:     time.Now().Add(time.Hour),
+	}
+	err := auctionRepo.CreateAuction(context.Background(), testAuction)
+	assert.Nil(t, err, "Should create auction without errors")
+
+	acceptedBid := bid_entity.Bid{
+		Id:        uuid.New().String(),
+		UserId:    uuid.New().String(),
+		AuctionId: auctionId,
+		Amount:    100.0,
+		Timestamp: time.Now(),
+	}
+	unknownAuctionBid := bid_entity.Bid{
+		Id:        uuid.New().String(),
+		UserId:    uuid.New().String(),
+		AuctionId: uuid.New().String(),
+		Amount:    100.0,
+		Timestamp: time.Now(),
+	}
+
+	rejections := bidRepo.CreateBidsWithResults(
+		context.Background(), []bid_entity.Bid{acceptedBid, unknownAuctionBid})
+	assert.Equal(t, []bid_entity.BidRejection{
+		{BidId: unknownAuctionBid.Id, Reason: bid_entity.RejectionAuctionNotFound},
+	}, rejections)
+
+	existing, err := bidRepo.FindExistingBidIds(
+		context.Background(), []string{acceptedBid.Id, unknownAuctionBid.Id})
+	assert.Nil(t, err)
+	assert.True(t, existing[acceptedBid.Id])
+	assert.False(t, existing[unknownAuctionBid.Id])
+}
+
+func TestStaleAuctionStateDoesNotAcceptBids(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDatabase(t)
+	defer cleanup()
+
+	auctionRepo := auction.NewAuctionRepository(db, auction.AuctionRepositoryConfig{DisableAutoClose: true})
+	bidRepo := NewBidRepository(db, auctionRepo)
+
+	auctionId := uuid.New().String()
+	err := auctionRepo.CreateAuction(context.Background(), &auction_entity.Auction{
+		Id:          auctionId,
+		ProductName: "Test Product",
+		Category:    "Test Category",
+		Description: "Test Description is longer than 10 chars",
+		Condition:   auction_entity.New,
+		Status:      auction_entity.Active,
+		Timestamp:   time.Now(),
+		EndTime:     time.Now().Add(time.Hour),
+	})
+	assert.Nil(t, err)
+
+	newBid := func(amount float64) bid_entity.Bid {
+		return bid_entity.Bid{
+			Id:        uuid.New().String(),
+			UserId:    uuid.New().String(),
+			AuctionId: auctionId,
+			Amount:    amount,
+			Timestamp: time.Now(),
+		}
+	}
+
+	// O primeiro lance deixa o leilão ativo no cache
+	assert.Empty(t, bidRepo.CreateBidsWithResults(context.Background(), []bid_entity.Bid{newBid(100)}))
+
+	// Encerramento antecipado feito por outra instância, sem aviso a este cache
+	_, updateErr := auctionRepo.Collection.UpdateOne(context.Background(), bson.M{"_id": auctionId},
+		bson.M{"$set": bson.M{"status": auction_entity.Completed, "end_time": time.Now().Add(-time.Second).UnixMilli()}})
+	assert.NoError(t, updateErr)
+
+	late := newBid(200)
+	assert.Equal(t, []bid_entity.BidRejection{{BidId: late.Id, Reason: bid_entity.RejectionAuctionClosed}},
+		bidRepo.CreateBidsWithResults(context.Background(), []bid_entity.Bid{late}))
+
+	auctionEntity, findErr := auctionRepo.FindAuctionById(context.Background(), auctionId)
+	assert.Nil(t, findErr)
+	assert.Equal(t, 100.0, auctionEntity.CurrentPrice)
+	assert.EqualValues(t, 1, auctionEntity.BidCount)
+}
+
+func TestRepeatedBidDoesNotCountTwice(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDatabase(t)
+	defer cleanup()
+
+	auctionRepo := auction.NewAuctionRepository(db, auction.AuctionRepositoryConfig{DisableAutoClose: true})
+	bidRepo := NewBidRepository(db, auctionRepo)
+
+	auctionId := uuid.New().String()
+	err := auctionRepo.CreateAuction(context.Background(), &auction_entity.Auction{
+		Id:          auctionId,
+		ProductName: "Test Product",
+		Category:    "Test Category",
+		Description: "Test Description is longer than 10 chars",
+		Condition:   auction_entity.New,
+		Status:      auction_entity.Active,
+		Timestamp:   time.Now(),
+		EndTime:     time.Now().Add(time.Hour),
+	})
+	assert.Nil(t, err)
+
+	first := bid_entity.Bid{
+		Id:        uuid.New().String(),
+		UserId:    uuid.New().String(),
+		AuctionId: auctionId,
+		Amount:    100,
+		Timestamp: time.Now(),
+	}
+	assert.Empty(t, bidRepo.CreateBidsWithResults(context.Background(), []bid_entity.Bid{first}))
+
+	// A releitura do write-ahead log pode reenviar um lance já gravado
+	replayed := first
+	replayed.Amount = 500
+	assert.Empty(t, bidRepo.CreateBidsWithResults(context.Background(), []bid_entity.Bid{replayed}))
+
+	auctionEntity, findErr := auctionRepo.FindAuctionById(context.Background(), auctionId)
+	assert.Nil(t, findErr)
+	assert.Equal(t, 100.0, auctionEntity.CurrentPrice)
+	assert.EqualValues(t, 1, auctionEntity.BidCount)
+}
+
+func TestBidsOnAuctionsWithoutStoredEndTime(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDatabase(t)
+	defer cleanup()
+
+	auctionRepo := auction.NewAuctionRepository(db, auction.AuctionRepositoryConfig{
+		AuctionDuration: time.Hour, DisableAutoClose: true})
+	bidRepo := NewBidRepository(db, auctionRepo)
+
+	// Documentos anteriores ao end_time, com o timestamp em milissegundos ou em segundos
+	now := time.Now()
+	openIds := []string{uuid.New().String(), uuid.New().String()}
+	endedId := uuid.New().String()
+	_, err := db.Collection("auctions").InsertMany(context.Background(), []interface{}{
+		bson.M{"_id": openIds[0], "status": auction_entity.Active, "timestamp": now.Add(-time.Minute).UnixMilli()},
+		bson.M{"_id": openIds[1], "status": auction_entity.Active, "timestamp": now.Add(-time.Minute).Unix()},
+		bson.M{"_id": endedId, "status": auction_entity.Active, "timestamp": now.Add(-2 * time.Hour).UnixMilli()},
+	})
+	assert.NoError(t, err)
+
+	var bids []bid_entity.Bid
+	for _, auctionId := range append(openIds, endedId) {
+		bids = append(bids, bid_entity.Bid{
+			Id:        uuid.New().String(),
+			UserId:    uuid.New().String(),
+			AuctionId: auctionId,
+			Amount:    100,
+			Timestamp: now,
+		})
+	}
+
+	assert.Equal(t, []bid_entity.BidRejection{{BidId: bids[2].Id, Reason: bid_entity.RejectionAuctionClosed}},
+		bidRepo.CreateBidsWithResults(context.Background(), bids))
+}
+
+func TestDelayedBidMadeBeforeEndIsAccepted(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db, cleanup := setupTestDatabase(t)
+	defer cleanup()
+
+	auctionRepo := auction.NewAuctionRepository(db, auction.AuctionRepositoryConfig{DisableAutoClose: true})
+	bidRepo := NewBidRepository(db, auctionRepo)
+
+	// O leilão já foi fechado quando a fila, ou o write-ahead log, grava os lances
+	now := time.Now()
+	auctionId := uuid.New().String()
+	err := auctionRepo.CreateAuction(context.Background(), &auction_entity.Auction{
+		Id:          auctionId,
+		ProductName: "Test Product",
+		Category:    "Test Category",
+		Description: "Test Description is longer than 10 chars",
+		Condition:   auction_entity.New,
+		Status:      auction_entity.Completed,
+		Timestamp:   now.Add(-time.Hour),
+		EndTime:     now.Add(-time.Minute),
+	})
+	assert.Nil(t, err)
+
+	newBid := func(madeAt time.Time) bid_entity.Bid {
+		return bid_entity.Bid{
+			Id:        uuid.New().String(),
+			UserId:    uuid.New().String(),
+			AuctionId: auctionId,
+			Amount:    100,
+			Timestamp: madeAt,
+		}
+	}
+	beforeEnd, afterEnd := newBid(now.Add(-2*time.Minute)), newBid(now.Add(-30*time.Second))
+
+	assert.Equal(t, []bid_entity.BidRejection{{BidId: afterEnd.Id, Reason: bid_entity.RejectionAuctionClosed}},
+		bidRepo.CreateBidsWithResults(context.Background(), []bid_entity.Bid{beforeEnd, afterEnd}))
 }
@@ -2,16 +2,20 @@ package bid
 
 import (
 	"context"
+	"fmt"
 	"fullcycle-auction_go/configuration/logger"
-	"fullcycle-auction_go/internal/entity/auction_entity"
 	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/infra/auctionstate"
 	"fullcycle-auction_go/internal/infra/database/auction"
+	"fullcycle-auction_go/internal/infra/database/instrument"
+	"fullcycle-auction_go/internal/infra/metrics"
 	"fullcycle-auction_go/internal/internal_error"
-	"os"
+	"sort"
 	"sync"
 	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
 )
 
 type BidEntityMongo struct {
@@ -19,103 +23,177 @@ type BidEntityMongo struct {
 	UserId    string  `bson:"user_id"`
 	AuctionId string  `bson:"auction_id"`
 	Amount    float64 `bson:"amount"`
-	Timestamp int64   `bson:"timestamp"`
+	Timestamp int64   `bson:"timestamp"` // milissegundos desde a época Unix
+	Sequence  int64   `bson:"sequence"`
+	// Voided e VoidReason só são gravados quando um administrador anula o lance
+	Voided     bool   `bson:"voided,omitempty"`
+	VoidReason string `bson:"void_reason,omitempty"`
+	VoidedAt   int64  `bson:"voided_at,omitempty"`
 }
 
+// BidRepository consulta o estado dos leilões no cache compartilhado do
+// AuctionRepository, que é atualizado a cada fechamento, prorrogação ou
+// cancelamento
 type BidRepository struct {
-	Collection            *mongo.Collection
-	AuctionRepository     *auction.AuctionRepository
-	auctionInterval       time.Duration
-	auctionStatusMap      map[string]auction_entity.AuctionStatus
-	auctionEndTimeMap     map[string]time.Time
-	auctionStatusMapMutex *sync.Mutex
-	auctionEndTimeMutex   *sync.Mutex
+	Collection        *mongo.Collection
+	AuctionRepository *auction.AuctionRepository
 }
 
 func NewBidRepository(database *mongo.Database, auctionRepository *auction.AuctionRepository) *BidRepository {
 	return &BidRepository{
-		auctionInterval:       getAuctionInterval(),
-		auctionStatusMap:      make(map[string]auction_entity.AuctionStatus),
-		auctionEndTimeMap:     make(map[string]time.Time),
-		auctionStatusMapMutex: &sync.Mutex{},
-		auctionEndTimeMutex:   &sync.Mutex{},
-		Collection:            database.Collection("bids"),
-		AuctionRepository:     auctionRepository,
+		Collection:        database.Collection("bids"),
+		AuctionRepository: auctionRepository,
 	}
 }
 
+// CreateBid processa os lances agrupados por leilão. Lances recusados por
+// regra do leilão não são erro; falhas de gravação são reportadas para que o
+// lote possa ser reprocessado.
 func (bd *BidRepository) CreateBid(
 	ctx context.Context,
 	bidEntities []bid_entity.Bid) *internal_error.InternalError {
+	failed := 0
+	for _, rejection := range bd.CreateBidsWithResults(ctx, bidEntities) {
+		if rejection.Reason == bid_entity.RejectionStorageError {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return internal_error.NewInternalServerError(
+			fmt.Sprintf("Error trying to insert %d of %d bids", failed, len(bidEntities)))
+	}
+	return nil
+}
+
+// CreateBidsWithResults processa os lances agrupados por leilão. Leilões
+// diferentes são processados em paralelo, mas os lances de um mesmo leilão
+// são aceitos em ordem de timestamp para que a sequência atribuída reflita a
+// ordem de chegada.
+func (bd *BidRepository) CreateBidsWithResults(
+	ctx context.Context,
+	bidEntities []bid_entity.Bid) []bid_entity.BidRejection {
+	ctx, done := instrument.Operation(ctx, "bid", "CreateBidsWithResults")
+	defer done()
+
 	var wg sync.WaitGroup
-	for _, bid := range bidEntities {
+	var mutex sync.Mutex
+	var rejections []bid_entity.BidRejection
+	for _, auctionBids := range groupBidsByAuction(bidEntities) {
 		wg.Add(1)
-		go func(bidValue bid_entity.Bid) {
+		go func(bids []bid_entity.Bid) {
 			defer wg.Done()
 
-			bd.auctionStatusMapMutex.Lock()
-			auctionStatus, okStatus := bd.auctionStatusMap[bidValue.AuctionId]
-			bd.auctionStatusMapMutex.Unlock()
+			for _, bidValue := range bids {
+				if reason := bd.acceptBid(ctx, bidValue); reason != "" {
+					mutex.Lock()
+					rejections = append(rejections, bid_entity.BidRejection{BidId: bidValue.Id, Reason: reason})
+					mutex.Unlock()
+				}
+			}
+		}(auctionBids)
+	}
+	wg.Wait()
+
+	metrics.BidsAccepted(len(bidEntities) - len(rejections))
+	for _, rejection := range rejections {
+		metrics.BidRejected(rejection.Reason)
+	}
 
-			bd.auctionEndTimeMutex.Lock()
-			auctionEndTime, okEndTime := bd.auctionEndTimeMap[bidValue.AuctionId]
-			bd.auctionEndTimeMutex.Unlock()
+	return rejections
+}
 
-			bidEntityMongo := &BidEntityMongo{
-				Id:        bidValue.Id,
-				UserId:    bidValue.UserId,
-				AuctionId: bidValue.AuctionId,
-				Amount:    bidValue.Amount,
-				Timestamp: bidValue.Timestamp.Unix(),
+// acceptBid verifica se o leilão ainda aceita lances e, em caso positivo,
+// atribui o número de sequência e persiste o lance. Retorna o motivo da
+// recusa, ou vazio se o lance foi gravado.
+func (bd *BidRepository) acceptBid(ctx context.Context, bidValue bid_entity.Bid) string {
+	states := bd.AuctionRepository.AuctionStates()
+
+	now := time.Now()
+	state, version, ok := states.Get(bidValue.AuctionId, now)
+	if !ok {
+		auctionEntity, err := bd.AuctionRepository.FindAuctionById(ctx, bidValue.AuctionId)
+		if err != nil {
+			logger.ErrorContext(ctx, "Error trying to find auction by id", err)
+			if err.Err == "not_found" {
+				return bid_entity.RejectionAuctionNotFound
 			}
+			return bid_entity.RejectionStorageError
+		}
 
-			if okEndTime && okStatus {
-				now := time.Now()
-				if auctionStatus == auction_entity.Completed || now.After(auctionEndTime) {
-					return
-				}
+		state = auctionstate.State{Status: auctionEntity.Status, EndTime: auctionEntity.EndTime}
+		states.Store(bidValue.AuctionId, state, version, now)
+	}
 
-				if _, err := bd.Collection.InsertOne(ctx, bidEntityMongo); err != nil {
-					logger.Error("Error trying to insert bid", err)
-					return
-				}
+	// O lance vale pelo horário em que foi feito, não pelo da gravação: um
+	// lance que esperou na fila ou no log além do término continua valendo,
+	// e um feito depois do término é recusado mesmo antes do fechamento
+	if !state.AcceptsBids(bidValue.Timestamp) {
+		return bid_entity.RejectionAuctionClosed
+	}
 
-				return
-			}
+	return bd.insertBid(ctx, bidValue)
+}
 
-			auctionEntity, err := bd.AuctionRepository.FindAuctionById(ctx, bidValue.AuctionId)
-			if err != nil {
-				logger.Error("Error trying to find auction by id", err)
-				return
-			}
-			if auctionEntity.Status == auction_entity.Completed {
-				return
-			}
+func (bd *BidRepository) insertBid(ctx context.Context, bidValue bid_entity.Bid) string {
+	registration, err := bd.AuctionRepository.RegisterAcceptedBid(
+		ctx, bidValue.AuctionId, bidValue.Amount, bidValue.Timestamp)
+	if err != nil {
+		// O leilão terminou antes do lance ou foi cancelado depois da consulta ao cache
+		if err.Err == "bad_request" {
+			return bid_entity.RejectionAuctionClosed
+		}
+		logger.ErrorContext(ctx, "Error trying to assign sequence to bid", err, zap.String("bid_id", bidValue.Id))
+		return bid_entity.RejectionStorageError
+	}
 
-			bd.auctionStatusMapMutex.Lock()
-			bd.auctionStatusMap[bidValue.AuctionId] = auctionEntity.Status
-			bd.auctionStatusMapMutex.Unlock()
+	bidEntityMongo := &BidEntityMongo{
+		Id:        bidValue.Id,
+		UserId:    bidValue.UserId,
+		AuctionId: bidValue.AuctionId,
+		Amount:    bidValue.Amount,
+		Timestamp: bidValue.Timestamp.UnixMilli(),
+		Sequence:  registration.Sequence,
+	}
 
-			bd.auctionEndTimeMutex.Lock()
-			bd.auctionEndTimeMap[bidValue.AuctionId] = auctionEntity.Timestamp.Add(bd.auctionInterval)
-			bd.auctionEndTimeMutex.Unlock()
+	_, insertErr := bd.Collection.InsertOne(ctx, bidEntityMongo)
+	if insertErr == nil {
+		return ""
+	}
 
-			if _, err := bd.Collection.InsertOne(ctx, bidEntityMongo); err != nil {
-				logger.Error("Error trying to insert bid", err)
-				return
-			}
-		}(bid)
+	// O leilão já contou o lance; sem o documento, a contagem e o preço voltam
+	// ao que eram
+	if undoErr := bd.AuctionRepository.UndoBidRegistration(
+		ctx, bidValue.AuctionId, *registration, bidValue.Amount); undoErr != nil {
+		logger.ErrorContext(ctx, "Error trying to undo bid registration", undoErr, zap.String("bid_id", bidValue.Id))
 	}
-	wg.Wait()
-	return nil
+
+	// Um lance repetido, como na releitura do write-ahead log, já está gravado
+	if mongo.IsDuplicateKeyError(insertErr) {
+		logger.WarnContext(ctx, "Bid was already stored", zap.String("bid_id", bidValue.Id))
+		return ""
+	}
+
+	logger.ErrorContext(ctx, "Error trying to insert bid", insertErr)
+	return bid_entity.RejectionStorageError
 }
 
-func getAuctionInterval() time.Duration {
-	auctionInterval := os.Getenv("AUCTION_INTERVAL")
-	duration, err := time.ParseDuration(auctionInterval)
-	if err != nil {
-		return time.Minute * 5
+// groupBidsByAuction separa o lote por leilão, ordenando cada grupo pelo
+// timestamp do lance (e pelo id, para empates exatos)
+func groupBidsByAuction(bidEntities []bid_entity.Bid) map[string][]bid_entity.Bid {
+	groups := make(map[string][]bid_entity.Bid)
+	for _, bid := range bidEntities {
+		groups[bid.AuctionId] = append(groups[bid.AuctionId], bid)
+	}
+
+	for _, bids := range groups {
+		sort.SliceStable(bids, func(i, j int) bool {
+			if bids[i].Timestamp.Equal(bids[j].Timestamp) {
+				return bids[i].Id < bids[j].Id
+			}
+			return bids[i].Timestamp.Before(bids[j].Timestamp)
+		})
 	}
 
-	return duration
+	return groups
 }
@@ -5,62 +5,167 @@ import (
 	"fmt"
 	"fullcycle-auction_go/configuration/logger"
 	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/pagination_entity"
+	"fullcycle-auction_go/internal/infra/database/instrument"
+	"fullcycle-auction_go/internal/infra/database/pagination"
+	"fullcycle-auction_go/internal/infra/database/storedtime"
 	"fullcycle-auction_go/internal/internal_error"
+
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo/options"
-	"time"
 )
 
+// bidSortFields mapeia as ordenações expostas para os campos do documento
+var bidSortFields = map[bid_entity.BidSortField]string{
+	bid_entity.SortBySequence: "sequence",
+	bid_entity.SortByAmount:   "amount",
+}
+
 func (bd *BidRepository) FindBidByAuctionId(
-	ctx context.Context, auctionId string) ([]bid_entity.Bid, *internal_error.InternalError) {
+	ctx context.Context,
+	auctionId string,
+	page pagination_entity.PageRequest) (*bid_entity.BidPage, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "bid", "FindBidByAuctionId")
+	defer done()
+
+	if page.SortBy == "" {
+		page.SortBy = string(bid_entity.SortBySequence)
+	}
+
+	sortField, ok := bidSortFields[bid_entity.BidSortField(page.SortBy)]
+	if !ok {
+		return nil, internal_error.NewBadRequestError("Invalid sort field")
+	}
+
+	cursorPosition, tokenErr := pagination.DecodeToken(page)
+	if tokenErr != nil {
+		return nil, tokenErr
+	}
+
 	filter := bson.M{"auction_id": auctionId}
+	limit := page.EffectiveLimit()
 
-	cursor, err := bd.Collection.Find(ctx, filter)
+	// Busca um documento a mais para saber se existe próxima página
+	opts := options.Find().
+		SetSort(pagination.Sort(sortField, page.Descending)).
+		SetLimit(int64(limit + 1))
+
+	cursor, err := bd.Collection.Find(ctx, pagination.Apply(filter, sortField, cursorPosition), opts)
 	if err != nil {
-		logger.Error(
-			fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId), err)
+		logger.ErrorContext(ctx, "Error trying to find bids", err)
 		return nil, internal_error.NewInternalServerError(
 			fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId))
 	}
+	defer cursor.Close(ctx)
 
 	var bidEntitiesMongo []BidEntityMongo
 	if err := cursor.All(ctx, &bidEntitiesMongo); err != nil {
-		logger.Error(
-			fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId), err)
+		logger.ErrorContext(ctx, "Error trying to find bids", err)
 		return nil, internal_error.NewInternalServerError(
 			fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId))
 	}
 
-	var bidEntities []bid_entity.Bid
-	for _, bidEntityMongo := range bidEntitiesMongo {
-		bidEntities = append(bidEntities, bid_entity.Bid{
-			Id:        bidEntityMongo.Id,
-			UserId:    bidEntityMongo.UserId,
-			AuctionId: bidEntityMongo.AuctionId,
-			Amount:    bidEntityMongo.Amount,
-			Timestamp: time.Unix(bidEntityMongo.Timestamp, 0),
+	bidPage := &bid_entity.BidPage{}
+	if len(bidEntitiesMongo) > limit {
+		bidEntitiesMongo = bidEntitiesMongo[:limit]
+		last := bidEntitiesMongo[limit-1]
+
+		var value interface{} = last.Sequence
+		if sortField == "amount" {
+			value = last.Amount
+		}
+
+		bidPage.NextToken = pagination.EncodeToken(pagination.Cursor{
+			SortBy:     page.SortBy,
+			Descending: page.Descending,
+			Value:      value,
+			Id:         last.Id,
 		})
 	}
 
-	return bidEntities, nil
+	for _, bidEntityMongo := range bidEntitiesMongo {
+		bidPage.Bids = append(bidPage.Bids, toBidEntity(bidEntityMongo))
+	}
+
+	if page.Token == "" {
+		total, err := bd.Collection.CountDocuments(ctx, filter)
+		if err != nil {
+			logger.ErrorContext(ctx, "Error trying to count bids", err)
+			return nil, internal_error.NewInternalServerError(
+				fmt.Sprintf("Error trying to count bids by auctionId %s", auctionId))
+		}
+		bidPage.Total = &total
+	}
+
+	return bidPage, nil
 }
 
 func (bd *BidRepository) FindWinningBidByAuctionId(
 	ctx context.Context, auctionId string) (*bid_entity.Bid, *internal_error.InternalError) {
-	filter := bson.M{"auction_id": auctionId}
+	ctx, done := instrument.Operation(ctx, "bid", "FindWinningBidByAuctionId")
+	defer done()
+
+	filter := bson.M{"auction_id": auctionId, "voided": bson.M{"$ne": true}}
 
+	// Empates no valor são decididos pelo lance feito primeiro. O timestamp
+	// vem antes da sequência porque os envios em lote não passam pela fila e
+	// podem receber uma sequência menor que a de um lance anterior ainda
+	// enfileirado; a sequência só desempata lances do mesmo milissegundo
 	var bidEntityMongo BidEntityMongo
-	opts := options.FindOne().SetSort(bson.D{{"amount", -1}})
+	opts := options.FindOne().SetSort(bson.D{
+		{Key: "amount", Value: -1},
+		{Key: "timestamp", Value: 1},
+		{Key: "sequence", Value: 1},
+		{Key: "_id", Value: 1},
+	})
 	if err := bd.Collection.FindOne(ctx, filter, opts).Decode(&bidEntityMongo); err != nil {
-		logger.Error("Error trying to find the auction winner", err)
+		logger.ErrorContext(ctx, "Error trying to find the auction winner", err)
 		return nil, internal_error.NewInternalServerError("Error trying to find the auction winner")
 	}
 
-	return &bid_entity.Bid{
-		Id:        bidEntityMongo.Id,
-		UserId:    bidEntityMongo.UserId,
-		AuctionId: bidEntityMongo.AuctionId,
-		Amount:    bidEntityMongo.Amount,
-		Timestamp: time.Unix(bidEntityMongo.Timestamp, 0),
-	}, nil
+	bidEntity := toBidEntity(bidEntityMongo)
+	return &bidEntity, nil
+}
+
+func toBidEntity(bidEntityMongo BidEntityMongo) bid_entity.Bid {
+	return bid_entity.Bid{
+		Id:         bidEntityMongo.Id,
+		UserId:     bidEntityMongo.UserId,
+		AuctionId:  bidEntityMongo.AuctionId,
+		Amount:     bidEntityMongo.Amount,
+		Timestamp:  storedtime.ToTime(bidEntityMongo.Timestamp),
+		Sequence:   bidEntityMongo.Sequence,
+		Voided:     bidEntityMongo.Voided,
+		VoidReason: bidEntityMongo.VoidReason,
+	}
+}
+
+func (bd *BidRepository) FindExistingBidIds(
+	ctx context.Context, bidIds []string) (map[string]bool, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "bid", "FindExistingBidIds")
+	defer done()
+
+	existing := make(map[string]bool)
+	if len(bidIds) == 0 {
+		return existing, nil
+	}
+
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+	cursor, err := bd.Collection.Find(ctx, bson.M{"_id": bson.M{"$in": bidIds}}, opts)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find existing bids", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find existing bids")
+	}
+	defer cursor.Close(ctx)
+
+	var bidEntitiesMongo []BidEntityMongo
+	if err := cursor.All(ctx, &bidEntitiesMongo); err != nil {
+		logger.ErrorContext(ctx, "Error trying to find existing bids", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find existing bids")
+	}
+
+	for _, bidEntityMongo := range bidEntitiesMongo {
+		existing[bidEntityMongo.Id] = true
+	}
+	return existing, nil
 }
@@ -0,0 +1,70 @@
+package bid
+
+import (
+	"context"
+	"errors"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/infra/database/instrument"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+func (bd *BidRepository) VoidBid(
+	ctx context.Context, bidId, reason string) (*bid_entity.Bid, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "bid", "VoidBid")
+	defer done()
+
+	filter := bson.M{"_id": bidId, "voided": bson.M{"$ne": true}}
+	update := bson.M{"$set": bson.M{
+		"voided":      true,
+		"void_reason": reason,
+		"voided_at":   time.Now().UnixMilli(),
+	}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var bidEntityMongo BidEntityMongo
+	if err := bd.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&bidEntityMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError("Bid not found or already voided")
+		}
+		logger.ErrorContext(ctx, "Error trying to void bid", err, zap.String("bid_id", bidId))
+		return nil, internal_error.NewInternalServerError("Error trying to void bid")
+	}
+
+	if err := bd.recalculateCurrentPrice(ctx, bidEntityMongo); err != nil {
+		return nil, err
+	}
+
+	bidEntity := toBidEntity(bidEntityMongo)
+	return &bidEntity, nil
+}
+
+// recalculateCurrentPrice volta o preço corrente do leilão para o maior lance
+// válido. O preço só é alterado se ainda for o valor do lance anulado; se um
+// lance maior chegou nesse meio tempo, o preço já está correto.
+func (bd *BidRepository) recalculateCurrentPrice(
+	ctx context.Context, voidedBid BidEntityMongo) *internal_error.InternalError {
+	filter := bson.M{"auction_id": voidedBid.AuctionId, "voided": bson.M{"$ne": true}}
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: "amount", Value: -1}}).
+		SetProjection(bson.M{"amount": 1})
+
+	var highest float64
+	var bidEntityMongo BidEntityMongo
+	err := bd.Collection.FindOne(ctx, filter, opts).Decode(&bidEntityMongo)
+	switch {
+	case err == nil:
+		highest = bidEntityMongo.Amount
+	case !errors.Is(err, mongo.ErrNoDocuments):
+		logger.ErrorContext(ctx, "Error trying to find highest bid", err)
+		return internal_error.NewInternalServerError("Error trying to recalculate auction price")
+	}
+
+	return bd.AuctionRepository.ResetCurrentPrice(ctx, voidedBid.AuctionId, voidedBid.Amount, highest)
+}
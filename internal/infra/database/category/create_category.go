@@ -0,0 +1,170 @@
+package category
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/category_entity"
+	"fullcycle-auction_go/internal/infra/database/instrument"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+type CategoryEntityMongo struct {
+	Id                string                            `bson:"_id"`
+	Name              string                            `bson:"name"`
+	Slug              string                            `bson:"slug"`
+	ParentId          string                            `bson:"parent_id,omitempty"`
+	Ancestors         []string                          `bson:"ancestors"`
+	AllowedConditions []auction_entity.ProductCondition `bson:"allowed_conditions"`
+	DefaultDuration   int64                             `bson:"default_duration_ms"`
+}
+
+type CategoryRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewCategoryRepository(database *mongo.Database) *CategoryRepository {
+	repo := &CategoryRepository{
+		Collection: database.Collection("categories"),
+	}
+
+	// Cria os índices de slug único e de ancestrais
+	go repo.ensureIndexes(context.Background())
+
+	return repo
+}
+
+func (cr *CategoryRepository) ensureIndexes(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	_, err := cr.Collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "slug", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "ancestors", Value: 1}},
+		},
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to create category indexes", err)
+	}
+}
+
+func (cr *CategoryRepository) CreateCategory(
+	ctx context.Context, category *category_entity.Category) *internal_error.InternalError {
+	ctx, done := instrument.Operation(ctx, "category", "CreateCategory")
+	defer done()
+
+	if _, err := cr.Collection.InsertOne(ctx, toCategoryEntityMongo(category)); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return internal_error.NewBadRequestError(
+				fmt.Sprintf("Category slug %s is already in use", category.Slug))
+		}
+		logger.ErrorContext(ctx, "Error trying to insert category", err)
+		return internal_error.NewInternalServerError("Error trying to insert category")
+	}
+
+	return nil
+}
+
+func (cr *CategoryRepository) UpdateCategory(
+	ctx context.Context, category *category_entity.Category) *internal_error.InternalError {
+	ctx, done := instrument.Operation(ctx, "category", "UpdateCategory")
+	defer done()
+
+	current, err := cr.FindCategoryById(ctx, category.Id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := cr.Collection.ReplaceOne(
+		ctx, bson.M{"_id": category.Id}, toCategoryEntityMongo(category)); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return internal_error.NewBadRequestError(
+				fmt.Sprintf("Category slug %s is already in use", category.Slug))
+		}
+		logger.ErrorContext(ctx, "Error trying to update category", err, zap.String("category_id", category.Id))
+		return internal_error.NewInternalServerError("Error trying to update category")
+	}
+
+	if current.ParentId == category.ParentId {
+		return nil
+	}
+
+	return cr.moveDescendants(ctx, category)
+}
+
+// moveDescendants reescreve os ancestrais dos descendentes de uma categoria que
+// mudou de pai, preservando o caminho abaixo dela
+func (cr *CategoryRepository) moveDescendants(
+	ctx context.Context, category *category_entity.Category) *internal_error.InternalError {
+	descendants, err := cr.FindDescendants(ctx, category.Id)
+	if err != nil {
+		return err
+	}
+
+	if len(descendants) == 0 {
+		return nil
+	}
+
+	var writes []mongo.WriteModel
+	for _, descendant := range descendants {
+		ancestors := append(append([]string{}, category.Ancestors...), category.Id)
+		for i, ancestor := range descendant.Ancestors {
+			if ancestor == category.Id {
+				ancestors = append(ancestors, descendant.Ancestors[i+1:]...)
+				break
+			}
+		}
+
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": descendant.Id}).
+			SetUpdate(bson.M{"$set": bson.M{"ancestors": ancestors}}))
+	}
+
+	if _, err := cr.Collection.BulkWrite(ctx, writes); err != nil {
+		logger.ErrorContext(ctx, "Error trying to move category descendants", err, zap.String("category_id", category.Id))
+		return internal_error.NewInternalServerError("Error trying to move category descendants")
+	}
+
+	return nil
+}
+
+func (cr *CategoryRepository) DeleteCategory(
+	ctx context.Context, id string) *internal_error.InternalError {
+	ctx, done := instrument.Operation(ctx, "category", "DeleteCategory")
+	defer done()
+
+	result, err := cr.Collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to delete category", err, zap.String("category_id", id))
+		return internal_error.NewInternalServerError("Error trying to delete category")
+	}
+
+	if result.DeletedCount == 0 {
+		return internal_error.NewNotFoundError("Category not found")
+	}
+
+	return nil
+}
+
+func toCategoryEntityMongo(category *category_entity.Category) *CategoryEntityMongo {
+	return &CategoryEntityMongo{
+		Id:                category.Id,
+		Name:              category.Name,
+		Slug:              category.Slug,
+		ParentId:          category.ParentId,
+		Ancestors:         category.Ancestors,
+		AllowedConditions: category.AllowedConditions,
+		DefaultDuration:   category.DefaultDuration.Milliseconds(),
+	}
+}
@@ -0,0 +1,88 @@
+package category
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/category_entity"
+	"fullcycle-auction_go/internal/infra/database/instrument"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (cr *CategoryRepository) FindCategoryById(
+	ctx context.Context, id string) (*category_entity.Category, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "category", "FindCategoryById")
+	defer done()
+
+	var categoryMongo CategoryEntityMongo
+	if err := cr.Collection.FindOne(ctx, bson.M{"_id": id}).Decode(&categoryMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError(
+				fmt.Sprintf("Category not found with this id = %s", id))
+		}
+		logger.ErrorContext(ctx, "Error trying to find category by id", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find category by id")
+	}
+
+	category := toCategoryEntity(categoryMongo)
+	return &category, nil
+}
+
+func (cr *CategoryRepository) FindCategories(
+	ctx context.Context) ([]category_entity.Category, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "category", "FindCategories")
+	defer done()
+
+	return cr.findMany(ctx, bson.M{})
+}
+
+func (cr *CategoryRepository) FindDescendants(
+	ctx context.Context, id string) ([]category_entity.Category, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "category", "FindDescendants")
+	defer done()
+
+	return cr.findMany(ctx, bson.M{"ancestors": id})
+}
+
+func (cr *CategoryRepository) findMany(
+	ctx context.Context, filter bson.M) ([]category_entity.Category, *internal_error.InternalError) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := cr.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find categories", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find categories")
+	}
+	defer cursor.Close(ctx)
+
+	var categoriesMongo []CategoryEntityMongo
+	if err := cursor.All(ctx, &categoriesMongo); err != nil {
+		logger.ErrorContext(ctx, "Error trying to decode categories", err)
+		return nil, internal_error.NewInternalServerError("Error trying to decode categories")
+	}
+
+	categories := make([]category_entity.Category, 0, len(categoriesMongo))
+	for _, categoryMongo := range categoriesMongo {
+		categories = append(categories, toCategoryEntity(categoryMongo))
+	}
+
+	return categories, nil
+}
+
+func toCategoryEntity(categoryMongo CategoryEntityMongo) category_entity.Category {
+	return category_entity.Category{
+		Id:                categoryMongo.Id,
+		Name:              categoryMongo.Name,
+		Slug:              categoryMongo.Slug,
+		ParentId:          categoryMongo.ParentId,
+		Ancestors:         categoryMongo.Ancestors,
+		AllowedConditions: categoryMongo.AllowedConditions,
+		DefaultDuration:   time.Duration(categoryMongo.DefaultDuration) * time.Millisecond,
+	}
+}
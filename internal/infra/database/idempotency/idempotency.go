@@ -0,0 +1,165 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/idempotency_entity"
+	"fullcycle-auction_go/internal/infra/database/instrument"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxReserveAttempts limita as tentativas quando um registro expirado é
+// removido ou recriado por outra instância durante a reserva
+const maxReserveAttempts = 3
+
+type IdempotencyRecordMongo struct {
+	Key         string `bson:"_id"`
+	Fingerprint string `bson:"fingerprint"`
+	Completed   bool   `bson:"completed"`
+	StatusCode  int    `bson:"status_code,omitempty"`
+	ContentType string `bson:"content_type,omitempty"`
+	Body        []byte `bson:"body,omitempty"`
+	CreatedAt   int64  `bson:"created_at"` // milissegundos desde a época Unix
+	// ExpiresAt alimenta o índice TTL que descarta os registros vencidos
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+type IdempotencyRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewIdempotencyRepository(database *mongo.Database) *IdempotencyRepository {
+	repo := &IdempotencyRepository{
+		Collection: database.Collection("idempotency_keys"),
+	}
+
+	// Cria o índice TTL dos registros
+	go repo.ensureIndexes(context.Background())
+
+	return repo
+}
+
+func (ir *IdempotencyRepository) ensureIndexes(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	_, err := ir.Collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to create idempotency indexes", err)
+	}
+}
+
+// Reserve usa a chave primária para garantir que só uma requisição fique com
+// a chave. O índice TTL pode levar até um minuto para remover um registro
+// vencido, então registros expirados são removidos aqui antes de tentar de novo.
+func (ir *IdempotencyRepository) Reserve(
+	ctx context.Context,
+	record *idempotency_entity.Record,
+	now time.Time) (*idempotency_entity.Record, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "idempotency", "Reserve")
+	defer done()
+
+	for attempt := 0; attempt < maxReserveAttempts; attempt++ {
+		_, err := ir.Collection.InsertOne(ctx, toIdempotencyRecordMongo(record))
+		if err == nil {
+			return nil, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			logger.ErrorContext(ctx, "Error trying to reserve idempotency key", err)
+			return nil, internal_error.NewInternalServerError("Error trying to reserve idempotency key")
+		}
+
+		var stored IdempotencyRecordMongo
+		err = ir.Collection.FindOne(ctx, bson.M{"_id": record.Key}).Decode(&stored)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue
+		}
+		if err != nil {
+			logger.ErrorContext(ctx, "Error trying to find idempotency key", err)
+			return nil, internal_error.NewInternalServerError("Error trying to find idempotency key")
+		}
+
+		existing := toRecord(&stored)
+		if !existing.Expired(now) {
+			return existing, nil
+		}
+
+		// Só remove se ninguém renovou o registro desde a leitura
+		if _, err := ir.Collection.DeleteOne(
+			ctx, bson.M{"_id": stored.Key, "expires_at": stored.ExpiresAt}); err != nil {
+			logger.ErrorContext(ctx, "Error trying to remove expired idempotency key", err)
+			return nil, internal_error.NewInternalServerError("Error trying to remove expired idempotency key")
+		}
+	}
+
+	return nil, internal_error.NewInternalServerError("Idempotency key is under contention")
+}
+
+func (ir *IdempotencyRepository) Complete(
+	ctx context.Context, record *idempotency_entity.Record) *internal_error.InternalError {
+	ctx, done := instrument.Operation(ctx, "idempotency", "Complete")
+	defer done()
+
+	update := bson.M{"$set": bson.M{
+		"completed":    true,
+		"status_code":  record.StatusCode,
+		"content_type": record.ContentType,
+		"body":         record.Body,
+		"expires_at":   record.ExpiresAt,
+	}}
+
+	if _, err := ir.Collection.UpdateOne(ctx, bson.M{"_id": record.Key}, update); err != nil {
+		logger.ErrorContext(ctx, "Error trying to store idempotent response", err)
+		return internal_error.NewInternalServerError("Error trying to store idempotent response")
+	}
+
+	return nil
+}
+
+func (ir *IdempotencyRepository) Release(
+	ctx context.Context, key string) *internal_error.InternalError {
+	ctx, done := instrument.Operation(ctx, "idempotency", "Release")
+	defer done()
+
+	if _, err := ir.Collection.DeleteOne(ctx, bson.M{"_id": key, "completed": false}); err != nil {
+		logger.ErrorContext(ctx, "Error trying to release idempotency key", err)
+		return internal_error.NewInternalServerError("Error trying to release idempotency key")
+	}
+
+	return nil
+}
+
+func toIdempotencyRecordMongo(record *idempotency_entity.Record) *IdempotencyRecordMongo {
+	return &IdempotencyRecordMongo{
+		Key:         record.Key,
+		Fingerprint: record.Fingerprint,
+		Completed:   record.Completed,
+		StatusCode:  record.StatusCode,
+		ContentType: record.ContentType,
+		Body:        record.Body,
+		CreatedAt:   record.CreatedAt.UnixMilli(),
+		ExpiresAt:   record.ExpiresAt,
+	}
+}
+
+func toRecord(stored *IdempotencyRecordMongo) *idempotency_entity.Record {
+	return &idempotency_entity.Record{
+		Key:         stored.Key,
+		Fingerprint: stored.Fingerprint,
+		Completed:   stored.Completed,
+		StatusCode:  stored.StatusCode,
+		ContentType: stored.ContentType,
+		Body:        stored.Body,
+		CreatedAt:   time.UnixMilli(stored.CreatedAt),
+		ExpiresAt:   stored.ExpiresAt,
+	}
+}
@@ -0,0 +1,30 @@
+package instrument
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/infra/metrics"
+	"fullcycle-auction_go/internal/infra/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Operation abre o span de um método de repositório e mede sua duração. Os
+// comandos enviados ao MongoDB aparecem como spans filhos. Uso:
+//
+//	ctx, done := instrument.Operation(ctx, "auction", "FindAuctionById")
+//	defer done()
+func Operation(ctx context.Context, repository string, method string) (context.Context, func()) {
+	observe := metrics.ObserveMongo(repository, method)
+	ctx, span := tracing.Start(ctx, repository+"."+method,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			attribute.String("db.system", "mongodb"),
+			attribute.String("repository", repository),
+		))
+
+	return ctx, func() {
+		span.End()
+		observe()
+	}
+}
@@ -0,0 +1,136 @@
+package lease
+
+import (
+	"context"
+	"errors"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/lease_entity"
+	"fullcycle-auction_go/internal/infra/database/instrument"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+type LeaseEntityMongo struct {
+	Name      string `bson:"_id"`
+	HolderId  string `bson:"holder_id"`
+	Token     int64  `bson:"token"`
+	ExpiresAt int64  `bson:"expires_at"` // milissegundos desde a época Unix
+}
+
+// LeaseRepository guarda uma concessão por documento. Todas as alterações são
+// condicionais, então duas réplicas nunca detêm a mesma concessão ao mesmo
+// tempo segundo o banco.
+type LeaseRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewLeaseRepository(database *mongo.Database) *LeaseRepository {
+	return &LeaseRepository{
+		Collection: database.Collection("leases"),
+	}
+}
+
+func (lr *LeaseRepository) Acquire(
+	ctx context.Context,
+	name, holderId string,
+	ttl time.Duration,
+	now time.Time) (*lease_entity.Lease, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "lease", "Acquire")
+	defer done()
+
+	filter := bson.M{
+		"_id": name,
+		"$or": bson.A{
+			bson.M{"expires_at": bson.M{"$lte": now.UnixMilli()}},
+			bson.M{"holder_id": holderId},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{"holder_id": holderId, "expires_at": now.Add(ttl).UnixMilli()},
+		"$inc": bson.M{"token": 1},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var leaseMongo LeaseEntityMongo
+	err := lr.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&leaseMongo)
+	if err == nil {
+		return toLease(&leaseMongo), nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		logger.ErrorContext(ctx, "Error trying to acquire lease", err, zap.String("lease", name))
+		return nil, internal_error.NewInternalServerError("Error trying to acquire lease")
+	}
+
+	// A concessão não existe ou é de outra réplica; só a criação pode vencer
+	leaseMongo = LeaseEntityMongo{
+		Name:      name,
+		HolderId:  holderId,
+		Token:     1,
+		ExpiresAt: now.Add(ttl).UnixMilli(),
+	}
+	if _, err := lr.Collection.InsertOne(ctx, leaseMongo); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, nil
+		}
+		logger.ErrorContext(ctx, "Error trying to create lease", err, zap.String("lease", name))
+		return nil, internal_error.NewInternalServerError("Error trying to create lease")
+	}
+
+	return toLease(&leaseMongo), nil
+}
+
+func (lr *LeaseRepository) Renew(
+	ctx context.Context,
+	lease *lease_entity.Lease,
+	ttl time.Duration,
+	now time.Time) (*lease_entity.Lease, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "lease", "Renew")
+	defer done()
+
+	expiresAt := now.Add(ttl).UnixMilli()
+	filter := bson.M{"_id": lease.Name, "holder_id": lease.HolderId, "token": lease.Token}
+	update := bson.M{"$set": bson.M{"expires_at": expiresAt}}
+
+	result, err := lr.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to renew lease", err, zap.String("lease", lease.Name))
+		return nil, internal_error.NewInternalServerError("Error trying to renew lease")
+	}
+	if result.MatchedCount == 0 {
+		return nil, nil
+	}
+
+	renewed := *lease
+	renewed.ExpiresAt = time.UnixMilli(expiresAt)
+	return &renewed, nil
+}
+
+func (lr *LeaseRepository) Release(
+	ctx context.Context, lease *lease_entity.Lease) *internal_error.InternalError {
+	ctx, done := instrument.Operation(ctx, "lease", "Release")
+	defer done()
+
+	filter := bson.M{"_id": lease.Name, "holder_id": lease.HolderId, "token": lease.Token}
+	update := bson.M{"$set": bson.M{"expires_at": int64(0)}}
+
+	if _, err := lr.Collection.UpdateOne(ctx, filter, update); err != nil {
+		logger.ErrorContext(ctx, "Error trying to release lease", err, zap.String("lease", lease.Name))
+		return internal_error.NewInternalServerError("Error trying to release lease")
+	}
+
+	return nil
+}
+
+func toLease(leaseMongo *LeaseEntityMongo) *lease_entity.Lease {
+	return &lease_entity.Lease{
+		Name:      leaseMongo.Name,
+		HolderId:  leaseMongo.HolderId,
+		Token:     leaseMongo.Token,
+		ExpiresAt: time.UnixMilli(leaseMongo.ExpiresAt),
+	}
+}
@@ -0,0 +1,190 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/infra/database/instrument"
+	"fullcycle-auction_go/internal/internal_error"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+// Migration corrige documentos já gravados. Up precisa ser idempotente: uma
+// migração interrompida é executada por inteiro na próxima vez.
+type Migration struct {
+	Version     int
+	Name        string
+	Description string
+	Up          func(ctx context.Context, database *mongo.Database) error
+}
+
+type MigrationStatus struct {
+	Version     int        `json:"version"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Applied     bool       `json:"applied"`
+	AppliedAt   *time.Time `json:"applied_at,omitempty"`
+}
+
+type MigrationEntityMongo struct {
+	Version   int    `bson:"_id"`
+	Name      string `bson:"name"`
+	AppliedAt int64  `bson:"applied_at"` // milissegundos desde a época Unix
+}
+
+// Migrator aplica as migrações em ordem de versão e registra as aplicadas na
+// coleção schema_migrations
+type Migrator struct {
+	Collection *mongo.Collection
+	database   *mongo.Database
+	migrations []Migration
+}
+
+func NewMigrator(database *mongo.Database, migrations []Migration) (*Migrator, error) {
+	if err := validate(migrations); err != nil {
+		return nil, err
+	}
+
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	return &Migrator{
+		Collection: database.Collection("schema_migrations"),
+		database:   database,
+		migrations: sorted,
+	}, nil
+}
+
+// Status lista todas as migrações, indicando quais já foram aplicadas
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, *internal_error.InternalError) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return statuses(m.migrations, applied), nil
+}
+
+// Up aplica as migrações pendentes e retorna as aplicadas nesta execução. A
+// primeira falha interrompe a execução; as seguintes ficam pendentes.
+func (m *Migrator) Up(ctx context.Context) ([]MigrationStatus, *internal_error.InternalError) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []MigrationStatus
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		if err := m.run(ctx, migration); err != nil {
+			return ran, err
+		}
+
+		now := time.Now()
+		ran = append(ran, MigrationStatus{
+			Version:     migration.Version,
+			Name:        migration.Name,
+			Description: migration.Description,
+			Applied:     true,
+			AppliedAt:   &now,
+		})
+	}
+
+	return ran, nil
+}
+
+func (m *Migrator) run(ctx context.Context, migration Migration) *internal_error.InternalError {
+	ctx, done := instrument.Operation(ctx, "migration", migration.Name)
+	defer done()
+
+	if err := migration.Up(ctx, m.database); err != nil {
+		logger.ErrorContext(ctx, "Error trying to apply migration", err, zap.Int("version", migration.Version),
+			zap.String("migration", migration.Name))
+		return internal_error.NewInternalServerError(
+			fmt.Sprintf("Error trying to apply migration %d (%s)", migration.Version, migration.Name))
+	}
+
+	record := MigrationEntityMongo{
+		Version:   migration.Version,
+		Name:      migration.Name,
+		AppliedAt: time.Now().UnixMilli(),
+	}
+	// Outro processo pode ter aplicado a mesma migração ao mesmo tempo; como
+	// ela é idempotente, basta um registro
+	if _, err := m.Collection.InsertOne(ctx, record); err != nil && !mongo.IsDuplicateKeyError(err) {
+		logger.ErrorContext(ctx, "Error trying to record migration", err, zap.Int("version", migration.Version))
+		return internal_error.NewInternalServerError(
+			fmt.Sprintf("Error trying to record migration %d", migration.Version))
+	}
+
+	logger.InfoContext(ctx, "Migration applied", zap.Int("version", migration.Version),
+		zap.String("migration", migration.Name))
+	return nil
+}
+
+// applied retorna o horário de aplicação de cada versão já aplicada
+func (m *Migrator) applied(ctx context.Context) (map[int]time.Time, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "migration", "applied")
+	defer done()
+
+	cursor, err := m.Collection.Find(ctx, bson.M{})
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find applied migrations", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find applied migrations")
+	}
+	defer cursor.Close(ctx)
+
+	var records []MigrationEntityMongo
+	if err := cursor.All(ctx, &records); err != nil {
+		logger.ErrorContext(ctx, "Error trying to decode applied migrations", err)
+		return nil, internal_error.NewInternalServerError("Error trying to decode applied migrations")
+	}
+
+	applied := make(map[int]time.Time, len(records))
+	for _, record := range records {
+		applied[record.Version] = time.UnixMilli(record.AppliedAt)
+	}
+	return applied, nil
+}
+
+func statuses(migrations []Migration, applied map[int]time.Time) []MigrationStatus {
+	result := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{
+			Version:     migration.Version,
+			Name:        migration.Name,
+			Description: migration.Description,
+		}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+		}
+		result = append(result, status)
+	}
+	return result
+}
+
+// validate recusa versões repetidas ou não positivas e migrações sem nome ou Up
+func validate(migrations []Migration) error {
+	seen := make(map[int]string, len(migrations))
+	for _, migration := range migrations {
+		if migration.Version <= 0 {
+			return fmt.Errorf("migration %q has invalid version %d", migration.Name, migration.Version)
+		}
+		if migration.Name == "" || migration.Up == nil {
+			return fmt.Errorf("migration %d needs a name and an Up function", migration.Version)
+		}
+		if other, ok := seen[migration.Version]; ok {
+			return fmt.Errorf("migrations %q and %q share version %d", other, migration.Name, migration.Version)
+		}
+		seen[migration.Version] = migration.Name
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package migration
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/infra/database/storedtime"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Migrations lista as migrações conhecidas. auctionDuration é a duração usada
+// para o término dos leilões antigos sem end_time, a mesma que os
+// repositórios usam ao lê-los. A ordem importa: o término só é calculado
+// depois de os timestamps estarem em milissegundos.
+func Migrations(auctionDuration time.Duration) []Migration {
+	return []Migration{
+		{
+			Version:     1,
+			Name:        "auction_timestamps_in_milliseconds",
+			Description: "Convert auction timestamps stored in seconds to milliseconds",
+			Up:          secondsToMilliseconds("auctions", "timestamp", "end_time"),
+		},
+		{
+			Version:     2,
+			Name:        "bid_timestamps_in_milliseconds",
+			Description: "Convert bid timestamps stored in seconds to milliseconds",
+			Up:          secondsToMilliseconds("bids", "timestamp"),
+		},
+		{
+			Version:     3,
+			Name:        "auction_end_time_backfill",
+			Description: "Store end_time on auctions created before it existed",
+			Up:          backfillAuctionEndTime(auctionDuration),
+		},
+	}
+}
+
+func secondsToMilliseconds(collection string, fields ...string) func(context.Context, *mongo.Database) error {
+	return func(ctx context.Context, database *mongo.Database) error {
+		for _, field := range fields {
+			filter := bson.M{field: bson.M{"$gt": 0, "$lt": storedtime.LegacySecondsThreshold}}
+			update := bson.A{bson.M{"$set": bson.M{
+				field: bson.M{"$multiply": bson.A{"$" + field, int64(1000)}},
+			}}}
+
+			if _, err := database.Collection(collection).UpdateMany(ctx, filter, update); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func backfillAuctionEndTime(auctionDuration time.Duration) func(context.Context, *mongo.Database) error {
+	return func(ctx context.Context, database *mongo.Database) error {
+		filter := bson.M{"end_time": bson.M{"$exists": false}}
+		update := bson.A{bson.M{"$set": bson.M{
+			"end_time": bson.M{"$add": bson.A{"$timestamp", auctionDuration.Milliseconds()}},
+		}}}
+
+		_, err := database.Collection("auctions").UpdateMany(ctx, filter, update)
+		return err
+	}
+}
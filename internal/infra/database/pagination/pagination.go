@@ -0,0 +1,103 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fullcycle-auction_go/internal/entity/pagination_entity"
+	"fullcycle-auction_go/internal/internal_error"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Cursor identifica o último documento entregue em uma página. O campo de
+// ordenação faz parte do cursor para que um token não seja reutilizado com
+// outra ordenação. Todos os campos de ordenação são numéricos, e Value é
+// sempre um número.
+type Cursor struct {
+	SortBy     string      `json:"s"`
+	Descending bool        `json:"d"`
+	Value      interface{} `json:"v"`
+	Id         string      `json:"id"`
+}
+
+// EncodeToken serializa o cursor em um token opaco para o cliente
+func EncodeToken(cursor Cursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeToken interpreta o token recebido e garante que ele pertence à
+// ordenação da requisição atual
+func DecodeToken(page pagination_entity.PageRequest) (*Cursor, *internal_error.InternalError) {
+	if page.Token == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(page.Token)
+	if err != nil {
+		return nil, internal_error.NewBadRequestError("Invalid page token")
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Id == "" {
+		return nil, internal_error.NewBadRequestError("Invalid page token")
+	}
+
+	if cursor.SortBy != page.SortBy || cursor.Descending != page.Descending {
+		return nil, internal_error.NewBadRequestError("Page token does not match the requested sort")
+	}
+
+	// O token não é assinado e Value vai direto para o filtro; um objeto como
+	// {"$ne": null} mudaria a consulta, então só números são aceitos
+	if _, ok := cursor.Value.(float64); !ok {
+		return nil, internal_error.NewBadRequestError("Invalid page token")
+	}
+
+	return &cursor, nil
+}
+
+// Sort monta a ordenação pelo campo informado usando _id como desempate, o que
+// torna a ordem total e estável entre páginas
+func Sort(field string, descending bool) bson.D {
+	direction := 1
+	if descending {
+		direction = -1
+	}
+
+	if field == "_id" {
+		return bson.D{{Key: "_id", Value: direction}}
+	}
+
+	return bson.D{
+		{Key: field, Value: direction},
+		{Key: "_id", Value: direction},
+	}
+}
+
+// Apply combina o filtro base com a condição de continuação a partir do cursor
+func Apply(filter bson.M, field string, cursor *Cursor) bson.M {
+	if cursor == nil {
+		return filter
+	}
+
+	operator := "$gt"
+	if cursor.Descending {
+		operator = "$lt"
+	}
+
+	var seek bson.M
+	if field == "_id" {
+		seek = bson.M{"_id": bson.M{operator: cursor.Id}}
+	} else {
+		seek = bson.M{"$or": bson.A{
+			bson.M{field: bson.M{operator: cursor.Value}},
+			bson.M{field: cursor.Value, "_id": bson.M{operator: cursor.Id}},
+		}}
+	}
+
+	if len(filter) == 0 {
+		return seek
+	}
+
+	return bson.M{"$and": bson.A{filter, seek}}
+}
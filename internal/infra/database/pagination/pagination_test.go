@@ -0,0 +1,60 @@
+package pagination
+
+import (
+	"fullcycle-auction_go/internal/entity/pagination_entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestTokenRoundTrip(t *testing.T) {
+	token := EncodeToken(Cursor{SortBy: "end_time", Descending: true, Value: int64(1700000000000), Id: "abc"})
+
+	cursor, err := DecodeToken(pagination_entity.PageRequest{Token: token, SortBy: "end_time", Descending: true})
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", cursor.Id)
+	assert.Equal(t, float64(1700000000000), cursor.Value)
+}
+
+func TestTokenRejectsDifferentSort(t *testing.T) {
+	token := EncodeToken(Cursor{SortBy: "end_time", Value: 10, Id: "abc"})
+
+	_, err := DecodeToken(pagination_entity.PageRequest{Token: token, SortBy: "bid_count"})
+	assert.NotNil(t, err)
+
+	_, err = DecodeToken(pagination_entity.PageRequest{Token: "not-a-token", SortBy: "end_time"})
+	assert.NotNil(t, err)
+}
+
+func TestTokenRejectsNonNumericValues(t *testing.T) {
+	page := pagination_entity.PageRequest{SortBy: "end_time"}
+
+	for _, value := range []interface{}{
+		map[string]interface{}{"$ne": nil},
+		[]interface{}{1, 2},
+		"1700000000000",
+		true,
+		nil,
+	} {
+		page.Token = EncodeToken(Cursor{SortBy: "end_time", Value: value, Id: "abc"})
+		_, err := DecodeToken(page)
+		assert.NotNil(t, err, "%v", value)
+	}
+}
+
+func TestApplyBuildsSeekCondition(t *testing.T) {
+	filter := bson.M{"status": 0}
+	cursor := &Cursor{Descending: true, Value: 5.0, Id: "abc"}
+
+	result := Apply(filter, "current_price", cursor)
+
+	assert.Equal(t, bson.M{"$and": bson.A{
+		filter,
+		bson.M{"$or": bson.A{
+			bson.M{"current_price": bson.M{"$lt": 5.0}},
+			bson.M{"current_price": 5.0, "_id": bson.M{"$lt": "abc"}},
+		}},
+	}}, result)
+	assert.Equal(t, filter, Apply(filter, "current_price", nil))
+}
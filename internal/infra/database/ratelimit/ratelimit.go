@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/ratelimit_entity"
+	"fullcycle-auction_go/internal/infra/database/instrument"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxTakeAttempts limita as tentativas quando outra instância altera o mesmo
+// balde entre a leitura e a escrita
+const maxTakeAttempts = 5
+
+type BucketEntityMongo struct {
+	Key       string  `bson:"_id"`
+	Tokens    float64 `bson:"tokens"`
+	UpdatedAt int64   `bson:"updated_at"` // milissegundos desde a época Unix
+	// ExpiresAt alimenta o índice TTL que descarta os baldes ociosos
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// RateLimitRepository compartilha os baldes entre as instâncias da API. Cada
+// consumo é uma troca condicional: a escrita só acontece se o balde ainda
+// estiver no estado lido, senão a leitura é refeita.
+type RateLimitRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewRateLimitRepository(database *mongo.Database) *RateLimitRepository {
+	repo := &RateLimitRepository{
+		Collection: database.Collection("rate_limits"),
+	}
+
+	// Cria o índice TTL dos baldes
+	go repo.ensureIndexes(context.Background())
+
+	return repo
+}
+
+func (rr *RateLimitRepository) ensureIndexes(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	_, err := rr.Collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to create rate limit indexes", err)
+	}
+}
+
+func (rr *RateLimitRepository) Take(
+	ctx context.Context,
+	key string,
+	limit ratelimit_entity.Limit,
+	cost int,
+	now time.Time) (*ratelimit_entity.Decision, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "ratelimit", "Take")
+	defer done()
+
+	// O estado é gravado em milissegundos; o cálculo usa a mesma precisão
+	now = time.UnixMilli(now.UnixMilli())
+
+	for attempt := 0; attempt < maxTakeAttempts; attempt++ {
+		var stored BucketEntityMongo
+		err := rr.Collection.FindOne(ctx, bson.M{"_id": key}).Decode(&stored)
+
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			bucket := ratelimit_entity.NewBucket(limit, now)
+			decision := bucket.TakeN(limit, cost, now)
+
+			_, err := rr.Collection.InsertOne(ctx, toBucketEntityMongo(key, bucket, limit))
+			if mongo.IsDuplicateKeyError(err) {
+				continue
+			}
+			if err != nil {
+				logger.ErrorContext(ctx, "Error trying to insert rate limit bucket", err)
+				return nil, internal_error.NewInternalServerError("Error trying to insert rate limit bucket")
+			}
+			return &decision, nil
+		}
+		if err != nil {
+			logger.ErrorContext(ctx, "Error trying to find rate limit bucket", err)
+			return nil, internal_error.NewInternalServerError("Error trying to find rate limit bucket")
+		}
+
+		bucket := ratelimit_entity.Bucket{
+			Tokens:    stored.Tokens,
+			UpdatedAt: time.UnixMilli(stored.UpdatedAt),
+		}
+		decision := bucket.TakeN(limit, cost, now)
+
+		filter := bson.M{"_id": key, "tokens": stored.Tokens, "updated_at": stored.UpdatedAt}
+		result, err := rr.Collection.ReplaceOne(ctx, filter, toBucketEntityMongo(key, bucket, limit))
+		if err != nil {
+			logger.ErrorContext(ctx, "Error trying to update rate limit bucket", err)
+			return nil, internal_error.NewInternalServerError("Error trying to update rate limit bucket")
+		}
+		if result.MatchedCount == 1 {
+			return &decision, nil
+		}
+	}
+
+	return nil, internal_error.NewInternalServerError("Rate limit bucket is under contention")
+}
+
+func toBucketEntityMongo(
+	key string, bucket ratelimit_entity.Bucket, limit ratelimit_entity.Limit) *BucketEntityMongo {
+	return &BucketEntityMongo{
+		Key:       key,
+		Tokens:    bucket.Tokens,
+		UpdatedAt: bucket.UpdatedAt.UnixMilli(),
+		ExpiresAt: bucket.UpdatedAt.Add(limit.IdleAfter()),
+	}
+}
@@ -0,0 +1,17 @@
+package storedtime
+
+import "time"
+
+// LegacySecondsThreshold separa os timestamps antigos, gravados em segundos,
+// dos atuais em milissegundos. Valores abaixo dele são segundos: um instante
+// em milissegundos só fica abaixo de 1e11 antes de março de 1973.
+const LegacySecondsThreshold = 1e11
+
+// ToTime converte o timestamp persistido, aceitando documentos antigos
+// em segundos ainda não convertidos pelas migrações
+func ToTime(value int64) time.Time {
+	if value < LegacySecondsThreshold {
+		return time.Unix(value, 0)
+	}
+	return time.UnixMilli(value)
+}
@@ -0,0 +1,13 @@
+package storedtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToTimeAcceptsSecondsAndMilliseconds(t *testing.T) {
+	assert.Equal(t, time.Unix(1700000000, 0), ToTime(1700000000))
+	assert.Equal(t, time.UnixMilli(1700000000123), ToTime(1700000000123))
+}
@@ -4,20 +4,32 @@ import (
 	"context"
 	"fullcycle-auction_go/configuration/logger"
 	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/infra/database/instrument"
 	"fullcycle-auction_go/internal/internal_error"
+
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func (ur *UserRepository) CreateUser(
 	ctx context.Context, user *user_entity.User) *internal_error.InternalError {
+	ctx, done := instrument.Operation(ctx, "user", "CreateUser")
+	defer done()
 
 	userEntityMongo := &UserEntityMongo{
-		Id:   user.Id,
-		Name: user.Name,
+		Id:           user.Id,
+		Name:         user.Name,
+		Email:        user.Email,
+		PasswordHash: user.PasswordHash,
+		Roles:        user.Roles,
+		Suspended:    user.Suspended,
 	}
 
 	_, err := ur.Collection.InsertOne(ctx, userEntityMongo)
 	if err != nil {
-		logger.Error("Error trying to insert user", err)
+		if mongo.IsDuplicateKeyError(err) {
+			return internal_error.NewBadRequestError("Email is already registered")
+		}
+		logger.ErrorContext(ctx, "Error trying to insert user", err)
 		return internal_error.NewInternalServerError("Error trying to insert user")
 	}
 
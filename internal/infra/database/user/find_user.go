@@ -6,14 +6,23 @@ import (
 	"fmt"
 	"fullcycle-auction_go/configuration/logger"
 	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/infra/database/instrument"
 	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
 )
 
 type UserEntityMongo struct {
-	Id   string `bson:"_id"`
-	Name string `bson:"name"`
+	Id           string             `bson:"_id"`
+	Name         string             `bson:"name"`
+	Email        string             `bson:"email,omitempty"`
+	PasswordHash string             `bson:"password_hash,omitempty"`
+	Roles        []user_entity.Role `bson:"roles,omitempty"`
+	Suspended    bool               `bson:"suspended,omitempty"`
 }
 
 type UserRepository struct {
@@ -21,32 +30,83 @@ type UserRepository struct {
 }
 
 func NewUserRepository(database *mongo.Database) *UserRepository {
-	return &UserRepository{
+	repo := &UserRepository{
 		Collection: database.Collection("users"),
 	}
+
+	// Cria o índice de e-mail único
+	go repo.ensureIndexes(context.Background())
+
+	return repo
+}
+
+func (ur *UserRepository) ensureIndexes(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// Usuários antigos não têm e-mail, por isso o índice é parcial
+	_, err := ur.Collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "email", Value: 1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"email": bson.M{"$exists": true}}),
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to create user indexes", err)
+	}
 }
 
 func (ur *UserRepository) FindUserById(
 	ctx context.Context, userId string) (*user_entity.User, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "user", "FindUserById")
+	defer done()
+
 	filter := bson.M{"_id": userId}
 
 	var userEntityMongo UserEntityMongo
 	err := ur.Collection.FindOne(ctx, filter).Decode(&userEntityMongo)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			logger.Error(fmt.Sprintf("User not found with this id = %s", userId), err)
+			logger.ErrorContext(ctx, "User not found", err, zap.String("target_user_id", userId))
 			return nil, internal_error.NewNotFoundError(
 				fmt.Sprintf("User not found with this id = %s", userId))
 		}
 
-		logger.Error("Error trying to find user by userId", err)
+		logger.ErrorContext(ctx, "Error trying to find user by userId", err)
 		return nil, internal_error.NewInternalServerError("Error trying to find user by userId")
 	}
 
-	userEntity := &user_entity.User{
-		Id:   userEntityMongo.Id,
-		Name: userEntityMongo.Name,
+	return toUserEntity(userEntityMongo), nil
+}
+
+func (ur *UserRepository) FindUserByEmail(
+	ctx context.Context, email string) (*user_entity.User, *internal_error.InternalError) {
+	ctx, done := instrument.Operation(ctx, "user", "FindUserByEmail")
+	defer done()
+
+	filter := bson.M{"email": user_entity.NormalizeEmail(email)}
+
+	var userEntityMongo UserEntityMongo
+	err := ur.Collection.FindOne(ctx, filter).Decode(&userEntityMongo)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError("User not found")
+		}
+
+		logger.ErrorContext(ctx, "Error trying to find user by email", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find user by email")
 	}
 
-	return userEntity, nil
+	return toUserEntity(userEntityMongo), nil
+}
+
+func toUserEntity(userEntityMongo UserEntityMongo) *user_entity.User {
+	return &user_entity.User{
+		Id:           userEntityMongo.Id,
+		Name:         userEntityMongo.Name,
+		Email:        userEntityMongo.Email,
+		PasswordHash: userEntityMongo.PasswordHash,
+		Roles:        userEntityMongo.Roles,
+		Suspended:    userEntityMongo.Suspended,
+	}
 }
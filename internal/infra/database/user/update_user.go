@@ -0,0 +1,49 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/infra/database/instrument"
+	"fullcycle-auction_go/internal/internal_error"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+func (ur *UserRepository) UpdateUserRoles(
+	ctx context.Context, userId string, roles []user_entity.Role) *internal_error.InternalError {
+	ctx, done := instrument.Operation(ctx, "user", "UpdateUserRoles")
+	defer done()
+
+	if roles == nil {
+		roles = []user_entity.Role{}
+	}
+
+	return ur.updateUser(ctx, userId, bson.M{"$set": bson.M{"roles": roles}})
+}
+
+func (ur *UserRepository) SetUserSuspended(
+	ctx context.Context, userId string, suspended bool) *internal_error.InternalError {
+	ctx, done := instrument.Operation(ctx, "user", "SetUserSuspended")
+	defer done()
+
+	return ur.updateUser(ctx, userId, bson.M{"$set": bson.M{"suspended": suspended}})
+}
+
+func (ur *UserRepository) updateUser(
+	ctx context.Context, userId string, update bson.M) *internal_error.InternalError {
+	result, err := ur.Collection.UpdateOne(ctx, bson.M{"_id": userId}, update)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to update user", err, zap.String("target_user_id", userId))
+		return internal_error.NewInternalServerError("Error trying to update user")
+	}
+
+	if result.MatchedCount == 0 {
+		return internal_error.NewNotFoundError(
+			fmt.Sprintf("User not found with this id = %s", userId))
+	}
+
+	return nil
+}
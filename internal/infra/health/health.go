@@ -0,0 +1,124 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Status string
+
+const (
+	Up   Status = "up"
+	Down Status = "down"
+)
+
+// CheckFunc verifica um componente; details é exposto no relatório mesmo
+// quando a verificação falha
+type CheckFunc func(ctx context.Context) (details any, err error)
+
+// ComponentReport é o resultado da verificação de um componente
+type ComponentReport struct {
+	Status        Status  `json:"status"`
+	LatencyMillis float64 `json:"latency_ms"`
+	Error         string  `json:"error,omitempty"`
+	Details       any     `json:"details,omitempty"`
+}
+
+// Report é o resultado da verificação de prontidão; Status é Down se algum
+// componente estiver Down
+type Report struct {
+	Status     Status                     `json:"status"`
+	CheckedAt  time.Time                  `json:"checked_at"`
+	Components map[string]ComponentReport `json:"components"`
+}
+
+type component struct {
+	name  string
+	check CheckFunc
+}
+
+// Checker executa em paralelo as verificações registradas, cada uma com o
+// mesmo prazo; uma verificação que passa do prazo conta como Down
+type Checker struct {
+	timeout    time.Duration
+	components []component
+	now        func() time.Time
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout, now: time.Now}
+}
+
+// Register adiciona um componente; deve ser chamado antes de Check
+func (c *Checker) Register(name string, check CheckFunc) {
+	c.components = append(c.components, component{name: name, check: check})
+	sort.SliceStable(c.components, func(i, j int) bool {
+		return c.components[i].name < c.components[j].name
+	})
+}
+
+func (c *Checker) Check(ctx context.Context) Report {
+	report := Report{
+		Status:     Up,
+		CheckedAt:  c.now(),
+		Components: make(map[string]ComponentReport, len(c.components)),
+	}
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	for _, comp := range c.components {
+		wg.Add(1)
+		go func(comp component) {
+			defer wg.Done()
+			result := c.run(ctx, comp)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			report.Components[comp.name] = result
+			if result.Status == Down {
+				report.Status = Down
+			}
+		}(comp)
+	}
+	wg.Wait()
+
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, comp component) ComponentReport {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	type outcome struct {
+		details any
+		err     error
+	}
+	done := make(chan outcome, 1)
+
+	started := c.now()
+	go func() {
+		details, err := comp.check(ctx)
+		done <- outcome{details, err}
+	}()
+
+	var result outcome
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		result.err = fmt.Errorf("check timed out after %s", c.timeout)
+	}
+
+	report := ComponentReport{
+		Status:        Up,
+		LatencyMillis: float64(c.now().Sub(started).Microseconds()) / 1000,
+		Details:       result.details,
+	}
+	if result.err != nil {
+		report.Status = Down
+		report.Error = result.err.Error()
+	}
+	return report
+}
@@ -0,0 +1,53 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckReportsEachComponent(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.Register("mongodb", func(ctx context.Context) (any, error) {
+		return nil, nil
+	})
+	checker.Register("bid_queue", func(ctx context.Context) (any, error) {
+		return map[string]int{"depth": 95, "capacity": 100}, errors.New("bid queue is 95% full")
+	})
+
+	report := checker.Check(context.Background())
+
+	assert.Equal(t, Down, report.Status)
+	assert.Equal(t, Up, report.Components["mongodb"].Status)
+	assert.Equal(t, Down, report.Components["bid_queue"].Status)
+	assert.Equal(t, "bid queue is 95% full", report.Components["bid_queue"].Error)
+	assert.Equal(t, map[string]int{"depth": 95, "capacity": 100}, report.Components["bid_queue"].Details)
+}
+
+func TestCheckTimesOutSlowComponents(t *testing.T) {
+	checker := NewChecker(20 * time.Millisecond)
+	release := make(chan struct{})
+	defer close(release)
+	checker.Register("mongodb", func(ctx context.Context) (any, error) {
+		<-release
+		return nil, nil
+	})
+
+	started := time.Now()
+	report := checker.Check(context.Background())
+
+	assert.Less(t, time.Since(started), time.Second)
+	assert.Equal(t, Down, report.Status)
+	assert.Contains(t, report.Components["mongodb"].Error, "timed out")
+	assert.GreaterOrEqual(t, report.Components["mongodb"].LatencyMillis, float64(20))
+}
+
+func TestCheckWithoutComponentsIsUp(t *testing.T) {
+	report := NewChecker(time.Second).Check(context.Background())
+
+	assert.Equal(t, Up, report.Status)
+	assert.Empty(t, report.Components)
+}
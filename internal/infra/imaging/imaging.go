@@ -0,0 +1,118 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+)
+
+// AllowedContentTypes lista os formatos de imagem aceitos no upload
+var AllowedContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// DetectContentType identifica o formato pelo conteúdo do arquivo, ignorando o
+// tipo declarado pelo cliente
+func DetectContentType(data []byte) (string, bool) {
+	contentType := http.DetectContentType(data)
+	return contentType, AllowedContentTypes[contentType]
+}
+
+// ErrTooManyPixels indica uma imagem maior que o limite de pixels. O limite
+// é conferido pelo cabeçalho, antes da decodificação, porque um arquivo
+// pequeno pode declarar dimensões que ocupariam gigabytes na memória.
+var ErrTooManyPixels = errors.New("image has too many pixels")
+
+type Thumbnail struct {
+	Data []byte
+	// Width e Height são as dimensões da imagem original
+	Width  int
+	Height int
+}
+
+// MakeThumbnail decodifica a imagem e gera uma miniatura JPEG cujo maior lado
+// tem no máximo maxSide pixels. Imagens com mais de maxPixels pixels são
+// recusadas com ErrTooManyPixels sem serem decodificadas.
+func MakeThumbnail(data []byte, maxSide int, maxPixels int64) (*Thumbnail, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decoding image header: %w", err)
+	}
+	if int64(config.Width)*int64(config.Height) > maxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	source, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decoding image: %w", err)
+	}
+
+	bounds := source.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return nil, fmt.Errorf("image has no pixels")
+	}
+
+	thumbWidth, thumbHeight := width, height
+	if width > maxSide || height > maxSide {
+		if width >= height {
+			thumbWidth, thumbHeight = maxSide, max(1, height*maxSide/width)
+		} else {
+			thumbWidth, thumbHeight = max(1, width*maxSide/height), maxSide
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := jpeg.Encode(&buffer, resize(source, thumbWidth, thumbHeight), &jpeg.Options{Quality: 80}); err != nil {
+		return nil, fmt.Errorf("encoding thumbnail: %w", err)
+	}
+
+	return &Thumbnail{
+		Data:   buffer.Bytes(),
+		Width:  width,
+		Height: height,
+	}, nil
+}
+
+// resize reduz a imagem pela média dos pixels de origem que caem em cada pixel
+// de destino (box filter), o que evita o serrilhado da amostragem simples
+func resize(source image.Image, width, height int) image.Image {
+	bounds := source.Bounds()
+	target := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*bounds.Dy()/height)
+
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := max(x0+1, bounds.Min.X+(x+1)*bounds.Dx()/width)
+
+			var r, g, b, a, count uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := source.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					count++
+				}
+			}
+
+			target.Set(x, y, color.RGBA64{
+				R: uint16(r / count),
+				G: uint16(g / count),
+				B: uint16(b / count),
+				A: uint16(a / count),
+			})
+		}
+	}
+
+	return target
+}
@@ -0,0 +1,38 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	var buffer bytes.Buffer
+	require.NoError(t, png.Encode(&buffer, image.NewGray(image.Rect(0, 0, width, height))))
+	return buffer.Bytes()
+}
+
+func TestMakeThumbnailKeepsAspectRatio(t *testing.T) {
+	thumbnail, err := MakeThumbnail(encodePNG(t, 400, 200), 100, 1_000_000)
+	require.NoError(t, err)
+	assert.Equal(t, 400, thumbnail.Width)
+	assert.Equal(t, 200, thumbnail.Height)
+
+	decoded, _, err := image.DecodeConfig(bytes.NewReader(thumbnail.Data))
+	require.NoError(t, err)
+	assert.Equal(t, 100, decoded.Width)
+	assert.Equal(t, 50, decoded.Height)
+}
+
+func TestMakeThumbnailRejectsTooManyPixels(t *testing.T) {
+	// O limite é conferido pelo cabeçalho, sem decodificar a imagem
+	_, err := MakeThumbnail(encodePNG(t, 300, 200), 100, 300*200-1)
+	assert.ErrorIs(t, err, ErrTooManyPixels)
+
+	_, err = MakeThumbnail(encodePNG(t, 300, 200), 100, 300*200)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,255 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/lease_entity"
+	"fullcycle-auction_go/internal/infra/scheduler"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Config define a concessão disputada pelas réplicas. TTL precisa ser maior
+// que RenewInterval para que uma renovação atrasada não derrube o líder.
+type Config struct {
+	Name          string
+	HolderId      string
+	TTL           time.Duration
+	RenewInterval time.Duration
+	RetryInterval time.Duration
+}
+
+// NewConfig monta a configuração da concessão name; o id da réplica combina
+// o hostname com um sufixo aleatório para que reinícios não herdem a
+// concessão anterior
+func NewConfig(name string, ttl, renewInterval, retryInterval time.Duration) Config {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "instance"
+	}
+
+	return Config{
+		Name:          name,
+		HolderId:      fmt.Sprintf("%s-%s", hostname, uuid.NewString()[:8]),
+		TTL:           ttl,
+		RenewInterval: renewInterval,
+		RetryInterval: retryInterval,
+	}
+}
+
+func (c Config) normalized() Config {
+	if c.TTL <= 0 {
+		c.TTL = 15 * time.Second
+	}
+	if c.RenewInterval <= 0 || c.RenewInterval >= c.TTL {
+		c.RenewInterval = c.TTL / 3
+	}
+	if c.RetryInterval <= 0 {
+		c.RetryInterval = c.RenewInterval
+	}
+	return c
+}
+
+// Elector disputa uma concessão e executa lead enquanto a detém. A concessão
+// é renovada a cada RenewInterval; se a renovação falhar até o vencimento, ou
+// outra réplica assumir, lead é interrompido e a disputa recomeça.
+type Elector struct {
+	repository lease_entity.LeaseRepositoryInterface
+	config     Config
+	clock      scheduler.Clock
+
+	mutex sync.RWMutex
+	lease *lease_entity.Lease
+
+	// attempted é fechado depois da primeira disputa, ganha ou não
+	attempted   chan struct{}
+	attemptOnce sync.Once
+
+	// heartbeat é o horário (em nanossegundos) da última disputa ou renovação
+	heartbeat atomic.Int64
+}
+
+func NewElector(
+	repository lease_entity.LeaseRepositoryInterface,
+	config Config,
+	clock scheduler.Clock) *Elector {
+	return &Elector{
+		repository: repository,
+		config:     config.normalized(),
+		clock:      clock,
+		attempted:  make(chan struct{}),
+	}
+}
+
+// Attempted é fechado quando a primeira disputa termina; a partir daí
+// IsLeader reflete o resultado
+func (e *Elector) Attempted() <-chan struct{} {
+	return e.attempted
+}
+
+// Token retorna o fencing token da concessão atual, se ela ainda vale pelo
+// relógio local
+func (e *Elector) Token() (int64, bool) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	if e.lease == nil || !e.lease.HeldAt(e.clock.Now()) {
+		return 0, false
+	}
+	return e.lease.Token, true
+}
+
+// Heartbeat retorna o horário da última disputa ou renovação feita por Run;
+// zero se Run ainda não começou
+func (e *Elector) Heartbeat() time.Time {
+	if beat := e.heartbeat.Load(); beat > 0 {
+		return time.Unix(0, beat)
+	}
+	return time.Time{}
+}
+
+// HeartbeatTimeout é o maior intervalo esperado entre dois heartbeats: a
+// espera entre tentativas mais o prazo da chamada ao repositório, com folga
+func (e *Elector) HeartbeatTimeout() time.Duration {
+	return 3 * max(e.config.RenewInterval, e.config.RetryInterval)
+}
+
+// IsLeader informa se esta réplica detém a concessão
+func (e *Elector) IsLeader() bool {
+	_, ok := e.Token()
+	return ok
+}
+
+// Run disputa a concessão até stop ser fechado. lead recebe um canal que é
+// fechado quando a liderança termina e deve retornar logo em seguida; ao
+// parar, a concessão é liberada para que outra réplica assuma sem esperar o TTL.
+func (e *Elector) Run(stop <-chan struct{}, lead func(leaderStop <-chan struct{})) {
+	for {
+		lease := e.acquire()
+		e.beat()
+		if lease == nil {
+			e.markAttempted()
+			if !e.wait(stop, e.config.RetryInterval) {
+				return
+			}
+			continue
+		}
+
+		e.setLease(lease)
+		e.markAttempted()
+		logger.Info("Elected leader", zap.String("lease", e.config.Name),
+			zap.String("holder_id", lease.HolderId),
+			zap.Int64("token", lease.Token))
+
+		leaderStop := make(chan struct{})
+		leadDone := make(chan struct{})
+		go func() {
+			defer close(leadDone)
+			lead(leaderStop)
+		}()
+
+		stopped := e.hold(stop)
+
+		close(leaderStop)
+		<-leadDone
+
+		lease = e.currentLease()
+		e.setLease(nil)
+
+		if stopped {
+			e.release(lease)
+			return
+		}
+
+		logger.Warn("Lost leadership", zap.String("lease", e.config.Name),
+			zap.Int64("token", lease.Token))
+	}
+}
+
+// hold renova a concessão até stop (retorna true) ou até perdê-la (false)
+func (e *Elector) hold(stop <-chan struct{}) bool {
+	for {
+		if !e.wait(stop, e.config.RenewInterval) {
+			return true
+		}
+
+		lease := e.currentLease()
+		ctx, cancel := context.WithTimeout(context.Background(), e.config.RenewInterval)
+		renewed, err := e.repository.Renew(ctx, lease, e.config.TTL, e.clock.Now())
+		cancel()
+		e.beat()
+
+		switch {
+		case err != nil:
+			// Uma falha temporária não derruba o líder enquanto a concessão valer
+			if !lease.HeldAt(e.clock.Now()) {
+				return false
+			}
+		case renewed == nil:
+			return false
+		default:
+			e.setLease(renewed)
+		}
+	}
+}
+
+func (e *Elector) acquire() *lease_entity.Lease {
+	ctx, cancel := context.WithTimeout(context.Background(), e.config.RetryInterval)
+	defer cancel()
+
+	lease, err := e.repository.Acquire(ctx, e.config.Name, e.config.HolderId, e.config.TTL, e.clock.Now())
+	if err != nil {
+		return nil
+	}
+	return lease
+}
+
+func (e *Elector) release(lease *lease_entity.Lease) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := e.repository.Release(ctx, lease); err != nil {
+		logger.Error("Error trying to release leadership", err, zap.String("lease", e.config.Name))
+		return
+	}
+	logger.Info("Released leadership", zap.String("lease", e.config.Name))
+}
+
+// wait espera d e retorna false se stop for fechado antes
+func (e *Elector) wait(stop <-chan struct{}, d time.Duration) bool {
+	timer := e.clock.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		return true
+	case <-stop:
+		return false
+	}
+}
+
+func (e *Elector) beat() {
+	e.heartbeat.Store(e.clock.Now().UnixNano())
+}
+
+func (e *Elector) markAttempted() {
+	e.attemptOnce.Do(func() { close(e.attempted) })
+}
+
+func (e *Elector) currentLease() *lease_entity.Lease {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.lease
+}
+
+func (e *Elector) setLease(lease *lease_entity.Lease) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.lease = lease
+}
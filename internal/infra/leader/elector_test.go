@@ -0,0 +1,208 @@
+package leader
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/lease_entity"
+	"fullcycle-auction_go/internal/infra/scheduler"
+	"fullcycle-auction_go/internal/internal_error"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryLeaseRepository reproduz as trocas condicionais do repositório do MongoDB
+type memoryLeaseRepository struct {
+	mutex  sync.Mutex
+	leases map[string]lease_entity.Lease
+	// unavailable simula uma falha de comunicação com o banco
+	unavailable bool
+}
+
+func (r *memoryLeaseRepository) Acquire(
+	ctx context.Context,
+	name, holderId string,
+	ttl time.Duration,
+	now time.Time) (*lease_entity.Lease, *internal_error.InternalError) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.unavailable {
+		return nil, internal_error.NewInternalServerError("database unavailable")
+	}
+
+	current, ok := r.leases[name]
+	if ok && current.HolderId != holderId && current.HeldAt(now) {
+		return nil, nil
+	}
+
+	lease := lease_entity.Lease{Name: name, HolderId: holderId, Token: current.Token + 1, ExpiresAt: now.Add(ttl)}
+	r.leases[name] = lease
+	return &lease, nil
+}
+
+func (r *memoryLeaseRepository) Renew(
+	ctx context.Context,
+	lease *lease_entity.Lease,
+	ttl time.Duration,
+	now time.Time) (*lease_entity.Lease, *internal_error.InternalError) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.unavailable {
+		return nil, internal_error.NewInternalServerError("database unavailable")
+	}
+
+	current := r.leases[lease.Name]
+	if current.HolderId != lease.HolderId || current.Token != lease.Token {
+		return nil, nil
+	}
+	current.ExpiresAt = now.Add(ttl)
+	r.leases[lease.Name] = current
+	return &current, nil
+}
+
+func (r *memoryLeaseRepository) Release(
+	ctx context.Context, lease *lease_entity.Lease) *internal_error.InternalError {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	current := r.leases[lease.Name]
+	if current.HolderId == lease.HolderId && current.Token == lease.Token {
+		current.ExpiresAt = time.Time{}
+		r.leases[lease.Name] = current
+	}
+	return nil
+}
+
+func (r *memoryLeaseRepository) setUnavailable(unavailable bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.unavailable = unavailable
+}
+
+type runningElector struct {
+	elector *Elector
+	stop    chan struct{}
+	done    chan struct{}
+	// leading conta os mandatos iniciados
+	mutex   sync.Mutex
+	leading int
+	active  bool
+}
+
+func startElector(repository *memoryLeaseRepository, holderId string) *runningElector {
+	running := &runningElector{
+		elector: NewElector(repository, Config{
+			Name:          "auction-closer",
+			HolderId:      holderId,
+			TTL:           60 * time.Millisecond,
+			RenewInterval: 10 * time.Millisecond,
+			RetryInterval: 10 * time.Millisecond,
+		}, scheduler.RealClock{}),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	go func() {
+		defer close(running.done)
+		running.elector.Run(running.stop, func(leaderStop <-chan struct{}) {
+			running.setActive(true)
+			<-leaderStop
+			running.setActive(false)
+		})
+	}()
+	return running
+}
+
+func (r *runningElector) setActive(active bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.active = active
+	if active {
+		r.leading++
+	}
+}
+
+func (r *runningElector) isActive() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.active
+}
+
+func (r *runningElector) shutdown() {
+	close(r.stop)
+	<-r.done
+}
+
+func TestElectorHandsOverLeadershipOnRelease(t *testing.T) {
+	repository := &memoryLeaseRepository{leases: make(map[string]lease_entity.Lease)}
+
+	first := startElector(repository, "first")
+	<-first.elector.Attempted()
+	assert.True(t, first.elector.IsLeader())
+	require.Eventually(t, first.isActive, time.Second, time.Millisecond)
+	firstToken, ok := first.elector.Token()
+	require.True(t, ok)
+
+	second := startElector(repository, "second")
+	defer second.shutdown()
+	<-second.elector.Attempted()
+
+	// Enquanto a primeira réplica renova a concessão, a segunda só espera
+	time.Sleep(100 * time.Millisecond)
+	assert.True(t, first.isActive())
+	assert.False(t, second.isActive())
+	assert.False(t, second.elector.IsLeader())
+
+	first.shutdown()
+	assert.False(t, first.isActive())
+	assert.False(t, first.elector.IsLeader())
+
+	require.Eventually(t, second.isActive, time.Second, time.Millisecond)
+	secondToken, ok := second.elector.Token()
+	require.True(t, ok)
+	assert.Greater(t, secondToken, firstToken)
+}
+
+func TestElectorStepsDownWhenLeaseCannotBeRenewed(t *testing.T) {
+	repository := &memoryLeaseRepository{leases: make(map[string]lease_entity.Lease)}
+
+	elector := startElector(repository, "first")
+	defer elector.shutdown()
+	require.Eventually(t, elector.isActive, time.Second, time.Millisecond)
+
+	repository.setUnavailable(true)
+	require.Eventually(t, func() bool { return !elector.isActive() }, time.Second, time.Millisecond)
+	assert.False(t, elector.elector.IsLeader())
+
+	// Com o banco de volta, a réplica disputa e assume um novo mandato
+	repository.setUnavailable(false)
+	require.Eventually(t, elector.isActive, time.Second, time.Millisecond)
+	elector.mutex.Lock()
+	assert.Equal(t, 2, elector.leading)
+	elector.mutex.Unlock()
+}
+
+func TestElectorHeartbeatAdvancesForLeaderAndFollower(t *testing.T) {
+	repository := &memoryLeaseRepository{leases: make(map[string]lease_entity.Lease)}
+
+	leader := startElector(repository, "first")
+	defer leader.shutdown()
+	follower := startElector(repository, "second")
+	defer follower.shutdown()
+
+	<-leader.elector.Attempted()
+	<-follower.elector.Attempted()
+
+	for _, running := range []*runningElector{leader, follower} {
+		first := running.elector.Heartbeat()
+		require.False(t, first.IsZero())
+		require.Eventually(t, func() bool {
+			return running.elector.Heartbeat().After(first)
+		}, time.Second, time.Millisecond)
+	}
+	assert.Equal(t, 30*time.Millisecond, leader.elector.HeartbeatTimeout())
+}
@@ -0,0 +1,157 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "auction"
+
+// Registry reúne as métricas da aplicação expostas em /metrics, além das
+// métricas do runtime do Go e do processo
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Requisições HTTP atendidas, por rota e status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duração das requisições HTTP, por rota.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	bidsReceived = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bids_received_total",
+		Help:      "Lances recebidos, pela fila ou em lote.",
+	}, []string{"source"})
+
+	bidsAccepted = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bids_accepted_total",
+		Help:      "Lances gravados no banco.",
+	})
+
+	bidsRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bids_rejected_total",
+		Help:      "Lances recusados, por motivo.",
+	}, []string{"reason"})
+
+	bidBatchSize = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "bid_batch_size",
+		Help:      "Quantidade de lances em cada gravação da fila.",
+		Buckets:   []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000},
+	})
+
+	bidFlushDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "bid_flush_duration_seconds",
+		Help:      "Duração de cada gravação de lances da fila.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	auctionCloseLag = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "auction_close_lag_seconds",
+		Help:      "Atraso entre o término previsto de um leilão e o seu fechamento.",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10, 30, 60, 300, 3600},
+	})
+
+	mongoDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mongo_operation_duration_seconds",
+		Help:      "Duração das operações no MongoDB, por repositório e método.",
+		Buckets:   []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+	}, []string{"repository", "method"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		bidsReceived,
+		bidsAccepted,
+		bidsRejected,
+		bidBatchSize,
+		bidFlushDuration,
+		auctionCloseLag,
+		mongoDuration,
+	)
+}
+
+// Handler expõe o Registry no formato de texto do Prometheus
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveHTTPRequest registra uma requisição atendida. route é o padrão da
+// rota (/auction/:auctionId), nunca o caminho recebido, para limitar a
+// cardinalidade.
+func ObserveHTTPRequest(method string, route string, status int, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// BidsReceived conta lances recebidos; source identifica a entrada (queue,
+// batch ou recovery)
+func BidsReceived(source string, count int) {
+	bidsReceived.WithLabelValues(source).Add(float64(count))
+}
+
+func BidsAccepted(count int) {
+	bidsAccepted.Add(float64(count))
+}
+
+func BidRejected(reason string) {
+	bidsRejected.WithLabelValues(reason).Inc()
+}
+
+// ObserveBidFlush registra uma gravação de lances da fila
+func ObserveBidFlush(size int, duration time.Duration) {
+	bidBatchSize.Observe(float64(size))
+	bidFlushDuration.Observe(duration.Seconds())
+}
+
+// ObserveAuctionClose registra o atraso de um fechamento em relação ao
+// término previsto; fechamentos adiantados contam como zero
+func ObserveAuctionClose(endTime time.Time, closedAt time.Time) {
+	lag := closedAt.Sub(endTime)
+	if lag < 0 {
+		lag = 0
+	}
+	auctionCloseLag.Observe(lag.Seconds())
+}
+
+// ObserveMongo mede uma operação de repositório. Uso:
+//
+//	defer metrics.ObserveMongo("auction", "FindAuctionById")()
+func ObserveMongo(repository string, method string) func() {
+	started := time.Now()
+	return func() {
+		mongoDuration.WithLabelValues(repository, method).Observe(time.Since(started).Seconds())
+	}
+}
+
+// RegisterActiveAuctions expõe a quantidade de leilões ativos, calculada por
+// count a cada coleta
+func RegisterActiveAuctions(count func() float64) {
+	Registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_auctions",
+		Help:      "Leilões com status ativo.",
+	}, count))
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObserveAuctionCloseNeverRecordsNegativeLag(t *testing.T) {
+	endTime := time.Now()
+
+	ObserveAuctionClose(endTime, endTime.Add(-time.Second))
+	ObserveAuctionClose(endTime, endTime.Add(2*time.Second))
+
+	metric := &dto.Metric{}
+	assert.NoError(t, auctionCloseLag.Write(metric))
+	assert.Equal(t, uint64(2), metric.GetHistogram().GetSampleCount())
+	assert.InDelta(t, 2, metric.GetHistogram().GetSampleSum(), 0.001)
+}
+
+func TestBidCountersByReason(t *testing.T) {
+	BidsReceived("batch", 3)
+	BidsAccepted(1)
+	BidRejected("auction_closed")
+	BidRejected("auction_closed")
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(bidsReceived.WithLabelValues("batch")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(bidsAccepted))
+	assert.Equal(t, float64(2), testutil.ToFloat64(bidsRejected.WithLabelValues("auction_closed")))
+}
+
+func TestObserveMongoRecordsOnReturn(t *testing.T) {
+	stop := ObserveMongo("auction", "FindAuctionById")
+	stop()
+
+	assert.Equal(t, 1, testutil.CollectAndCount(mongoDuration))
+}
@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/ratelimit_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sync"
+	"time"
+)
+
+// sweepInterval define de quanto em quanto tempo os baldes ociosos são descartados
+const sweepInterval = time.Minute
+
+type memoryBucket struct {
+	bucket    ratelimit_entity.Bucket
+	idleAfter time.Duration
+}
+
+// MemoryStore guarda os baldes na memória do processo; cada instância da API
+// aplica os limites de forma independente
+type MemoryStore struct {
+	mutex     sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*memoryBucket),
+	}
+}
+
+func (ms *MemoryStore) Take(
+	ctx context.Context,
+	key string,
+	limit ratelimit_entity.Limit,
+	cost int,
+	now time.Time) (*ratelimit_entity.Decision, *internal_error.InternalError) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.sweep(now)
+
+	entry, ok := ms.buckets[key]
+	if !ok {
+		entry = &memoryBucket{bucket: ratelimit_entity.NewBucket(limit, now)}
+		ms.buckets[key] = entry
+	}
+	entry.idleAfter = limit.IdleAfter()
+
+	decision := entry.bucket.TakeN(limit, cost, now)
+	return &decision, nil
+}
+
+// sweep remove os baldes que já voltaram a ficar cheios, mantendo o mapa
+// proporcional aos clientes ativos
+func (ms *MemoryStore) sweep(now time.Time) {
+	if now.Sub(ms.lastSweep) < sweepInterval {
+		return
+	}
+	ms.lastSweep = now
+
+	for key, entry := range ms.buckets {
+		if now.Sub(entry.bucket.UpdatedAt) > entry.idleAfter {
+			delete(ms.buckets, key)
+		}
+	}
+}
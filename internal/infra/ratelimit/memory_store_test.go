@@ -0,0 +1,38 @@
+package ratelimit
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/ratelimit_entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreKeepsBucketsPerKey(t *testing.T) {
+	store := NewMemoryStore()
+	limit := ratelimit_entity.Limit{Rate: 1, Burst: 1}
+	now := time.Now()
+
+	decision, err := store.Take(context.Background(), "user:1", limit, 1, now)
+	assert.Nil(t, err)
+	assert.True(t, decision.Allowed)
+
+	decision, _ = store.Take(context.Background(), "user:1", limit, 1, now)
+	assert.False(t, decision.Allowed)
+
+	decision, _ = store.Take(context.Background(), "user:2", limit, 1, now)
+	assert.True(t, decision.Allowed)
+}
+
+func TestMemoryStoreDiscardsIdleBuckets(t *testing.T) {
+	store := NewMemoryStore()
+	limit := ratelimit_entity.Limit{Rate: 1, Burst: 1}
+	now := time.Now()
+
+	store.Take(context.Background(), "user:1", limit, 1, now)
+	store.Take(context.Background(), "user:2", limit, 1, now.Add(2*sweepInterval))
+
+	assert.Len(t, store.buckets, 1)
+	assert.Contains(t, store.buckets, "user:2")
+}
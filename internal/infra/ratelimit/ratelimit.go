@@ -0,0 +1,19 @@
+package ratelimit
+
+import (
+	"fullcycle-auction_go/internal/entity/ratelimit_entity"
+	mongo_ratelimit "fullcycle-auction_go/internal/infra/database/ratelimit"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// NewStore escolhe onde os baldes ficam: "memory" (padrão) ou "mongo",
+// necessário quando há várias instâncias da API
+func NewStore(storeType string, database *mongo.Database) ratelimit_entity.RateLimitStoreInterface {
+	switch storeType {
+	case "mongo":
+		return mongo_ratelimit.NewRateLimitRepository(database)
+	default:
+		return NewMemoryStore()
+	}
+}
@@ -0,0 +1,99 @@
+package readiness
+
+import (
+	"sync"
+	"time"
+)
+
+type Phase string
+
+const (
+	Recovering Phase = "recovering"
+	Ready      Phase = "ready"
+	Failed     Phase = "failed"
+)
+
+// StepReport descreve uma etapa da recuperação na inicialização
+type StepReport struct {
+	Name           string `json:"name"`
+	DurationMillis int64  `json:"duration_ms"`
+	Result         any    `json:"result,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// Report é o estado exposto da inicialização
+type Report struct {
+	Phase       Phase        `json:"phase"`
+	StartedAt   time.Time    `json:"started_at"`
+	CompletedAt *time.Time   `json:"completed_at,omitempty"`
+	Steps       []StepReport `json:"steps"`
+}
+
+// State acompanha a recuperação feita antes de a aplicação aceitar lances.
+// Começa em Recovering e só passa a Ready quando todas as etapas terminam.
+type State struct {
+	mutex  sync.RWMutex
+	report Report
+	now    func() time.Time
+}
+
+func NewState() *State {
+	return &State{
+		report: Report{Phase: Recovering, StartedAt: time.Now(), Steps: []StepReport{}},
+		now:    time.Now,
+	}
+}
+
+// Run executa uma etapa e registra sua duração e resultado; com erro, o
+// estado passa a Failed
+func (s *State) Run(name string, step func() (any, error)) error {
+	started := s.now()
+	result, err := step()
+
+	report := StepReport{
+		Name:           name,
+		DurationMillis: s.now().Sub(started).Milliseconds(),
+		Result:         result,
+	}
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.report.Steps = append(s.report.Steps, report)
+	if err != nil {
+		s.report.Phase = Failed
+	}
+	return err
+}
+
+// MarkReady encerra a recuperação
+func (s *State) MarkReady() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.report.Phase != Recovering {
+		return
+	}
+	completedAt := s.now()
+	s.report.Phase = Ready
+	s.report.CompletedAt = &completedAt
+}
+
+func (s *State) Ready() bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.report.Phase == Ready
+}
+
+// Report retorna uma cópia do estado atual
+func (s *State) Report() Report {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	report := s.report
+	report.Steps = append([]StepReport(nil), s.report.Steps...)
+	return report
+}
@@ -0,0 +1,42 @@
+package readiness
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateBecomesReadyAfterSteps(t *testing.T) {
+	state := NewState()
+	assert.False(t, state.Ready())
+	assert.Equal(t, Recovering, state.Report().Phase)
+
+	require.NoError(t, state.Run("auctions", func() (any, error) {
+		return map[string]int{"closed_overdue": 2}, nil
+	}))
+	state.MarkReady()
+
+	report := state.Report()
+	assert.True(t, state.Ready())
+	assert.Equal(t, Ready, report.Phase)
+	assert.NotNil(t, report.CompletedAt)
+	require.Len(t, report.Steps, 1)
+	assert.Equal(t, "auctions", report.Steps[0].Name)
+	assert.Equal(t, map[string]int{"closed_overdue": 2}, report.Steps[0].Result)
+}
+
+func TestStateFailsWhenAStepFails(t *testing.T) {
+	state := NewState()
+
+	err := state.Run("bids", func() (any, error) { return nil, errors.New("wal unavailable") })
+	assert.EqualError(t, err, "wal unavailable")
+
+	// Uma recuperação com falha não é marcada como pronta
+	state.MarkReady()
+	report := state.Report()
+	assert.False(t, state.Ready())
+	assert.Equal(t, Failed, report.Phase)
+	assert.Equal(t, "wal unavailable", report.Steps[0].Error)
+}
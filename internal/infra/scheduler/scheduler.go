@@ -0,0 +1,216 @@
+package scheduler
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// Clock fornece a hora atual e os temporizadores; os testes usam um relógio manual
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// RealClock usa o relógio do sistema
+type RealClock struct{}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+func (RealClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t realTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+// Scheduler executa fire para cada id no seu prazo. Os prazos ficam em um
+// heap mínimo e a goroutine de Run dorme até o mais próximo, então o custo de
+// cada agendamento é O(log n) independentemente de quantos prazos existam.
+type Scheduler struct {
+	clock Clock
+	fire  func(id string)
+
+	mutex sync.Mutex
+	items deadlineHeap
+	index map[string]*deadline
+	// wake acorda Run quando o prazo mais próximo pode ter mudado
+	wake chan struct{}
+}
+
+func New(clock Clock, fire func(id string)) *Scheduler {
+	return &Scheduler{
+		clock: clock,
+		fire:  fire,
+		index: make(map[string]*deadline),
+		wake:  make(chan struct{}, 1),
+	}
+}
+
+// Schedule agenda id para at; se id já estiver agendado, o prazo é substituído
+func (s *Scheduler) Schedule(id string, at time.Time) {
+	s.mutex.Lock()
+	if item, ok := s.index[id]; ok {
+		item.at = at
+		heap.Fix(&s.items, item.position)
+	} else {
+		item := &deadline{id: id, at: at}
+		heap.Push(&s.items, item)
+		s.index[id] = item
+	}
+	s.mutex.Unlock()
+
+	s.notify()
+}
+
+// Cancel remove o agendamento de id e informa se ele existia
+func (s *Scheduler) Cancel(id string) bool {
+	s.mutex.Lock()
+	item, ok := s.index[id]
+	if ok {
+		heap.Remove(&s.items, item.position)
+		delete(s.index, id)
+	}
+	s.mutex.Unlock()
+
+	if ok {
+		s.notify()
+	}
+	return ok
+}
+
+// Deadline retorna o prazo agendado para id
+func (s *Scheduler) Deadline(id string) (time.Time, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	item, ok := s.index[id]
+	if !ok {
+		return time.Time{}, false
+	}
+	return item.at, true
+}
+
+// Len retorna quantos prazos estão agendados
+func (s *Scheduler) Len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.items)
+}
+
+// Run executa os prazos vencidos, em ordem, até stop ser fechado. fire é
+// chamado sem lock, então pode agendar de novo o mesmo id (por exemplo, para
+// tentar outra vez após uma falha).
+func (s *Scheduler) Run(stop <-chan struct{}) {
+	for {
+		id, wait, due := s.next()
+		if due {
+			s.fire(id)
+
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			continue
+		}
+
+		var timer Timer
+		var timerC <-chan time.Time
+		if wait >= 0 {
+			timer = s.clock.NewTimer(wait)
+			timerC = timer.C()
+		}
+
+		select {
+		case <-timerC:
+		case <-s.wake:
+		case <-stop:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// next remove e retorna o primeiro prazo se ele já venceu; caso contrário
+// retorna quanto falta para ele, ou -1 se não houver prazos
+func (s *Scheduler) next() (string, time.Duration, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.items) == 0 {
+		return "", -1, false
+	}
+
+	item := s.items[0]
+	wait := item.at.Sub(s.clock.Now())
+	if wait > 0 {
+		return "", wait, false
+	}
+
+	heap.Pop(&s.items)
+	delete(s.index, item.id)
+	return item.id, 0, true
+}
+
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+type deadline struct {
+	id       string
+	at       time.Time
+	position int
+}
+
+// deadlineHeap implementa heap.Interface ordenando pelo prazo
+type deadlineHeap []*deadline
+
+func (h deadlineHeap) Len() int { return len(h) }
+
+func (h deadlineHeap) Less(i, j int) bool { return h[i].at.Before(h[j].at) }
+
+func (h deadlineHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].position = i
+	h[j].position = j
+}
+
+func (h *deadlineHeap) Push(x any) {
+	item := x.(*deadline)
+	item.position = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *deadlineHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return item
+}
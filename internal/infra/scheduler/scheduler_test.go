@@ -0,0 +1,175 @@
+package scheduler
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// manualClock só avança quando o teste chama advance
+type manualClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*manualTimer
+}
+
+type manualTimer struct {
+	clock *manualClock
+	at    time.Time
+	c     chan time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *manualClock) NewTimer(d time.Duration) Timer {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	timer := &manualTimer{clock: c, at: c.now.Add(d), c: make(chan time.Time, 1)}
+	c.timers = append(c.timers, timer)
+	return timer
+}
+
+func (c *manualClock) advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.at.After(c.now) {
+			pending = append(pending, timer)
+			continue
+		}
+		timer.c <- c.now
+	}
+	c.timers = pending
+}
+
+// waitForTimer espera o scheduler dormir até at, para que advance não
+// aconteça antes de o temporizador existir
+func (c *manualClock) waitForTimer(t *testing.T, at time.Time) {
+	require.Eventually(t, func() bool {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		for _, timer := range c.timers {
+			if timer.at.Equal(at) {
+				return true
+			}
+		}
+		return false
+	}, time.Second, time.Millisecond)
+}
+
+func (t *manualTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *manualTimer) Stop() bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+
+	for i, timer := range t.clock.timers {
+		if timer == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func startScheduler(t *testing.T, clock *manualClock) (*Scheduler, chan string) {
+	fired := make(chan string, 10)
+	s := New(clock, func(id string) { fired <- id })
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		s.Run(stop)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		close(stop)
+		<-done
+	})
+
+	return s, fired
+}
+
+func expectFired(t *testing.T, fired chan string, id string) {
+	select {
+	case got := <-fired:
+		assert.Equal(t, id, got)
+	case <-time.After(time.Second):
+		t.Fatalf("expected %s to fire", id)
+	}
+}
+
+func expectNothingFired(t *testing.T, fired chan string) {
+	select {
+	case got := <-fired:
+		t.Fatalf("unexpected fire of %s", got)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestSchedulerFiresInDeadlineOrder(t *testing.T) {
+	clock := &manualClock{now: time.Now()}
+	s, fired := startScheduler(t, clock)
+	start := clock.Now()
+
+	s.Schedule("late", start.Add(3*time.Second))
+	s.Schedule("early", start.Add(time.Second))
+
+	clock.waitForTimer(t, start.Add(time.Second))
+	expectNothingFired(t, fired)
+
+	clock.advance(time.Second)
+	expectFired(t, fired, "early")
+
+	clock.waitForTimer(t, start.Add(3*time.Second))
+	clock.advance(2 * time.Second)
+	expectFired(t, fired, "late")
+	assert.Equal(t, 0, s.Len())
+}
+
+func TestSchedulerReschedulesAndCancels(t *testing.T) {
+	clock := &manualClock{now: time.Now()}
+	s, fired := startScheduler(t, clock)
+	start := clock.Now()
+
+	s.Schedule("extended", start.Add(time.Second))
+	s.Schedule("cancelled", start.Add(2*time.Second))
+
+	// Uma prorrogação substitui o prazo em vez de criar um segundo
+	s.Schedule("extended", start.Add(5*time.Second))
+	deadline, ok := s.Deadline("extended")
+	assert.True(t, ok)
+	assert.Equal(t, start.Add(5*time.Second), deadline)
+
+	assert.True(t, s.Cancel("cancelled"))
+	assert.False(t, s.Cancel("cancelled"))
+	assert.Equal(t, 1, s.Len())
+
+	clock.waitForTimer(t, start.Add(5*time.Second))
+	clock.advance(4 * time.Second)
+	expectNothingFired(t, fired)
+
+	clock.advance(time.Second)
+	expectFired(t, fired, "extended")
+}
+
+func TestSchedulerFiresPastDeadlinesImmediately(t *testing.T) {
+	clock := &manualClock{now: time.Now()}
+	s, fired := startScheduler(t, clock)
+
+	s.Schedule("expired", clock.Now().Add(-time.Minute))
+	expectFired(t, fired, "expired")
+}
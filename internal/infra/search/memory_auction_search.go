@@ -0,0 +1,79 @@
+package search
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/pagination_entity"
+	"fullcycle-auction_go/internal/entity/search_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sort"
+)
+
+// MaxScannedAuctions limita quantos leilões a busca em memória percorre
+const MaxScannedAuctions = 5000
+
+// AuctionLister é a parte do repositório de leilões usada pela busca em memória
+type AuctionLister interface {
+	FindAuctions(
+		ctx context.Context,
+		filter auction_entity.AuctionFilter,
+		page pagination_entity.PageRequest) (*auction_entity.AuctionPage, *internal_error.InternalError)
+}
+
+// MemoryAuctionSearch implementa a busca textual em memória sobre qualquer
+// repositório de leilões, para backends que não oferecem índice textual
+type MemoryAuctionSearch struct {
+	repository AuctionLister
+}
+
+func NewMemoryAuctionSearch(repository AuctionLister) *MemoryAuctionSearch {
+	return &MemoryAuctionSearch{
+		repository: repository,
+	}
+}
+
+func (ms *MemoryAuctionSearch) SearchAuctions(
+	ctx context.Context,
+	query *search_entity.Query,
+	filter auction_entity.AuctionFilter,
+	limit int) ([]auction_entity.AuctionSearchHit, *internal_error.InternalError) {
+	var hits []auction_entity.AuctionSearchHit
+
+	page := pagination_entity.PageRequest{Limit: pagination_entity.MaxLimit}
+	for scanned := 0; scanned < MaxScannedAuctions; {
+		auctionPage, err := ms.repository.FindAuctions(ctx, filter, page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, auction := range auctionPage.Auctions {
+			fields := auction.SearchFields()
+			if score := query.Score(fields); score > 0 {
+				hits = append(hits, auction_entity.AuctionSearchHit{
+					Auction:    auction,
+					Score:      score,
+					Highlights: query.Highlights(fields),
+				})
+			}
+		}
+
+		scanned += len(auctionPage.Auctions)
+		if auctionPage.NextToken == "" {
+			break
+		}
+		page.Token = auctionPage.NextToken
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score == hits[j].Score {
+			return hits[i].Auction.Id < hits[j].Auction.Id
+		}
+		return hits[i].Score > hits[j].Score
+	})
+
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	return hits, nil
+}
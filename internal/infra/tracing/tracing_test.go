@@ -0,0 +1,24 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetupWithoutExporterKeepsTracingDisabled(t *testing.T) {
+	shutdown, err := Setup(context.Background(), ExporterNone, "fullcycle-auction")
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	_, span := Start(context.Background(), "test")
+	defer span.End()
+	assert.False(t, span.SpanContext().IsValid())
+}
+
+func TestSetupRejectsUnknownExporter(t *testing.T) {
+	_, err := Setup(context.Background(), "zipkin", "fullcycle-auction")
+	assert.ErrorContains(t, err, "invalid trace exporter")
+}
@@ -0,0 +1,73 @@
+package wal
+
+import (
+	"encoding/json"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+
+	"go.uber.org/zap"
+)
+
+// BidLog guarda no log os lances aceitos e ainda não gravados no banco
+type BidLog struct {
+	log *Log
+}
+
+// OpenBidLog abre o log no diretório dir, com segmentos de até segmentSize bytes
+func OpenBidLog(dir string, segmentSize int64) (*BidLog, error) {
+	log, err := Open(dir, segmentSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBidLog(log), nil
+}
+
+func NewBidLog(log *Log) *BidLog {
+	return &BidLog{log: log}
+}
+
+func (bl *BidLog) Append(bid bid_entity.Bid) *internal_error.InternalError {
+	data, err := json.Marshal(bid)
+	if err != nil {
+		logger.Error("Error trying to encode bid for the write-ahead log", err)
+		return internal_error.NewInternalServerError("Error trying to log bid")
+	}
+
+	if err := bl.log.Append(bid.Id, data); err != nil {
+		logger.Error("Error trying to append bid to the write-ahead log", err)
+		return internal_error.NewInternalServerError("Error trying to log bid")
+	}
+
+	return nil
+}
+
+func (bl *BidLog) Ack(bidIds ...string) *internal_error.InternalError {
+	if err := bl.log.Ack(bidIds...); err != nil {
+		logger.Error("Error trying to acknowledge bids in the write-ahead log", err)
+		return internal_error.NewInternalServerError("Error trying to acknowledge logged bids")
+	}
+
+	return nil
+}
+
+func (bl *BidLog) PendingBids() ([]bid_entity.Bid, *internal_error.InternalError) {
+	entries := bl.log.Recovered()
+
+	bids := make([]bid_entity.Bid, 0, len(entries))
+	for _, entry := range entries {
+		var bid bid_entity.Bid
+		if err := json.Unmarshal(entry.Data, &bid); err != nil {
+			logger.Error("Error trying to decode bid from the write-ahead log", err, zap.String("bid_id", entry.Id))
+			return nil, internal_error.NewInternalServerError("Error trying to read logged bids")
+		}
+		bids = append(bids, bid)
+	}
+
+	return bids, nil
+}
+
+func (bl *BidLog) Close() error {
+	return bl.log.Close()
+}
@@ -0,0 +1,392 @@
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	segmentSuffix = ".wal"
+	// headerSize é o tamanho do cabeçalho de cada registro: comprimento e CRC32
+	headerSize = 8
+	// maxRecordSize protege a leitura de um comprimento corrompido
+	maxRecordSize = 16 << 20
+
+	recordEntry = "entry"
+	recordAck   = "ack"
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Entry é um registro pendente: gravado no log e ainda não confirmado
+type Entry struct {
+	Id   string
+	Data []byte
+}
+
+type record struct {
+	Type string          `json:"type"`
+	Id   string          `json:"id,omitempty"`
+	Data json.RawMessage `json:"data,omitempty"`
+	Acks []string        `json:"acks,omitempty"`
+}
+
+type segment struct {
+	id      uint64
+	path    string
+	size    int64
+	pending int
+}
+
+// Log é um log local somente de acréscimo, dividido em segmentos. Cada entrada
+// é sincronizada com o disco antes de Append retornar e permanece pendente até
+// ser confirmada com Ack. Os segmentos mais antigos são apagados assim que
+// todas as suas entradas são confirmadas.
+type Log struct {
+	mutex          sync.Mutex
+	dir            string
+	maxSegmentSize int64
+	segments       []*segment
+	active         *os.File
+	// pending associa cada entrada não confirmada ao segmento onde foi gravada
+	pending map[string]*segment
+	// recovered são as entradas pendentes encontradas na abertura do log
+	recovered []Entry
+}
+
+// Open lê os segmentos existentes em dir, recupera as entradas não
+// confirmadas e abre um novo segmento para as próximas gravações
+func Open(dir string, maxSegmentSize int64) (*Log, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	l := &Log{
+		dir:            dir,
+		maxSegmentSize: maxSegmentSize,
+		pending:        make(map[string]*segment),
+	}
+
+	ids, err := l.segmentIds()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]Entry)
+	var order []string
+	for i, id := range ids {
+		seg := &segment{id: id, path: l.segmentPath(id)}
+		l.segments = append(l.segments, seg)
+
+		last := i == len(ids)-1
+		if err := l.readSegment(seg, last, func(rec record) {
+			switch rec.Type {
+			case recordEntry:
+				if _, ok := entries[rec.Id]; !ok {
+					order = append(order, rec.Id)
+				}
+				entries[rec.Id] = Entry{Id: rec.Id, Data: rec.Data}
+				l.pending[rec.Id] = seg
+				seg.pending++
+			case recordAck:
+				l.acknowledge(rec.Acks)
+			}
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, id := range order {
+		if _, ok := l.pending[id]; ok {
+			l.recovered = append(l.recovered, entries[id])
+		}
+	}
+
+	var next uint64 = 1
+	if len(ids) > 0 {
+		next = ids[len(ids)-1] + 1
+	}
+	if err := l.openSegment(next); err != nil {
+		return nil, err
+	}
+
+	l.compact()
+	return l, nil
+}
+
+// Recovered retorna as entradas pendentes encontradas na abertura, na ordem
+// em que foram gravadas
+func (l *Log) Recovered() []Entry {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	recovered := make([]Entry, 0, len(l.recovered))
+	for _, entry := range l.recovered {
+		if _, ok := l.pending[entry.Id]; ok {
+			recovered = append(recovered, entry)
+		}
+	}
+	return recovered
+}
+
+// Append grava a entrada e só retorna depois da sincronização com o disco
+func (l *Log) Append(id string, data []byte) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.active == nil {
+		return errors.New("write-ahead log is closed")
+	}
+
+	seg := l.segments[len(l.segments)-1]
+	if seg.size >= l.maxSegmentSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+		seg = l.segments[len(l.segments)-1]
+	}
+
+	if err := l.write(record{Type: recordEntry, Id: id, Data: data}); err != nil {
+		return err
+	}
+
+	if previous, ok := l.pending[id]; ok {
+		previous.pending--
+	}
+	l.pending[id] = seg
+	seg.pending++
+	return nil
+}
+
+// Ack confirma as entradas informadas; ids desconhecidos ou já confirmados são
+// ignorados
+func (l *Log) Ack(ids ...string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.active == nil {
+		return errors.New("write-ahead log is closed")
+	}
+
+	acks := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := l.pending[id]; ok {
+			acks = append(acks, id)
+		}
+	}
+	if len(acks) == 0 {
+		return nil
+	}
+
+	if err := l.write(record{Type: recordAck, Acks: acks}); err != nil {
+		return err
+	}
+
+	l.acknowledge(acks)
+	l.compact()
+	return nil
+}
+
+func (l *Log) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.active == nil {
+		return nil
+	}
+
+	err := l.active.Close()
+	l.active = nil
+	return err
+}
+
+func (l *Log) acknowledge(ids []string) {
+	for _, id := range ids {
+		if seg, ok := l.pending[id]; ok {
+			seg.pending--
+			delete(l.pending, id)
+		}
+	}
+}
+
+// compact apaga, do mais antigo para o mais novo, os segmentos sem entradas
+// pendentes. A ordem importa: uma confirmação só se refere a entradas do
+// próprio segmento ou de segmentos anteriores. Quando nada está pendente, o
+// segmento ativo também é substituído por um novo, vazio.
+func (l *Log) compact() {
+	for len(l.segments) > 1 && l.segments[0].pending == 0 {
+		os.Remove(l.segments[0].path)
+		l.segments = l.segments[1:]
+	}
+
+	if len(l.segments) == 1 && l.segments[0].pending == 0 && l.segments[0].size > 0 && l.active != nil {
+		if err := l.rotate(); err != nil {
+			return
+		}
+		os.Remove(l.segments[0].path)
+		l.segments = l.segments[1:]
+	}
+}
+
+func (l *Log) rotate() error {
+	next := l.segments[len(l.segments)-1].id + 1
+	if err := l.active.Close(); err != nil {
+		return err
+	}
+	l.active = nil
+	return l.openSegment(next)
+}
+
+func (l *Log) openSegment(id uint64) error {
+	path := l.segmentPath(id)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if err := syncDir(l.dir); err != nil {
+		file.Close()
+		return err
+	}
+
+	l.active = file
+	l.segments = append(l.segments, &segment{id: id, path: path})
+	return nil
+}
+
+func (l *Log) write(rec record) error {
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	buffer := make([]byte, headerSize+len(payload))
+	binary.BigEndian.PutUint32(buffer[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(buffer[4:8], crc32.Checksum(payload, crcTable))
+	copy(buffer[headerSize:], payload)
+
+	if _, err := l.active.Write(buffer); err != nil {
+		return err
+	}
+	if err := l.active.Sync(); err != nil {
+		return err
+	}
+
+	l.segments[len(l.segments)-1].size += int64(len(buffer))
+	return nil
+}
+
+// readSegment percorre os registros do segmento. Um registro incompleto ou
+// corrompido no fim do último segmento é resultado de uma queda no meio da
+// gravação, nunca confirmada ao cliente, e é descartado.
+func (l *Log) readSegment(seg *segment, last bool, handle func(record)) error {
+	file, err := os.Open(seg.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var offset int64
+	for {
+		rec, size, err := readRecord(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if !last {
+				return fmt.Errorf("corrupted write-ahead log segment %s at offset %d: %w", seg.path, offset, err)
+			}
+			if err := os.Truncate(seg.path, offset); err != nil {
+				return err
+			}
+			break
+		}
+
+		handle(rec)
+		offset += size
+	}
+
+	seg.size = offset
+	return nil
+}
+
+func readRecord(reader io.Reader) (record, int64, error) {
+	var rec record
+
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		if err == io.EOF {
+			return rec, 0, io.EOF
+		}
+		return rec, 0, io.ErrUnexpectedEOF
+	}
+
+	length := binary.BigEndian.Uint32(header[0:4])
+	if length > maxRecordSize {
+		return rec, 0, errors.New("record too large")
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return rec, 0, io.ErrUnexpectedEOF
+	}
+
+	if crc32.Checksum(payload, crcTable) != binary.BigEndian.Uint32(header[4:8]) {
+		return rec, 0, errors.New("checksum mismatch")
+	}
+
+	if err := json.Unmarshal(payload, &rec); err != nil {
+		return rec, 0, err
+	}
+
+	return rec, int64(headerSize + len(payload)), nil
+}
+
+func (l *Log) segmentIds() ([]uint64, error) {
+	files, err := os.ReadDir(l.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uint64
+	for _, file := range files {
+		name, found := strings.CutSuffix(file.Name(), segmentSuffix)
+		if !found || file.IsDir() {
+			continue
+		}
+		id, err := strconv.ParseUint(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+func (l *Log) segmentPath(id uint64) string {
+	return filepath.Join(l.dir, fmt.Sprintf("%016d%s", id, segmentSuffix))
+}
+
+// syncDir garante que a criação de um segmento sobreviva a uma queda
+func syncDir(dir string) error {
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return file.Sync()
+}
@@ -0,0 +1,104 @@
+package wal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func recoveredIds(l *Log) []string {
+	var ids []string
+	for _, entry := range l.Recovered() {
+		ids = append(ids, entry.Id)
+	}
+	return ids
+}
+
+func segmentFiles(t *testing.T, dir string) []string {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+segmentSuffix))
+	require.NoError(t, err)
+	return files
+}
+
+func TestLogRecoversUnacknowledgedEntries(t *testing.T) {
+	dir := t.TempDir()
+
+	l, err := Open(dir, 1<<20)
+	require.NoError(t, err)
+	require.NoError(t, l.Append("bid-1", []byte(`{"amount":1}`)))
+	require.NoError(t, l.Append("bid-2", []byte(`{"amount":2}`)))
+	require.NoError(t, l.Append("bid-3", []byte(`{"amount":3}`)))
+	require.NoError(t, l.Ack("bid-2"))
+	require.NoError(t, l.Close())
+
+	reopened, err := Open(dir, 1<<20)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	recovered := reopened.Recovered()
+	require.Len(t, recovered, 2)
+	assert.Equal(t, "bid-1", recovered[0].Id)
+	assert.Equal(t, `{"amount":1}`, string(recovered[0].Data))
+	assert.Equal(t, "bid-3", recovered[1].Id)
+
+	// Confirmações feitas depois da abertura valem para as entradas recuperadas
+	require.NoError(t, reopened.Ack("bid-1"))
+	assert.Equal(t, []string{"bid-3"}, recoveredIds(reopened))
+}
+
+func TestLogCompactsAcknowledgedSegments(t *testing.T) {
+	dir := t.TempDir()
+
+	// Segmentos minúsculos: cada entrada ocupa um segmento
+	l, err := Open(dir, 1)
+	require.NoError(t, err)
+	for _, id := range []string{"bid-1", "bid-2", "bid-3"} {
+		require.NoError(t, l.Append(id, []byte(`{}`)))
+	}
+	assert.Len(t, segmentFiles(t, dir), 3)
+
+	// Só os segmentos mais antigos, totalmente confirmados, são apagados
+	require.NoError(t, l.Ack("bid-2"))
+	assert.Len(t, segmentFiles(t, dir), 3)
+	require.NoError(t, l.Ack("bid-1"))
+	assert.Len(t, segmentFiles(t, dir), 1)
+
+	// Sem nada pendente, o log volta a ter um único segmento vazio
+	require.NoError(t, l.Ack("bid-3"))
+	files := segmentFiles(t, dir)
+	require.Len(t, files, 1)
+	info, err := os.Stat(files[0])
+	require.NoError(t, err)
+	assert.Zero(t, info.Size())
+	require.NoError(t, l.Close())
+
+	reopened, err := Open(dir, 1)
+	require.NoError(t, err)
+	defer reopened.Close()
+	assert.Empty(t, reopened.Recovered())
+}
+
+func TestLogDiscardsTornTail(t *testing.T) {
+	dir := t.TempDir()
+
+	l, err := Open(dir, 1<<20)
+	require.NoError(t, err)
+	require.NoError(t, l.Append("bid-1", []byte(`{}`)))
+	require.NoError(t, l.Append("bid-2", []byte(`{}`)))
+	require.NoError(t, l.Close())
+
+	// Simula uma queda no meio da gravação do último registro
+	files := segmentFiles(t, dir)
+	require.Len(t, files, 1)
+	info, err := os.Stat(files[0])
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(files[0], info.Size()-3))
+
+	reopened, err := Open(dir, 1<<20)
+	require.NoError(t, err)
+	defer reopened.Close()
+	assert.Equal(t, []string{"bid-1"}, recoveredIds(reopened))
+}
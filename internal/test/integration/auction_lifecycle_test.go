@@ -47,16 +47,12 @@ func TestSimpleAuctionLifecycleIntegration(t *testing.T) {
 		t.Skip("Skipping integration test in short mode")
 	}
 
-	// Configurar um intervalo de leilão curto para testes
-	os.Setenv("AUCTION_INTERVAL", "3s")
-	defer os.Unsetenv("AUCTION_INTERVAL")
-
 	// Configurar o banco de dados
 	db, cleanup := setupTestDatabase(t)
 	defer cleanup()
 
 	// Inicializar repositórios
-	auctionRepo := auction.NewAuctionRepository(db)
+	auctionRepo := auction.NewAuctionRepository(db, auction.AuctionRepositoryConfig{AuctionDuration: 3 * time.Second})
 	bidRepo := bid.NewBidRepository(db, auctionRepo)
 	defer auctionRepo.Cleanup()
 
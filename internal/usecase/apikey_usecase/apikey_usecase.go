@@ -0,0 +1,268 @@
+package apikey_usecase
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/apikey_entity"
+	"fullcycle-auction_go/internal/infra/tracing"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// MaxActiveAPIKeysPerUser limita as chaves ativas de cada usuário
+const MaxActiveAPIKeysPerUser = 20
+
+// DefaultRotationGracePeriod é o tempo em que a chave antiga continua válida após a rotação
+const DefaultRotationGracePeriod = 24 * time.Hour
+
+type CreateAPIKeyInputDTO struct {
+	Name   string   `json:"name" binding:"required,min=3,max=100"`
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,oneof=auctions:read auctions:write bids:read bids:write"`
+	// ExpiresIn é uma duração no formato do Go (ex.: "720h"); vazio para não expirar
+	ExpiresIn string `json:"expires_in"`
+}
+
+type RotateAPIKeyInputDTO struct {
+	// GracePeriod é quanto tempo a chave antiga continua válida (padrão 24h, "0s" para encerrar na hora)
+	GracePeriod string `json:"grace_period"`
+}
+
+type APIKeyOutputDTO struct {
+	Id         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	Active     bool       `json:"active"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreatedAPIKeyOutputDTO é o único momento em que o segredo é exibido
+type CreatedAPIKeyOutputDTO struct {
+	APIKeyOutputDTO
+	Secret string `json:"secret"`
+}
+
+type APIKeyUseCaseInterface interface {
+	CreateAPIKey(
+		ctx context.Context,
+		userId string,
+		input CreateAPIKeyInputDTO) (*CreatedAPIKeyOutputDTO, *internal_error.InternalError)
+
+	FindAPIKeys(
+		ctx context.Context, userId string) ([]APIKeyOutputDTO, *internal_error.InternalError)
+
+	RevokeAPIKey(
+		ctx context.Context, userId, keyId string) *internal_error.InternalError
+
+	RotateAPIKey(
+		ctx context.Context,
+		userId, keyId string,
+		input RotateAPIKeyInputDTO) (*CreatedAPIKeyOutputDTO, *internal_error.InternalError)
+}
+
+type APIKeyUseCase struct {
+	apiKeyRepositoryInterface apikey_entity.APIKeyRepositoryInterface
+}
+
+func NewAPIKeyUseCase(
+	apiKeyRepositoryInterface apikey_entity.APIKeyRepositoryInterface) APIKeyUseCaseInterface {
+	return &APIKeyUseCase{
+		apiKeyRepositoryInterface: apiKeyRepositoryInterface,
+	}
+}
+
+func (au *APIKeyUseCase) CreateAPIKey(
+	ctx context.Context,
+	userId string,
+	input CreateAPIKeyInputDTO) (*CreatedAPIKeyOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "APIKeyUseCase.CreateAPIKey")
+	defer span.End()
+
+	var expiresAt *time.Time
+	if input.ExpiresIn != "" {
+		duration, err := time.ParseDuration(input.ExpiresIn)
+		if err != nil || duration <= 0 {
+			return nil, internal_error.NewBadRequestError("expires_in must be a positive duration such as 720h")
+		}
+		expiration := time.Now().Add(duration)
+		expiresAt = &expiration
+	}
+
+	scopes := make([]apikey_entity.Scope, 0, len(input.Scopes))
+	for _, scope := range input.Scopes {
+		scopes = append(scopes, apikey_entity.Scope(scope))
+	}
+
+	return au.createAPIKey(ctx, userId, input.Name, scopes, expiresAt)
+}
+
+func (au *APIKeyUseCase) FindAPIKeys(
+	ctx context.Context, userId string) ([]APIKeyOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "APIKeyUseCase.FindAPIKeys")
+	defer span.End()
+
+	apiKeys, err := au.apiKeyRepositoryInterface.FindAPIKeysByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	outputs := make([]APIKeyOutputDTO, 0, len(apiKeys))
+	for i := range apiKeys {
+		outputs = append(outputs, toAPIKeyOutputDTO(&apiKeys[i], now))
+	}
+
+	return outputs, nil
+}
+
+func (au *APIKeyUseCase) RevokeAPIKey(
+	ctx context.Context, userId, keyId string) *internal_error.InternalError {
+	ctx, span := tracing.Start(ctx, "APIKeyUseCase.RevokeAPIKey")
+	defer span.End()
+
+	apiKey, err := au.findOwnedAPIKey(ctx, userId, keyId)
+	if err != nil {
+		return err
+	}
+
+	if apiKey.RevokedAt != nil {
+		return nil
+	}
+
+	if err := au.apiKeyRepositoryInterface.RevokeAPIKey(ctx, keyId, time.Now()); err != nil {
+		return err
+	}
+
+	logger.InfoContext(ctx, "API key revoked", zap.String("api_key_id", keyId))
+	return nil
+}
+
+// RotateAPIKey cria uma chave com o mesmo nome, escopos e validade restante, e
+// faz a chave antiga expirar após o período de carência, para que o cliente
+// possa trocar o segredo sem interrupção
+func (au *APIKeyUseCase) RotateAPIKey(
+	ctx context.Context,
+	userId, keyId string,
+	input RotateAPIKeyInputDTO) (*CreatedAPIKeyOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "APIKeyUseCase.RotateAPIKey")
+	defer span.End()
+
+	gracePeriod := DefaultRotationGracePeriod
+	if input.GracePeriod != "" {
+		duration, err := time.ParseDuration(input.GracePeriod)
+		if err != nil || duration < 0 || duration > 7*24*time.Hour {
+			return nil, internal_error.NewBadRequestError("grace_period must be a duration between 0s and 168h")
+		}
+		gracePeriod = duration
+	}
+
+	apiKey, err := au.findOwnedAPIKey(ctx, userId, keyId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !apiKey.IsActive(now) {
+		return nil, internal_error.NewBadRequestError("Only active API keys can be rotated")
+	}
+
+	// A nova chave herda a validade da antiga, se houver
+	var expiresAt *time.Time
+	if apiKey.ExpiresAt != nil {
+		expiration := now.Add(apiKey.ExpiresAt.Sub(apiKey.CreatedAt))
+		expiresAt = &expiration
+	}
+
+	created, err := au.createAPIKey(ctx, userId, apiKey.Name, apiKey.Scopes, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	oldExpiresAt := now.Add(gracePeriod)
+	if apiKey.ExpiresAt == nil || oldExpiresAt.Before(*apiKey.ExpiresAt) {
+		if err := au.apiKeyRepositoryInterface.SetAPIKeyExpiration(ctx, keyId, oldExpiresAt); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.InfoContext(ctx, "API key rotated", zap.String("api_key_id", keyId), zap.String("new_api_key_id", created.Id))
+	return created, nil
+}
+
+func (au *APIKeyUseCase) createAPIKey(
+	ctx context.Context,
+	userId, name string,
+	scopes []apikey_entity.Scope,
+	expiresAt *time.Time) (*CreatedAPIKeyOutputDTO, *internal_error.InternalError) {
+	existing, err := au.apiKeyRepositoryInterface.FindAPIKeysByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	active := 0
+	for i := range existing {
+		if existing[i].IsActive(now) {
+			active++
+		}
+	}
+	if active >= MaxActiveAPIKeysPerUser {
+		return nil, internal_error.NewBadRequestError(
+			fmt.Sprintf("A user can have at most %d active API keys", MaxActiveAPIKeysPerUser))
+	}
+
+	apiKey, secret, err := apikey_entity.CreateAPIKey(userId, name, scopes, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := au.apiKeyRepositoryInterface.CreateAPIKey(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	return &CreatedAPIKeyOutputDTO{
+		APIKeyOutputDTO: toAPIKeyOutputDTO(apiKey, now),
+		Secret:          secret,
+	}, nil
+}
+
+// findOwnedAPIKey responde "não encontrada" também para chaves de outros
+// usuários, para não revelar quais ids existem
+func (au *APIKeyUseCase) findOwnedAPIKey(
+	ctx context.Context, userId, keyId string) (*apikey_entity.APIKey, *internal_error.InternalError) {
+	apiKey, err := au.apiKeyRepositoryInterface.FindAPIKeyById(ctx, keyId)
+	if err != nil {
+		return nil, err
+	}
+
+	if apiKey.UserId != userId {
+		return nil, internal_error.NewNotFoundError("API key not found")
+	}
+
+	return apiKey, nil
+}
+
+func toAPIKeyOutputDTO(apiKey *apikey_entity.APIKey, now time.Time) APIKeyOutputDTO {
+	scopes := make([]string, 0, len(apiKey.Scopes))
+	for _, scope := range apiKey.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	return APIKeyOutputDTO{
+		Id:         apiKey.Id,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     scopes,
+		Active:     apiKey.IsActive(now),
+		CreatedAt:  apiKey.CreatedAt,
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		RevokedAt:  apiKey.RevokedAt,
+	}
+}
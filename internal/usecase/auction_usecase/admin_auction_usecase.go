@@ -0,0 +1,140 @@
+package auction_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/infra/tracing"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type CancelAuctionInputDTO struct {
+	Reason string `json:"reason" binding:"required,min=3,max=200"`
+}
+
+type ExtendAuctionInputDTO struct {
+	EndTime time.Time `json:"end_time" binding:"required"`
+}
+
+// RelistAuctionInputDTO define a duração do novo leilão; zero usa a duração
+// padrão da categoria ou, sem ela, a dos leilões
+type RelistAuctionInputDTO struct {
+	Duration time.Duration `json:"duration"`
+}
+
+// ForceCloseAuction encerra o leilão agora; o lance vencedor é escolhido
+// como num fechamento automático
+func (au *AuctionUseCase) ForceCloseAuction(
+	ctx context.Context,
+	actingUserId, auctionId string) (*AuctionOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "AuctionUseCase.ForceCloseAuction")
+	defer span.End()
+
+	auction, err := au.auctionRepositoryInterface.ForceCloseAuction(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.InfoContext(ctx, "Auction closed by admin", zap.String("auction_id", auctionId),
+		zap.String("acting_user_id", actingUserId))
+
+	auctionOutput := toAuctionOutputDTO(*auction)
+	return &auctionOutput, nil
+}
+
+// CancelAuction encerra o leilão sem vencedor
+func (au *AuctionUseCase) CancelAuction(
+	ctx context.Context,
+	actingUserId, auctionId string,
+	input CancelAuctionInputDTO) (*AuctionOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "AuctionUseCase.CancelAuction")
+	defer span.End()
+
+	if len(input.Reason) < 3 {
+		return nil, internal_error.NewBadRequestError("A cancellation reason is required")
+	}
+
+	auction, err := au.auctionRepositoryInterface.CancelAuction(ctx, auctionId, input.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.InfoContext(ctx, "Auction cancelled", zap.String("auction_id", auctionId),
+		zap.String("reason", input.Reason), zap.String("acting_user_id", actingUserId))
+
+	auctionOutput := toAuctionOutputDTO(*auction)
+	return &auctionOutput, nil
+}
+
+// ExtendAuction adia o término de um leilão ativo
+func (au *AuctionUseCase) ExtendAuction(
+	ctx context.Context,
+	actingUserId, auctionId string,
+	input ExtendAuctionInputDTO) (*AuctionOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "AuctionUseCase.ExtendAuction")
+	defer span.End()
+
+	auction, err := au.auctionRepositoryInterface.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auction.ValidateExtension(input.EndTime, time.Now()); err != nil {
+		return nil, err
+	}
+
+	extended, err := au.auctionRepositoryInterface.ExtendAuction(ctx, auctionId, input.EndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.InfoContext(ctx, "Auction extended", zap.String("auction_id", auctionId),
+		zap.Time("previous_end_time", auction.EndTime), zap.Time("end_time", input.EndTime),
+		zap.String("acting_user_id", actingUserId))
+
+	auctionOutput := toAuctionOutputDTO(*extended)
+	return &auctionOutput, nil
+}
+
+// RelistAuction cria um novo leilão com o produto de um leilão encerrado
+func (au *AuctionUseCase) RelistAuction(
+	ctx context.Context,
+	actingUserId, auctionId string,
+	input RelistAuctionInputDTO) (*AuctionOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "AuctionUseCase.RelistAuction")
+	defer span.End()
+
+	if input.Duration < 0 {
+		return nil, internal_error.NewBadRequestError("Duration must be positive")
+	}
+
+	original, err := au.auctionRepositoryInterface.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sem duração informada vale a duração padrão da categoria, como na criação
+	duration := input.Duration
+	if duration == 0 && original.CategoryId != "" {
+		if category, err := au.categoryRepositoryInterface.FindCategoryById(ctx, original.CategoryId); err == nil {
+			duration = category.DefaultDuration
+		}
+	}
+
+	relisted, err := original.Relist(duration)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := au.auctionRepositoryInterface.CreateAuction(ctx, relisted); err != nil {
+		return nil, err
+	}
+
+	logger.InfoContext(ctx, "Auction relisted", zap.String("auction_id", relisted.Id),
+		zap.String("relisted_from", auctionId), zap.String("acting_user_id", actingUserId))
+
+	auctionOutput := toAuctionOutputDTO(*relisted)
+	return &auctionOutput, nil
+}
@@ -0,0 +1,341 @@
+package auction_usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/infra/blobstore"
+	"fullcycle-auction_go/internal/infra/imaging"
+	"fullcycle-auction_go/internal/infra/tracing"
+	"fullcycle-auction_go/internal/internal_error"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type AuctionImageOutputDTO struct {
+	Id           string `json:"id"`
+	Position     int    `json:"position"`
+	ContentType  string `json:"content_type"`
+	Size         int64  `json:"size"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+}
+
+type ImageUploadInputDTO struct {
+	FileName string
+	Data     []byte
+}
+
+type ReorderImagesInputDTO struct {
+	ImageIds []string `json:"image_ids" binding:"required,min=1,dive,uuid"`
+}
+
+// ImageContentOutputDTO entrega o conteúdo de uma imagem; o chamador deve fechar Body
+type ImageContentOutputDTO struct {
+	Body        io.ReadCloser
+	ContentType string
+}
+
+type AuctionImageUseCaseInterface interface {
+	UploadImages(
+		ctx context.Context,
+		userId, auctionId string,
+		uploads []ImageUploadInputDTO) ([]AuctionImageOutputDTO, *internal_error.InternalError)
+
+	DeleteImage(
+		ctx context.Context, userId, auctionId, imageId string) *internal_error.InternalError
+
+	ReorderImages(
+		ctx context.Context,
+		userId, auctionId string,
+		input ReorderImagesInputDTO) ([]AuctionImageOutputDTO, *internal_error.InternalError)
+
+	OpenImage(
+		ctx context.Context,
+		auctionId, imageId string,
+		thumbnail bool) (*ImageContentOutputDTO, *internal_error.InternalError)
+
+	// MaxUploadSize é o maior corpo de requisição aceito em um upload
+	MaxUploadSize() int64
+}
+
+type AuctionImageUseCase struct {
+	auctionRepositoryInterface auction_entity.AuctionRepositoryInterface
+	blobStore                  blobstore.BlobStore
+
+	maxImageSize      int64
+	maxImages         int
+	maxPixels         int64
+	thumbnailMaxWidth int
+}
+
+// AuctionImageConfig limita as imagens aceitas em cada leilão
+type AuctionImageConfig struct {
+	// MaxImageSize é o maior arquivo aceito, em bytes
+	MaxImageSize int64
+	MaxImages    int
+	// MaxPixels é a maior quantidade de pixels (largura x altura) aceita
+	MaxPixels        int64
+	ThumbnailMaxSide int
+}
+
+func NewAuctionImageUseCase(
+	auctionRepositoryInterface auction_entity.AuctionRepositoryInterface,
+	blobStore blobstore.BlobStore,
+	config AuctionImageConfig) AuctionImageUseCaseInterface {
+	return &AuctionImageUseCase{
+		auctionRepositoryInterface: auctionRepositoryInterface,
+		blobStore:                  blobStore,
+		maxImageSize:               config.MaxImageSize,
+		maxImages:                  config.MaxImages,
+		maxPixels:                  config.MaxPixels,
+		thumbnailMaxWidth:          config.ThumbnailMaxSide,
+	}
+}
+
+func (iu *AuctionImageUseCase) MaxUploadSize() int64 {
+	// Margem para os cabeçalhos do multipart
+	return iu.maxImageSize*int64(iu.maxImages) + 1024*1024
+}
+
+func (iu *AuctionImageUseCase) UploadImages(
+	ctx context.Context,
+	userId, auctionId string,
+	uploads []ImageUploadInputDTO) ([]AuctionImageOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "AuctionImageUseCase.UploadImages")
+	defer span.End()
+
+	if len(uploads) == 0 {
+		return nil, internal_error.NewBadRequestError("At least one image is required")
+	}
+
+	auction, err := iu.findOwnedAuction(ctx, userId, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Valida todos os arquivos antes de gravar qualquer um
+	type preparedImage struct {
+		image     auction_entity.AuctionImage
+		data      []byte
+		thumbnail []byte
+	}
+	var prepared []preparedImage
+	for i, upload := range uploads {
+		if int64(len(upload.Data)) > iu.maxImageSize {
+			return nil, internal_error.NewBadRequestError(
+				fmt.Sprintf("Image %s exceeds the maximum size of %d bytes", upload.FileName, iu.maxImageSize))
+		}
+
+		contentType, allowed := imaging.DetectContentType(upload.Data)
+		if !allowed {
+			return nil, internal_error.NewBadRequestError(
+				fmt.Sprintf("Image %s has unsupported content type %s", upload.FileName, contentType))
+		}
+
+		thumbnail, thumbErr := imaging.MakeThumbnail(upload.Data, iu.thumbnailMaxWidth, iu.maxPixels)
+		if errors.Is(thumbErr, imaging.ErrTooManyPixels) {
+			return nil, internal_error.NewBadRequestError(
+				fmt.Sprintf("Image %s exceeds the maximum of %d pixels", upload.FileName, iu.maxPixels))
+		}
+		if thumbErr != nil {
+			return nil, internal_error.NewBadRequestError(
+				fmt.Sprintf("Image %s could not be decoded", upload.FileName))
+		}
+
+		imageId := uuid.New().String()
+		prepared = append(prepared, preparedImage{
+			image: auction_entity.AuctionImage{
+				Id:           imageId,
+				Position:     len(auction.Images) + i,
+				ContentType:  contentType,
+				Size:         int64(len(upload.Data)),
+				Width:        thumbnail.Width,
+				Height:       thumbnail.Height,
+				ObjectKey:    fmt.Sprintf("auctions/%s/%s/original", auctionId, imageId),
+				ThumbnailKey: fmt.Sprintf("auctions/%s/%s/thumbnail.jpg", auctionId, imageId),
+				UploadedAt:   time.Now(),
+			},
+			data:      upload.Data,
+			thumbnail: thumbnail.Data,
+		})
+	}
+
+	var images []auction_entity.AuctionImage
+	for _, item := range prepared {
+		images = append(images, item.image)
+
+		if err := iu.blobStore.Put(ctx, item.image.ObjectKey,
+			bytes.NewReader(item.data), int64(len(item.data)), item.image.ContentType); err != nil {
+			iu.deleteBlobs(ctx, images)
+			return nil, err
+		}
+
+		if err := iu.blobStore.Put(ctx, item.image.ThumbnailKey,
+			bytes.NewReader(item.thumbnail), int64(len(item.thumbnail)), "image/jpeg"); err != nil {
+			iu.deleteBlobs(ctx, images)
+			return nil, err
+		}
+	}
+
+	if err := iu.auctionRepositoryInterface.AddAuctionImages(ctx, auctionId, images, iu.maxImages); err != nil {
+		iu.deleteBlobs(ctx, images)
+		return nil, err
+	}
+
+	return toAuctionImageOutputDTOs(auctionId, images), nil
+}
+
+func (iu *AuctionImageUseCase) DeleteImage(
+	ctx context.Context, userId, auctionId, imageId string) *internal_error.InternalError {
+	ctx, span := tracing.Start(ctx, "AuctionImageUseCase.DeleteImage")
+	defer span.End()
+
+	auction, err := iu.findOwnedAuction(ctx, userId, auctionId)
+	if err != nil {
+		return err
+	}
+
+	image, found := auction.FindImage(imageId)
+	if !found {
+		return internal_error.NewNotFoundError("Image not found")
+	}
+
+	var remaining []auction_entity.AuctionImage
+	for _, other := range auction.Images {
+		if other.Id != imageId {
+			other.Position = len(remaining)
+			remaining = append(remaining, other)
+		}
+	}
+
+	if err := iu.auctionRepositoryInterface.UpdateAuctionImages(ctx, auctionId, remaining); err != nil {
+		return err
+	}
+
+	iu.deleteBlobs(ctx, []auction_entity.AuctionImage{*image})
+	return nil
+}
+
+// ReorderImages exige a lista completa de ids na nova ordem
+func (iu *AuctionImageUseCase) ReorderImages(
+	ctx context.Context,
+	userId, auctionId string,
+	input ReorderImagesInputDTO) ([]AuctionImageOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "AuctionImageUseCase.ReorderImages")
+	defer span.End()
+
+	auction, err := iu.findOwnedAuction(ctx, userId, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(input.ImageIds) != len(auction.Images) {
+		return nil, internal_error.NewBadRequestError("image_ids must list every image of the auction exactly once")
+	}
+
+	reordered := make([]auction_entity.AuctionImage, 0, len(input.ImageIds))
+	seen := make(map[string]bool)
+	for position, imageId := range input.ImageIds {
+		image, found := auction.FindImage(imageId)
+		if !found || seen[imageId] {
+			return nil, internal_error.NewBadRequestError("image_ids must list every image of the auction exactly once")
+		}
+		seen[imageId] = true
+
+		image.Position = position
+		reordered = append(reordered, *image)
+	}
+
+	if err := iu.auctionRepositoryInterface.UpdateAuctionImages(ctx, auctionId, reordered); err != nil {
+		return nil, err
+	}
+
+	return toAuctionImageOutputDTOs(auctionId, reordered), nil
+}
+
+func (iu *AuctionImageUseCase) OpenImage(
+	ctx context.Context,
+	auctionId, imageId string,
+	thumbnail bool) (*ImageContentOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "AuctionImageUseCase.OpenImage")
+	defer span.End()
+
+	auction, err := iu.auctionRepositoryInterface.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	image, found := auction.FindImage(imageId)
+	if !found {
+		return nil, internal_error.NewNotFoundError("Image not found")
+	}
+
+	key, contentType := image.ObjectKey, image.ContentType
+	if thumbnail {
+		key, contentType = image.ThumbnailKey, "image/jpeg"
+	}
+
+	body, err := iu.blobStore.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImageContentOutputDTO{
+		Body:        body,
+		ContentType: contentType,
+	}, nil
+}
+
+// findOwnedAuction busca o leilão e garante que o usuário é o vendedor
+func (iu *AuctionImageUseCase) findOwnedAuction(
+	ctx context.Context, userId, auctionId string) (*auction_entity.Auction, *internal_error.InternalError) {
+	auction, err := iu.auctionRepositoryInterface.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if auction.SellerId != userId {
+		return nil, internal_error.NewForbiddenError("Only the seller can change the auction images")
+	}
+
+	return auction, nil
+}
+
+// deleteBlobs remove os arquivos das imagens; falhas só são registradas, pois
+// um arquivo órfão não afeta o leilão
+func (iu *AuctionImageUseCase) deleteBlobs(ctx context.Context, images []auction_entity.AuctionImage) {
+	for _, image := range images {
+		for _, key := range []string{image.ObjectKey, image.ThumbnailKey} {
+			if err := iu.blobStore.Delete(ctx, key); err != nil {
+				logger.ErrorContext(ctx, "Error trying to delete image file", err, zap.String("blob_key", key))
+			}
+		}
+	}
+}
+
+func toAuctionImageOutputDTOs(auctionId string, images []auction_entity.AuctionImage) []AuctionImageOutputDTO {
+	outputs := make([]AuctionImageOutputDTO, 0, len(images))
+	for _, image := range images {
+		url := fmt.Sprintf("/auction/%s/images/%s", auctionId, image.Id)
+		outputs = append(outputs, AuctionImageOutputDTO{
+			Id:           image.Id,
+			Position:     image.Position,
+			ContentType:  image.ContentType,
+			Size:         image.Size,
+			Width:        image.Width,
+			Height:       image.Height,
+			URL:          url,
+			ThumbnailURL: url + "/thumbnail",
+		})
+	}
+	return outputs
+}
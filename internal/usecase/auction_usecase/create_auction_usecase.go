@@ -4,84 +4,198 @@ import (
 	"context"
 	"fullcycle-auction_go/internal/entity/auction_entity"
 	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/category_entity"
+	"fullcycle-auction_go/internal/infra/tracing"
 	"fullcycle-auction_go/internal/internal_error"
 	"fullcycle-auction_go/internal/usecase/bid_usecase"
 	"time"
 )
 
 type AuctionInputDTO struct {
+	// SellerId vem do token de acesso, nunca do corpo da requisição
+	SellerId    string           `json:"-"`
 	ProductName string           `json:"product_name" binding:"required,min=1"`
-	Category    string           `json:"category" binding:"required,min=2"`
+	CategoryId  string           `json:"category_id" binding:"required,uuid"`
 	Description string           `json:"description" binding:"required,min=10,max=200"`
 	Condition   ProductCondition `json:"condition" binding:"oneof=0 1 2"`
 }
 
 type AuctionOutputDTO struct {
-	Id          string           `json:"id"`
-	ProductName string           `json:"product_name"`
-	Category    string           `json:"category"`
-	Description string           `json:"description"`
-	Condition   ProductCondition `json:"condition"`
-	Status      AuctionStatus    `json:"status"`
-	Timestamp   time.Time        `json:"timestamp" time_format:"2006-01-02 15:04:05"`
+	Id           string                  `json:"id"`
+	SellerId     string                  `json:"seller_id,omitempty"`
+	ProductName  string                  `json:"product_name"`
+	CategoryId   string                  `json:"category_id,omitempty"`
+	Category     string                  `json:"category"`
+	Description  string                  `json:"description"`
+	Condition    ProductCondition        `json:"condition"`
+	Status       AuctionStatus           `json:"status"`
+	Timestamp    time.Time               `json:"timestamp" time_format:"2006-01-02 15:04:05"`
+	EndTime      time.Time               `json:"end_time" time_format:"2006-01-02 15:04:05"`
+	CurrentPrice float64                 `json:"current_price"`
+	BidCount     int64                   `json:"bid_count"`
+	Images       []AuctionImageOutputDTO `json:"images"`
+	CancelReason string                  `json:"cancel_reason,omitempty"`
+	CancelledAt  *time.Time              `json:"cancelled_at,omitempty"`
+	RelistedFrom string                  `json:"relisted_from,omitempty"`
+}
+
+// FindAuctionsInputDTO reúne os filtros, a ordenação e a paginação aceitos na
+// listagem de leilões via query string
+type FindAuctionsInputDTO struct {
+	Status AuctionStatus `form:"status" binding:"omitempty,oneof=0 1 2"`
+	// Statuses só é usado pela administração, já que na query string o status
+	// 0 (ativo) não filtra
+	Statuses []AuctionStatus `form:"-"`
+	Category string          `form:"category"`
+	// CategoryId inclui na busca todas as subcategorias da categoria informada
+	CategoryId  string           `form:"category_id" binding:"omitempty,uuid"`
+	ProductName string           `form:"productName"`
+	Condition   ProductCondition `form:"condition" binding:"omitempty,oneof=1 2 3"`
+	MinPrice    *float64         `form:"min_price" binding:"omitempty,gte=0"`
+	MaxPrice    *float64         `form:"max_price" binding:"omitempty,gte=0"`
+	EndsAfter   *time.Time       `form:"ends_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndsBefore  *time.Time       `form:"ends_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit       int              `form:"limit" binding:"omitempty,min=1,max=100"`
+	PageToken   string           `form:"page_token"`
+	Sort        string           `form:"sort" binding:"omitempty,oneof=created_at end_time current_price bid_count"`
+	Order       string           `form:"order" binding:"omitempty,oneof=asc desc"`
+}
+
+// SearchAuctionsInputDTO descreve a busca textual; status e category restringem
+// os resultados da mesma forma que na listagem
+type SearchAuctionsInputDTO struct {
+	Query      string        `form:"q" binding:"required,max=200"`
+	Status     AuctionStatus `form:"status" binding:"omitempty,oneof=0 1 2"`
+	Category   string        `form:"category"`
+	CategoryId string        `form:"category_id" binding:"omitempty,uuid"`
+	Limit      int           `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type AuctionSearchOutputDTO struct {
+	Auction AuctionOutputDTO `json:"auction"`
+	Score   float64          `json:"score"`
+	// Highlights traz, por campo, o texto com os termos encontrados entre <mark>
+	Highlights map[string]string `json:"highlights,omitempty"`
+}
+
+type AuctionSearchPageOutputDTO struct {
+	Query string                   `json:"query"`
+	Items []AuctionSearchOutputDTO `json:"items"`
+}
+
+type AuctionPageOutputDTO struct {
+	Items     []AuctionOutputDTO `json:"items"`
+	NextToken string             `json:"next_token,omitempty"`
+	Total     *int64             `json:"total,omitempty"`
 }
 
 type WinningInfoOutputDTO struct {
 	Auction AuctionOutputDTO          `json:"auction"`
 	Bid     *bid_usecase.BidOutputDTO `json:"bid,omitempty"`
+	// WinningRule documenta como empates entre lances de mesmo valor são decididos
+	WinningRule string `json:"winning_rule"`
 }
 
 func NewAuctionUseCase(
 	auctionRepositoryInterface auction_entity.AuctionRepositoryInterface,
-	bidRepositoryInterface bid_entity.BidEntityRepository) AuctionUseCaseInterface {
+	bidRepositoryInterface bid_entity.BidEntityRepository,
+	categoryRepositoryInterface category_entity.CategoryRepositoryInterface) AuctionUseCaseInterface {
 	return &AuctionUseCase{
-		auctionRepositoryInterface: auctionRepositoryInterface,
-		bidRepositoryInterface:     bidRepositoryInterface,
+		auctionRepositoryInterface:  auctionRepositoryInterface,
+		bidRepositoryInterface:      bidRepositoryInterface,
+		categoryRepositoryInterface: categoryRepositoryInterface,
 	}
 }
 
 type AuctionUseCaseInterface interface {
 	CreateAuction(
 		ctx context.Context,
-		auctionInput AuctionInputDTO) *internal_error.InternalError
+		auctionInput AuctionInputDTO) (*AuctionOutputDTO, *internal_error.InternalError)
 
 	FindAuctionById(
 		ctx context.Context, id string) (*AuctionOutputDTO, *internal_error.InternalError)
 
 	FindAuctions(
 		ctx context.Context,
-		status AuctionStatus,
-		category, productName string) ([]AuctionOutputDTO, *internal_error.InternalError)
+		input FindAuctionsInputDTO) (*AuctionPageOutputDTO, *internal_error.InternalError)
 
 	FindWinningBidByAuctionId(
 		ctx context.Context,
 		auctionId string) (*WinningInfoOutputDTO, *internal_error.InternalError)
+
+	SearchAuctions(
+		ctx context.Context,
+		input SearchAuctionsInputDTO) (*AuctionSearchPageOutputDTO, *internal_error.InternalError)
+
+	ForceCloseAuction(
+		ctx context.Context,
+		actingUserId, auctionId string) (*AuctionOutputDTO, *internal_error.InternalError)
+
+	CancelAuction(
+		ctx context.Context,
+		actingUserId, auctionId string,
+		input CancelAuctionInputDTO) (*AuctionOutputDTO, *internal_error.InternalError)
+
+	ExtendAuction(
+		ctx context.Context,
+		actingUserId, auctionId string,
+		input ExtendAuctionInputDTO) (*AuctionOutputDTO, *internal_error.InternalError)
+
+	RelistAuction(
+		ctx context.Context,
+		actingUserId, auctionId string,
+		input RelistAuctionInputDTO) (*AuctionOutputDTO, *internal_error.InternalError)
 }
 
 type ProductCondition int64
 type AuctionStatus int64
 
 type AuctionUseCase struct {
-	auctionRepositoryInterface auction_entity.AuctionRepositoryInterface
-	bidRepositoryInterface     bid_entity.BidEntityRepository
+	auctionRepositoryInterface  auction_entity.AuctionRepositoryInterface
+	bidRepositoryInterface      bid_entity.BidEntityRepository
+	categoryRepositoryInterface category_entity.CategoryRepositoryInterface
 }
 
 func (au *AuctionUseCase) CreateAuction(
 	ctx context.Context,
-	auctionInput AuctionInputDTO) *internal_error.InternalError {
+	auctionInput AuctionInputDTO) (*AuctionOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "AuctionUseCase.CreateAuction")
+	defer span.End()
+
+	category, err := au.categoryRepositoryInterface.FindCategoryById(ctx, auctionInput.CategoryId)
+	if err != nil {
+		if err.Err == "not_found" {
+			return nil, internal_error.NewBadRequestError("Category does not exist")
+		}
+		return nil, err
+	}
+
+	condition := auction_entity.ProductCondition(auctionInput.Condition)
+	if !category.AllowsCondition(condition) {
+		return nil, internal_error.NewBadRequestError("Condition is not allowed in this category")
+	}
+
 	auction, err := auction_entity.CreateAuction(
+		auctionInput.SellerId,
 		auctionInput.ProductName,
-		auctionInput.Category,
+		category.Id,
+		category.Name,
 		auctionInput.Description,
-		auction_entity.ProductCondition(auctionInput.Condition))
+		condition)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// A duração padrão da categoria substitui a duração global dos leilões
+	if category.DefaultDuration > 0 {
+		auction.EndTime = auction.Timestamp.Add(category.DefaultDuration)
 	}
 
 	if err := au.auctionRepositoryInterface.CreateAuction(
 		ctx, auction); err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	auctionOutput := toAuctionOutputDTO(*auction)
+	return &auctionOutput, nil
 }
@@ -4,78 +4,104 @@ import (
 	"context"
 	"fullcycle-auction_go/configuration/logger"
 	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/pagination_entity"
+	"fullcycle-auction_go/internal/infra/tracing"
 	"fullcycle-auction_go/internal/internal_error"
 	"fullcycle-auction_go/internal/usecase/bid_usecase"
+	"time"
 )
 
 func (au *AuctionUseCase) FindAuctionById(
 	ctx context.Context, id string) (*AuctionOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "AuctionUseCase.FindAuctionById")
+	defer span.End()
+
 	auctionEntity, err := au.auctionRepositoryInterface.FindAuctionById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	return &AuctionOutputDTO{
-		Id:          auctionEntity.Id,
-		ProductName: auctionEntity.ProductName,
-		Category:    auctionEntity.Category,
-		Description: auctionEntity.Description,
-		Condition:   ProductCondition(auctionEntity.Condition),
-		Status:      AuctionStatus(auctionEntity.Status),
-		Timestamp:   auctionEntity.Timestamp,
-	}, nil
+	auctionOutput := toAuctionOutputDTO(*auctionEntity)
+	return &auctionOutput, nil
 }
 
 func (au *AuctionUseCase) FindAuctions(
 	ctx context.Context,
-	status AuctionStatus,
-	category, productName string) ([]AuctionOutputDTO, *internal_error.InternalError) {
-	auctionEntities, err := au.auctionRepositoryInterface.FindAuctions(
-		ctx, auction_entity.AuctionStatus(status), category, productName)
+	input FindAuctionsInputDTO) (*AuctionPageOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "AuctionUseCase.FindAuctions")
+	defer span.End()
+
+	categoryIds, err := au.categoryTreeIds(ctx, input.CategoryId)
 	if err != nil {
 		return nil, err
 	}
 
-	var auctionOutputs []AuctionOutputDTO
-	for _, value := range auctionEntities {
-		auctionOutputs = append(auctionOutputs, AuctionOutputDTO{
-			Id:          value.Id,
-			ProductName: value.ProductName,
-			Category:    value.Category,
-			Description: value.Description,
-			Condition:   ProductCondition(value.Condition),
-			Status:      AuctionStatus(value.Status),
-			Timestamp:   value.Timestamp,
+	auctionFilter := auction_entity.AuctionFilter{
+		Status:      auction_entity.AuctionStatus(input.Status),
+		Statuses:    toAuctionStatuses(input.Statuses),
+		Category:    input.Category,
+		CategoryIds: categoryIds,
+		ProductName: input.ProductName,
+		Condition:   auction_entity.ProductCondition(input.Condition),
+		MinPrice:    input.MinPrice,
+		MaxPrice:    input.MaxPrice,
+		EndsAfter:   input.EndsAfter,
+		EndsBefore:  input.EndsBefore,
+	}
+
+	auctionPage, err := au.auctionRepositoryInterface.FindAuctions(
+		ctx, auctionFilter, pagination_entity.PageRequest{
+			Limit:      input.Limit,
+			Token:      input.PageToken,
+			SortBy:     input.Sort,
+			Descending: input.Order == "desc",
 		})
+	if err != nil {
+		return nil, err
 	}
 
-	return auctionOutputs, nil
+	auctionOutputs := make([]AuctionOutputDTO, 0, len(auctionPage.Auctions))
+	for _, value := range auctionPage.Auctions {
+		auctionOutputs = append(auctionOutputs, toAuctionOutputDTO(value))
+	}
+
+	return &AuctionPageOutputDTO{
+		Items:     auctionOutputs,
+		NextToken: auctionPage.NextToken,
+		Total:     auctionPage.Total,
+	}, nil
 }
 
 func (au *AuctionUseCase) FindWinningBidByAuctionId(
 	ctx context.Context,
 	auctionId string) (*WinningInfoOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "AuctionUseCase.FindWinningBidByAuctionId")
+	defer span.End()
+
 	auction, err := au.auctionRepositoryInterface.FindAuctionById(ctx, auctionId)
 	if err != nil {
 		return nil, err
 	}
 
-	auctionOutputDTO := AuctionOutputDTO{
-		Id:          auction.Id,
-		ProductName: auction.ProductName,
-		Category:    auction.Category,
-		Description: auction.Description,
-		Condition:   ProductCondition(auction.Condition),
-		Status:      AuctionStatus(auction.Status),
-		Timestamp:   auction.Timestamp,
+	auctionOutputDTO := toAuctionOutputDTO(*auction)
+
+	// Um leilão cancelado não tem vencedor, mesmo que tenha recebido lances
+	if auction.Status == auction_entity.Cancelled {
+		return &WinningInfoOutputDTO{
+			Auction:     auctionOutputDTO,
+			Bid:         nil,
+			WinningRule: bid_entity.WinningBidRule,
+		}, nil
 	}
 
 	bidWinning, err := au.bidRepositoryInterface.FindWinningBidByAuctionId(ctx, auction.Id)
 	if err != nil {
-		logger.Error("", err)
+		logger.ErrorContext(ctx, "", err)
 		return &WinningInfoOutputDTO{
-			Auction: auctionOutputDTO,
-			Bid:     nil,
+			Auction:     auctionOutputDTO,
+			Bid:         nil,
+			WinningRule: bid_entity.WinningBidRule,
 		}, nil
 	}
 
@@ -85,10 +111,76 @@ func (au *AuctionUseCase) FindWinningBidByAuctionId(
 		AuctionId: bidWinning.AuctionId,
 		Amount:    bidWinning.Amount,
 		Timestamp: bidWinning.Timestamp,
+		Sequence:  bidWinning.Sequence,
 	}
 
 	return &WinningInfoOutputDTO{
-		Auction: auctionOutputDTO,
-		Bid:     bidOutputDTO,
+		Auction:     auctionOutputDTO,
+		Bid:         bidOutputDTO,
+		WinningRule: bid_entity.WinningBidRule,
 	}, nil
 }
+
+func toAuctionOutputDTO(auctionEntity auction_entity.Auction) AuctionOutputDTO {
+	return AuctionOutputDTO{
+		Id:           auctionEntity.Id,
+		SellerId:     auctionEntity.SellerId,
+		ProductName:  auctionEntity.ProductName,
+		CategoryId:   auctionEntity.CategoryId,
+		Category:     auctionEntity.Category,
+		Description:  auctionEntity.Description,
+		Condition:    ProductCondition(auctionEntity.Condition),
+		Status:       AuctionStatus(auctionEntity.Status),
+		Timestamp:    auctionEntity.Timestamp,
+		EndTime:      auctionEntity.EndTime,
+		CurrentPrice: auctionEntity.CurrentPrice,
+		BidCount:     auctionEntity.BidCount,
+		Images:       toAuctionImageOutputDTOs(auctionEntity.Id, auctionEntity.Images),
+		CancelReason: auctionEntity.CancelReason,
+		CancelledAt:  optionalTime(auctionEntity.CancelledAt),
+		RelistedFrom: auctionEntity.RelistedFrom,
+	}
+}
+
+func optionalTime(value time.Time) *time.Time {
+	if value.IsZero() {
+		return nil
+	}
+	return &value
+}
+
+func toAuctionStatuses(statuses []AuctionStatus) []auction_entity.AuctionStatus {
+	if len(statuses) == 0 {
+		return nil
+	}
+	values := make([]auction_entity.AuctionStatus, 0, len(statuses))
+	for _, status := range statuses {
+		values = append(values, auction_entity.AuctionStatus(status))
+	}
+	return values
+}
+
+// categoryTreeIds retorna o id da categoria e de todos os seus descendentes,
+// ou nil quando nenhuma categoria foi informada
+func (au *AuctionUseCase) categoryTreeIds(
+	ctx context.Context, categoryId string) ([]string, *internal_error.InternalError) {
+	if categoryId == "" {
+		return nil, nil
+	}
+
+	if _, err := au.categoryRepositoryInterface.FindCategoryById(ctx, categoryId); err != nil {
+		return nil, err
+	}
+
+	descendants, err := au.categoryRepositoryInterface.FindDescendants(ctx, categoryId)
+	if err != nil {
+		return nil, err
+	}
+
+	categoryIds := []string{categoryId}
+	for _, descendant := range descendants {
+		categoryIds = append(categoryIds, descendant.Id)
+	}
+
+	return categoryIds, nil
+}
@@ -0,0 +1,73 @@
+package auction_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryAuctionRepository só implementa a busca por id; os demais métodos
+// não são usados nestes testes
+type memoryAuctionRepository struct {
+	auction_entity.AuctionRepositoryInterface
+	auctions map[string]auction_entity.Auction
+}
+
+func (r *memoryAuctionRepository) FindAuctionById(
+	ctx context.Context, id string) (*auction_entity.Auction, *internal_error.InternalError) {
+	auction, ok := r.auctions[id]
+	if !ok {
+		return nil, internal_error.NewNotFoundError("Auction not found")
+	}
+	return &auction, nil
+}
+
+// winningBidRepository retorna sempre o mesmo lance como vencedor
+type winningBidRepository struct {
+	bid_entity.BidEntityRepository
+	winner bid_entity.Bid
+}
+
+func (r *winningBidRepository) FindWinningBidByAuctionId(
+	ctx context.Context, auctionId string) (*bid_entity.Bid, *internal_error.InternalError) {
+	winner := r.winner
+	return &winner, nil
+}
+
+func TestFindWinningBidByAuctionId(t *testing.T) {
+	auctionId := uuid.NewString()
+	winner := bid_entity.Bid{
+		Id:        uuid.NewString(),
+		UserId:    uuid.NewString(),
+		AuctionId: auctionId,
+		Amount:    150,
+		Timestamp: time.Now(),
+		Sequence:  3,
+	}
+	auctions := &memoryAuctionRepository{auctions: map[string]auction_entity.Auction{
+		auctionId: {Id: auctionId, Status: auction_entity.Completed},
+	}}
+	auctionUseCase := NewAuctionUseCase(auctions, &winningBidRepository{winner: winner}, nil)
+
+	winning, err := auctionUseCase.FindWinningBidByAuctionId(context.Background(), auctionId)
+	require.Nil(t, err)
+	require.NotNil(t, winning.Bid)
+	assert.Equal(t, winner.Id, winning.Bid.Id)
+	assert.Equal(t, int64(3), winning.Bid.Sequence)
+
+	// Os lances de um leilão cancelado continuam gravados, mas nenhum vence
+	auctions.auctions[auctionId] = auction_entity.Auction{Id: auctionId, Status: auction_entity.Cancelled}
+
+	winning, err = auctionUseCase.FindWinningBidByAuctionId(context.Background(), auctionId)
+	require.Nil(t, err)
+	assert.Nil(t, winning.Bid)
+	assert.Equal(t, auctionId, winning.Auction.Id)
+	assert.Equal(t, bid_entity.WinningBidRule, winning.WinningRule)
+}
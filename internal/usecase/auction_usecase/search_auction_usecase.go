@@ -0,0 +1,51 @@
+package auction_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/pagination_entity"
+	"fullcycle-auction_go/internal/entity/search_entity"
+	"fullcycle-auction_go/internal/infra/tracing"
+	"fullcycle-auction_go/internal/internal_error"
+)
+
+func (au *AuctionUseCase) SearchAuctions(
+	ctx context.Context,
+	input SearchAuctionsInputDTO) (*AuctionSearchPageOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "AuctionUseCase.SearchAuctions")
+	defer span.End()
+
+	query, err := search_entity.ParseQuery(input.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	categoryIds, err := au.categoryTreeIds(ctx, input.CategoryId)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := pagination_entity.PageRequest{Limit: input.Limit}.EffectiveLimit()
+	hits, err := au.auctionRepositoryInterface.SearchAuctions(ctx, query, auction_entity.AuctionFilter{
+		Status:      auction_entity.AuctionStatus(input.Status),
+		Category:    input.Category,
+		CategoryIds: categoryIds,
+	}, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]AuctionSearchOutputDTO, 0, len(hits))
+	for _, hit := range hits {
+		items = append(items, AuctionSearchOutputDTO{
+			Auction:    toAuctionOutputDTO(hit.Auction),
+			Score:      hit.Score,
+			Highlights: hit.Highlights,
+		})
+	}
+
+	return &AuctionSearchPageOutputDTO{
+		Query: query.String(),
+		Items: items,
+	}, nil
+}
@@ -0,0 +1,118 @@
+package auth_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auth_entity"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/infra/tracing"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type LoginInputDTO struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type RefreshInputDTO struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type TokenOutputDTO struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	RefreshExpiresIn int64  `json:"refresh_expires_in"`
+}
+
+type AuthUseCaseInterface interface {
+	Login(
+		ctx context.Context,
+		input LoginInputDTO) (*TokenOutputDTO, *internal_error.InternalError)
+
+	Refresh(
+		ctx context.Context,
+		input RefreshInputDTO) (*TokenOutputDTO, *internal_error.InternalError)
+}
+
+type AuthUseCase struct {
+	userRepositoryInterface user_entity.UserRepositoryInterface
+	tokenService            auth_entity.TokenServiceInterface
+}
+
+func NewAuthUseCase(
+	userRepositoryInterface user_entity.UserRepositoryInterface,
+	tokenService auth_entity.TokenServiceInterface) AuthUseCaseInterface {
+	return &AuthUseCase{
+		userRepositoryInterface: userRepositoryInterface,
+		tokenService:            tokenService,
+	}
+}
+
+// dummyPasswordHash é comparado quando o e-mail não existe, para que o tempo
+// de resposta não revele quais e-mails estão cadastrados
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+func (au *AuthUseCase) Login(
+	ctx context.Context,
+	input LoginInputDTO) (*TokenOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "AuthUseCase.Login")
+	defer span.End()
+
+	user, err := au.userRepositoryInterface.FindUserByEmail(ctx, input.Email)
+	if err != nil {
+		if err.Err != "not_found" {
+			return nil, err
+		}
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(input.Password))
+		return nil, internal_error.NewUnauthorizedError("Invalid email or password")
+	}
+
+	if !user.CheckPassword(input.Password) {
+		return nil, internal_error.NewUnauthorizedError("Invalid email or password")
+	}
+
+	return au.issueTokens(user.Id)
+}
+
+// Refresh troca um refresh token válido por um novo par de tokens
+func (au *AuthUseCase) Refresh(
+	ctx context.Context,
+	input RefreshInputDTO) (*TokenOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "AuthUseCase.Refresh")
+	defer span.End()
+
+	claims, err := au.tokenService.ParseToken(input.RefreshToken, auth_entity.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// O usuário pode ter sido removido depois da emissão do token
+	if _, err := au.userRepositoryInterface.FindUserById(ctx, claims.UserId); err != nil {
+		if err.Err == "not_found" {
+			return nil, internal_error.NewUnauthorizedError("Invalid token")
+		}
+		return nil, err
+	}
+
+	return au.issueTokens(claims.UserId)
+}
+
+func (au *AuthUseCase) issueTokens(userId string) (*TokenOutputDTO, *internal_error.InternalError) {
+	tokens, err := au.tokenService.IssueTokens(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &TokenOutputDTO{
+		AccessToken:      tokens.AccessToken,
+		RefreshToken:     tokens.RefreshToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        int64(tokens.AccessExpiresAt.Sub(now).Seconds()),
+		RefreshExpiresIn: int64(tokens.RefreshExpiresAt.Sub(now).Seconds()),
+	}, nil
+}
@@ -0,0 +1,246 @@
+package bid_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/ratelimit_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sync"
+	"time"
+)
+
+// BidBatchConfig define a fila e a política de gravação em lote de uma
+// instância do caso de uso
+type BidBatchConfig struct {
+	// QueueCapacity é quantos lances podem aguardar na fila
+	QueueCapacity int
+	// EnqueueTimeout é a espera por uma vaga na fila antes de recusar o lance
+	EnqueueTimeout time.Duration
+	// Um lote é gravado ao atingir MaxBatchSize lances, MaxBatchBytes bytes
+	// estimados (0 desliga o limite) ou FlushInterval desde o seu primeiro lance
+	MaxBatchSize  int
+	MaxBatchBytes int
+	FlushInterval time.Duration
+	// MaxConcurrentFlushes limita as gravações de lotes simultâneas
+	MaxConcurrentFlushes int
+	// MaxBidsPerRequest limita os lances de um envio em lote, que não passa pela fila
+	MaxBidsPerRequest int
+	// FlushRetryInterval é a primeira espera antes de gravar de novo os lances
+	// de um lote que falhou; dobra a cada falha até maxFlushRetryInterval
+	FlushRetryInterval time.Duration
+	// AuctionRateLimit é aplicado a cada item de um envio em lote, com os
+	// baldes de AuctionRateLimitStore; sem o armazenamento não há limite
+	AuctionRateLimit      ratelimit_entity.Limit
+	AuctionRateLimitStore ratelimit_entity.RateLimitStoreInterface
+}
+
+// normalized preenche valores ausentes ou inválidos; a fila nunca é menor que um lote
+func (c BidBatchConfig) normalized() BidBatchConfig {
+	if c.MaxBatchSize <= 0 {
+		c.MaxBatchSize = 5
+	}
+	if c.QueueCapacity <= 0 {
+		c.QueueCapacity = 1000
+	}
+	if c.QueueCapacity < c.MaxBatchSize {
+		c.QueueCapacity = c.MaxBatchSize
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = 3 * time.Minute
+	}
+	if c.MaxBatchBytes < 0 {
+		c.MaxBatchBytes = 0
+	}
+	if c.EnqueueTimeout < 0 {
+		c.EnqueueTimeout = 0
+	}
+	if c.MaxConcurrentFlushes <= 0 {
+		c.MaxConcurrentFlushes = 8
+	}
+	if c.MaxBidsPerRequest <= 0 {
+		c.MaxBidsPerRequest = 30
+	}
+	if c.FlushRetryInterval <= 0 {
+		c.FlushRetryInterval = time.Second
+	}
+	return c
+}
+
+// Clock fornece os temporizadores do batcher; os testes usam um relógio manual
+type Clock interface {
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// BidBatcher junta os lances da fila em lotes e entrega cada lote a flush.
+// Todo o estado do lote pertence à goroutine iniciada em Start, então várias
+// instâncias podem coexistir no mesmo processo.
+type BidBatcher struct {
+	config BidBatchConfig
+	clock  Clock
+	flush  func(batch []bid_entity.Bid)
+
+	queue chan bid_entity.Bid
+
+	// stateMutex impede que um lance entre na fila depois do sinal de parada
+	stateMutex sync.RWMutex
+	stopped    bool
+	startOnce  sync.Once
+	stop       chan struct{}
+	done       chan struct{}
+
+	// Estado do lote aberto, usado só pela goroutine de run; deadline só
+	// existe enquanto há um lote aberto
+	batch      []bid_entity.Bid
+	batchBytes int
+	deadline   <-chan time.Time
+}
+
+func NewBidBatcher(
+	config BidBatchConfig,
+	clock Clock,
+	flush func(batch []bid_entity.Bid)) *BidBatcher {
+	config = config.normalized()
+
+	return &BidBatcher{
+		config: config,
+		clock:  clock,
+		flush:  flush,
+		queue:  make(chan bid_entity.Bid, config.QueueCapacity),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start inicia o consumo da fila; chamadas repetidas são ignoradas
+func (b *BidBatcher) Start() {
+	b.startOnce.Do(func() {
+		go b.run()
+	})
+}
+
+// Stop recusa novos lances, entrega o que estiver na fila como um último lote
+// e espera o consumo terminar até o prazo de ctx
+func (b *BidBatcher) Stop(ctx context.Context) *internal_error.InternalError {
+	b.stateMutex.Lock()
+	alreadyStopped := b.stopped
+	b.stopped = true
+	b.stateMutex.Unlock()
+
+	if !alreadyStopped {
+		close(b.stop)
+	}
+
+	// Sem Start não há goroutine para esvaziar a fila
+	b.Start()
+
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return internal_error.NewInternalServerError("Timed out waiting for the bid batcher to stop")
+	}
+}
+
+// Enqueue espera no máximo EnqueueTimeout (ou até o contexto da requisição
+// terminar) por uma vaga na fila
+func (b *BidBatcher) Enqueue(ctx context.Context, bid bid_entity.Bid) *internal_error.InternalError {
+	b.stateMutex.RLock()
+	defer b.stateMutex.RUnlock()
+
+	if b.stopped {
+		return internal_error.NewServiceUnavailableError("Bid intake is shutting down, try again later")
+	}
+
+	select {
+	case b.queue <- bid:
+		return nil
+	default:
+	}
+
+	if b.config.EnqueueTimeout > 0 {
+		timer := time.NewTimer(b.config.EnqueueTimeout)
+		defer timer.Stop()
+
+		select {
+		case b.queue <- bid:
+			return nil
+		case <-ctx.Done():
+		case <-timer.C:
+		}
+	}
+
+	return internal_error.NewServiceUnavailableError("Bid queue is full, try again later")
+}
+
+func (b *BidBatcher) Depth() int {
+	return len(b.queue)
+}
+
+func (b *BidBatcher) Capacity() int {
+	return cap(b.queue)
+}
+
+func (b *BidBatcher) run() {
+	defer close(b.done)
+
+	for {
+		select {
+		case bid := <-b.queue:
+			b.add(bid)
+		case <-b.deadline:
+			b.flushBatch()
+		case <-b.stop:
+			// Depois do sinal de parada nenhum lance novo entra na fila
+			for len(b.queue) > 0 {
+				b.add(<-b.queue)
+			}
+			b.flushBatch()
+			return
+		}
+	}
+}
+
+// add coloca o lance no lote atual, gravando o lote antes se o lance não
+// couber nele e depois se o lote atingir um dos limites
+func (b *BidBatcher) add(bid bid_entity.Bid) {
+	size := estimateBidSize(bid)
+	if len(b.batch) > 0 && b.config.MaxBatchBytes > 0 &&
+		b.batchBytes+size > b.config.MaxBatchBytes {
+		b.flushBatch()
+	}
+
+	if len(b.batch) == 0 {
+		b.deadline = b.clock.After(b.config.FlushInterval)
+	}
+	b.batch = append(b.batch, bid)
+	b.batchBytes += size
+
+	if len(b.batch) >= b.config.MaxBatchSize ||
+		(b.config.MaxBatchBytes > 0 && b.batchBytes >= b.config.MaxBatchBytes) {
+		b.flushBatch()
+	}
+}
+
+func (b *BidBatcher) flushBatch() {
+	b.deadline = nil
+	if len(b.batch) == 0 {
+		return
+	}
+
+	batch := b.batch
+	b.batch = nil
+	b.batchBytes = 0
+	b.flush(batch)
+}
+
+// estimateBidSize aproxima o tamanho do documento gravado para o lance
+func estimateBidSize(bid bid_entity.Bid) int {
+	const fixedFieldsSize = 96
+	return fixedFieldsSize + len(bid.Id) + len(bid.UserId) + len(bid.AuctionId)
+}
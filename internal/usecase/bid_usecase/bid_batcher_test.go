@@ -0,0 +1,176 @@
+package bid_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// manualClock só dispara os temporizadores quando Advance é chamado
+type manualClock struct {
+	mutex   sync.Mutex
+	now     time.Duration
+	waiters []manualTimer
+}
+
+type manualTimer struct {
+	at      time.Duration
+	channel chan time.Time
+}
+
+func (c *manualClock) After(d time.Duration) <-chan time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	channel := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, manualTimer{at: c.now + d, channel: channel})
+	return channel
+}
+
+func (c *manualClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now += d
+	waiters := c.waiters[:0]
+	for _, waiter := range c.waiters {
+		if waiter.at <= c.now {
+			waiter.channel <- time.Time{}
+		} else {
+			waiters = append(waiters, waiter)
+		}
+	}
+	c.waiters = waiters
+}
+
+func (c *manualClock) pending() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.waiters)
+}
+
+// batchRecorder guarda os lotes entregues pelo batcher
+type batchRecorder struct {
+	mutex   sync.Mutex
+	batches [][]bid_entity.Bid
+}
+
+func (r *batchRecorder) flush(batch []bid_entity.Bid) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.batches = append(r.batches, batch)
+}
+
+func (r *batchRecorder) sizes() []int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	sizes := make([]int, 0, len(r.batches))
+	for _, batch := range r.batches {
+		sizes = append(sizes, len(batch))
+	}
+	return sizes
+}
+
+func newTestBid() bid_entity.Bid {
+	bid, _ := bid_entity.CreateBid(uuid.NewString(), uuid.NewString(), 10)
+	return *bid
+}
+
+func enqueueBids(t *testing.T, batcher *BidBatcher, count int) {
+	for i := 0; i < count; i++ {
+		require.Nil(t, batcher.Enqueue(context.Background(), newTestBid()))
+	}
+}
+
+func TestBidBatcherFlushesBySize(t *testing.T) {
+	recorder := &batchRecorder{}
+	batcher := NewBidBatcher(
+		BidBatchConfig{MaxBatchSize: 3, FlushInterval: time.Hour}, &manualClock{}, recorder.flush)
+	batcher.Start()
+
+	enqueueBids(t, batcher, 7)
+	assert.Eventually(t, func() bool { return len(recorder.sizes()) == 2 }, time.Second, time.Millisecond)
+
+	require.Nil(t, batcher.Stop(context.Background()))
+	assert.Equal(t, []int{3, 3, 1}, recorder.sizes())
+}
+
+func TestBidBatcherFlushesByBytes(t *testing.T) {
+	recorder := &batchRecorder{}
+	bidSize := estimateBidSize(newTestBid())
+	batcher := NewBidBatcher(
+		BidBatchConfig{MaxBatchSize: 100, MaxBatchBytes: 2*bidSize + 1, FlushInterval: time.Hour},
+		&manualClock{}, recorder.flush)
+	batcher.Start()
+
+	// O terceiro lance não cabe no lote e abre um novo
+	enqueueBids(t, batcher, 5)
+	require.Nil(t, batcher.Stop(context.Background()))
+	assert.Equal(t, []int{2, 2, 1}, recorder.sizes())
+}
+
+func TestBidBatcherFlushesByInterval(t *testing.T) {
+	recorder := &batchRecorder{}
+	clock := &manualClock{}
+	batcher := NewBidBatcher(
+		BidBatchConfig{MaxBatchSize: 100, FlushInterval: time.Minute}, clock, recorder.flush)
+	batcher.Start()
+	defer batcher.Stop(context.Background())
+
+	// O prazo só começa a contar com o primeiro lance do lote
+	clock.Advance(time.Hour)
+	enqueueBids(t, batcher, 2)
+	require.Eventually(t, func() bool { return clock.pending() == 1 }, time.Second, time.Millisecond)
+
+	clock.Advance(59 * time.Second)
+	assert.Empty(t, recorder.sizes())
+
+	clock.Advance(time.Second)
+	assert.Eventually(t, func() bool { return len(recorder.sizes()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, []int{2}, recorder.sizes())
+}
+
+func TestBidBatcherInstancesAreIndependent(t *testing.T) {
+	first, second := &batchRecorder{}, &batchRecorder{}
+	config := BidBatchConfig{MaxBatchSize: 2, FlushInterval: time.Hour}
+	firstBatcher := NewBidBatcher(config, &manualClock{}, first.flush)
+	secondBatcher := NewBidBatcher(config, &manualClock{}, second.flush)
+	firstBatcher.Start()
+	secondBatcher.Start()
+
+	var wg sync.WaitGroup
+	for _, batcher := range []*BidBatcher{firstBatcher, secondBatcher} {
+		wg.Add(1)
+		go func(batcher *BidBatcher) {
+			defer wg.Done()
+			enqueueBids(t, batcher, 5)
+		}(batcher)
+	}
+	wg.Wait()
+
+	require.Nil(t, firstBatcher.Stop(context.Background()))
+	require.Nil(t, secondBatcher.Stop(context.Background()))
+	assert.Equal(t, []int{2, 2, 1}, first.sizes())
+	assert.Equal(t, []int{2, 2, 1}, second.sizes())
+}
+
+func TestBidBatcherRejectsAfterStop(t *testing.T) {
+	recorder := &batchRecorder{}
+	batcher := NewBidBatcher(BidBatchConfig{MaxBatchSize: 10}, &manualClock{}, recorder.flush)
+
+	// Lances enfileirados antes de Start são entregues na parada
+	enqueueBids(t, batcher, 2)
+	require.Nil(t, batcher.Stop(context.Background()))
+	assert.Equal(t, []int{2}, recorder.sizes())
+
+	err := batcher.Enqueue(context.Background(), newTestBid())
+	require.NotNil(t, err)
+	assert.Equal(t, "service_unavailable", err.Err)
+}
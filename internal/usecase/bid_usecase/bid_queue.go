@@ -0,0 +1,117 @@
+package bid_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// BidQueueStats é o retrato da fila de lances exposto como métrica
+type BidQueueStats struct {
+	Depth    int `json:"depth"`
+	Capacity int `json:"capacity"`
+	// Enqueued e Rejected contam os lances aceitos na fila e os recusados por fila cheia
+	Enqueued        int64 `json:"enqueued"`
+	Rejected        int64 `json:"rejected"`
+	Flushes         int64 `json:"flushes"`
+	FlushedBids     int64 `json:"flushed_bids"`
+	InFlightFlushes int   `json:"in_flight_flushes"`
+	// LastFlushMillis e AvgFlushMillis medem a gravação de um lote de um leilão
+	LastFlushMillis int64   `json:"last_flush_ms"`
+	AvgFlushMillis  float64 `json:"avg_flush_ms"`
+	// LastBatchLatencyMillis é o tempo entre a chegada do lance mais antigo do
+	// último lote e o fim da sua gravação
+	LastBatchLatencyMillis int64 `json:"last_batch_latency_ms"`
+}
+
+type bidQueueMetrics struct {
+	enqueued           atomic.Int64
+	rejected           atomic.Int64
+	flushes            atomic.Int64
+	flushedBids        atomic.Int64
+	totalFlushMillis   atomic.Int64
+	lastFlushMillis    atomic.Int64
+	lastBatchLatencyMs atomic.Int64
+}
+
+func (m *bidQueueMetrics) recordFlush(bids []bid_entity.Bid, started time.Time, finished time.Time) {
+	duration := finished.Sub(started).Milliseconds()
+	m.flushes.Add(1)
+	m.flushedBids.Add(int64(len(bids)))
+	m.totalFlushMillis.Add(duration)
+	m.lastFlushMillis.Store(duration)
+
+	oldest := finished
+	for _, bid := range bids {
+		if bid.Timestamp.Before(oldest) {
+			oldest = bid.Timestamp
+		}
+	}
+	m.lastBatchLatencyMs.Store(finished.Sub(oldest).Milliseconds())
+}
+
+// auctionFlushes encadeia as gravações de um mesmo leilão: cada lote espera o
+// anterior do mesmo leilão terminar, preservando a ordem de chegada, enquanto
+// leilões diferentes são gravados em paralelo
+type auctionFlushes struct {
+	mutex sync.Mutex
+	last  map[string]chan struct{}
+}
+
+func newAuctionFlushes() *auctionFlushes {
+	return &auctionFlushes{last: make(map[string]chan struct{})}
+}
+
+// schedule retorna o canal do lote anterior do leilão (nil se não houver) e o
+// canal a ser fechado quando o novo lote terminar
+func (af *auctionFlushes) schedule(auctionId string) (<-chan struct{}, chan struct{}) {
+	af.mutex.Lock()
+	defer af.mutex.Unlock()
+
+	previous := af.last[auctionId]
+	done := make(chan struct{})
+	af.last[auctionId] = done
+	return previous, done
+}
+
+func (af *auctionFlushes) finish(auctionId string, done chan struct{}) {
+	af.mutex.Lock()
+	defer af.mutex.Unlock()
+
+	close(done)
+	if af.last[auctionId] == done {
+		delete(af.last, auctionId)
+	}
+}
+
+// bidSpans guarda o span da requisição de cada lance na fila, para que a
+// gravação em lote, feita fora da requisição, seja ligada às requisições que
+// a originaram
+type bidSpans struct {
+	spans sync.Map
+}
+
+func (bs *bidSpans) add(ctx context.Context, bidId string) {
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		bs.spans.Store(bidId, spanContext)
+	}
+}
+
+func (bs *bidSpans) remove(bidId string) {
+	bs.spans.Delete(bidId)
+}
+
+// take retorna os links dos lances do lote e os esquece
+func (bs *bidSpans) take(bids []bid_entity.Bid) []trace.Link {
+	var links []trace.Link
+	for _, bid := range bids {
+		if spanContext, ok := bs.spans.LoadAndDelete(bid.Id); ok {
+			links = append(links, trace.Link{SpanContext: spanContext.(trace.SpanContext)})
+		}
+	}
+	return links
+}
@@ -2,16 +2,25 @@ package bid_usecase
 
 import (
 	"context"
+	"fmt"
 	"fullcycle-auction_go/configuration/logger"
 	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/ratelimit_entity"
+	"fullcycle-auction_go/internal/infra/metrics"
+	"fullcycle-auction_go/internal/infra/tracing"
 	"fullcycle-auction_go/internal/internal_error"
-	"os"
-	"strconv"
+	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 )
 
 type BidInputDTO struct {
-	UserId    string  `json:"user_id"`
+	// UserId vem do token de acesso, nunca do corpo da requisição
+	UserId    string  `json:"-"`
 	AuctionId string  `json:"auction_id"`
 	Amount    float64 `json:"amount"`
 }
@@ -22,114 +31,387 @@ type BidOutputDTO struct {
 	AuctionId string    `json:"auction_id"`
 	Amount    float64   `json:"amount"`
 	Timestamp time.Time `json:"timestamp" time_format:"2006-01-02 15:04:05"`
+	Sequence  int64     `json:"sequence"`
+	// Voided indica um lance anulado por um administrador
+	Voided     bool   `json:"voided,omitempty"`
+	VoidReason string `json:"void_reason,omitempty"`
+}
+
+type VoidBidInputDTO struct {
+	Reason string `json:"reason" binding:"required,min=3,max=200"`
+}
+
+// FindBidsInputDTO reúne a ordenação e a paginação aceitas na listagem de lances
+type FindBidsInputDTO struct {
+	Limit     int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	PageToken string `form:"page_token"`
+	Sort      string `form:"sort" binding:"omitempty,oneof=sequence amount"`
+	Order     string `form:"order" binding:"omitempty,oneof=asc desc"`
+}
+
+type BidPageOutputDTO struct {
+	Items     []BidOutputDTO `json:"items"`
+	NextToken string         `json:"next_token,omitempty"`
+	Total     *int64         `json:"total,omitempty"`
+}
+
+// BidRecoveryOutputDTO resume os lances lidos do write-ahead log na inicialização
+type BidRecoveryOutputDTO struct {
+	Pending int `json:"pending"`
+	// AlreadyStored são os lances gravados antes da queda, mas não confirmados no log
+	AlreadyStored int `json:"already_stored"`
+	Replayed      int `json:"replayed"`
+	// Rejected são os lances recusados na nova gravação, como os de leilões cancelados
+	Rejected int `json:"rejected"`
 }
 
+// maxFlushRetryInterval limita a espera entre as tentativas de gravar um lote
+const maxFlushRetryInterval = time.Minute
+
 type BidUseCase struct {
 	BidRepository bid_entity.BidEntityRepository
+	// bidLog guarda os lances da fila até a gravação no banco
+	bidLog  bid_entity.BidLogInterface
+	batcher *BidBatcher
+	// flushSlots limita as gravações simultâneas; quando todas estão ocupadas a
+	// fila para de ser consumida e as novas requisições recebem 503
+	flushSlots     chan struct{}
+	auctionFlushes *auctionFlushes
+	metrics        bidQueueMetrics
+	spans          bidSpans
+	// maxBidsPerRequest limita o tamanho de um envio em lote
+	maxBidsPerRequest int
+	// auctionRateLimit limita os itens de um envio em lote por leilão
+	auctionRateLimit      ratelimit_entity.Limit
+	auctionRateLimitStore ratelimit_entity.RateLimitStoreInterface
+
+	// flushRetryInterval é a primeira espera antes de repetir um lote que falhou
+	flushRetryInterval time.Duration
+	// giveUp é fechado quando o prazo do encerramento acaba e interrompe as
+	// novas tentativas; os lances pendentes ficam no log
+	giveUp     chan struct{}
+	giveUpOnce sync.Once
 
-	timer               *time.Timer
-	maxBatchSize        int
-	batchInsertInterval time.Duration
-	bidChannel          chan bid_entity.Bid
+	// flushes acompanha as gravações em andamento para o encerramento
+	flushes sync.WaitGroup
 }
 
-func NewBidUseCase(bidRepository bid_entity.BidEntityRepository) BidUseCaseInterface {
-	maxSizeInterval := getMaxBatchSizeInterval()
-	maxBatchSize := getMaxBatchSize()
+func NewBidUseCase(
+	bidRepository bid_entity.BidEntityRepository,
+	bidLog bid_entity.BidLogInterface,
+	config BidBatchConfig) BidUseCaseInterface {
+	config = config.normalized()
 
 	bidUseCase := &BidUseCase{
-		BidRepository:       bidRepository,
-		maxBatchSize:        maxBatchSize,
-		batchInsertInterval: maxSizeInterval,
-		timer:               time.NewTimer(maxSizeInterval),
-		bidChannel:          make(chan bid_entity.Bid, maxBatchSize),
-	}
+		BidRepository:      bidRepository,
+		bidLog:             bidLog,
+		flushSlots:         make(chan struct{}, config.MaxConcurrentFlushes),
+		auctionFlushes:     newAuctionFlushes(),
+		maxBidsPerRequest:  config.MaxBidsPerRequest,
+		flushRetryInterval: config.FlushRetryInterval,
+		giveUp:             make(chan struct{}),
 
-	bidUseCase.triggerCreateRoutine(context.Background())
+		auctionRateLimit:      config.AuctionRateLimit,
+		auctionRateLimitStore: config.AuctionRateLimitStore,
+	}
+	bidUseCase.batcher = NewBidBatcher(config, realClock{}, func(batch []bid_entity.Bid) {
+		bidUseCase.flush(context.Background(), batch)
+	})
 
 	return bidUseCase
 }
 
-var bidBatch []bid_entity.Bid
-
 type BidUseCaseInterface interface {
+	// CreateBid coloca o lance na fila de gravação; com a fila cheia o lance é
+	// recusado com service_unavailable em vez de bloquear a requisição
 	CreateBid(
 		ctx context.Context,
 		bidInputDTO BidInputDTO) *internal_error.InternalError
 
+	// CreateBids valida e grava um lote de lances diretamente no banco, sem
+	// passar pela fila, e informa o resultado de cada item
+	CreateBids(
+		ctx context.Context,
+		bidInputs []BidInputDTO) (*BulkBidOutputDTO, *internal_error.InternalError)
+
 	FindWinningBidByAuctionId(
 		ctx context.Context, auctionId string) (*BidOutputDTO, *internal_error.InternalError)
 
 	FindBidByAuctionId(
-		ctx context.Context, auctionId string) ([]BidOutputDTO, *internal_error.InternalError)
+		ctx context.Context,
+		auctionId string,
+		input FindBidsInputDTO) (*BidPageOutputDTO, *internal_error.InternalError)
+
+	VoidBid(
+		ctx context.Context,
+		bidId string,
+		input VoidBidInputDTO) (*BidOutputDTO, *internal_error.InternalError)
+
+	// RecoverLoggedBids grava os lances que estavam na fila quando o processo
+	// parou; deve ser chamado na inicialização, antes de Start
+	RecoverLoggedBids(ctx context.Context) (*BidRecoveryOutputDTO, *internal_error.InternalError)
+
+	QueueStats() BidQueueStats
+
+	// Start inicia a gravação em lote dos lances da fila
+	Start()
+
+	// Shutdown recusa novos lances, grava os que estão na fila e espera as
+	// gravações em andamento até o prazo de ctx. Lances não gravados a tempo
+	// continuam no log e são recuperados na próxima inicialização.
+	Shutdown(ctx context.Context) *internal_error.InternalError
 }
 
-func (bu *BidUseCase) triggerCreateRoutine(ctx context.Context) {
-	go func() {
-		defer close(bu.bidChannel)
-
-		for {
-			select {
-			case bidEntity, ok := <-bu.bidChannel:
-				if !ok {
-					if len(bidBatch) > 0 {
-						if err := bu.BidRepository.CreateBid(ctx, bidBatch); err != nil {
-							logger.Error("error trying to process bid batch list", err)
-						}
-					}
-					return
-				}
-
-				bidBatch = append(bidBatch, bidEntity)
-
-				if len(bidBatch) >= bu.maxBatchSize {
-					if err := bu.BidRepository.CreateBid(ctx, bidBatch); err != nil {
-						logger.Error("error trying to process bid batch list", err)
-					}
-
-					bidBatch = nil
-					bu.timer.Reset(bu.batchInsertInterval)
-				}
-			case <-bu.timer.C:
-				if err := bu.BidRepository.CreateBid(ctx, bidBatch); err != nil {
-					logger.Error("error trying to process bid batch list", err)
-				}
-				bidBatch = nil
-				bu.timer.Reset(bu.batchInsertInterval)
+func (bu *BidUseCase) Start() {
+	bu.batcher.Start()
+}
+
+// flush separa o lote por leilão e grava cada parte em segundo plano. Só
+// bloqueia quando não há vaga para uma nova gravação, o que segura o consumo
+// da fila enquanto o banco estiver lento.
+func (bu *BidUseCase) flush(ctx context.Context, batch []bid_entity.Bid) {
+	for auctionId, bids := range groupBidsByAuction(batch) {
+		bu.flushSlots <- struct{}{}
+		previous, done := bu.auctionFlushes.schedule(auctionId)
+
+		bu.flushes.Add(1)
+		go func(auctionId string, bids []bid_entity.Bid) {
+			defer bu.flushes.Done()
+			defer func() { <-bu.flushSlots }()
+			defer bu.auctionFlushes.finish(auctionId, done)
+
+			if previous != nil {
+				<-previous
+			}
+
+			flushCtx, span := tracing.Start(logger.WithAuctionId(ctx, auctionId), "BidUseCase.flush",
+				trace.WithLinks(bu.spans.take(bids)...),
+				trace.WithAttributes(
+					attribute.String("auction_id", auctionId),
+					attribute.Int("bid_count", len(bids))))
+			defer span.End()
+
+			started := time.Now()
+			if err := bu.storeBatch(flushCtx, bids); err != nil {
+				span.SetStatus(codes.Error, err.Message)
+				// Os lances continuam no log e são reprocessados na próxima inicialização
+				logger.ErrorContext(ctx, "error trying to process bid batch list", err)
+			}
+			finished := time.Now()
+			bu.metrics.recordFlush(bids, started, finished)
+			metrics.ObserveBidFlush(len(bids), finished.Sub(started))
+		}(auctionId, bids)
+	}
+}
+
+// storeBatch grava os lances e confirma no log os que foram resolvidos,
+// aceitos ou recusados pela regra do leilão. Os que falham na gravação são
+// tentados de novo com espera crescente enquanto o processo roda, ocupando a
+// vaga de gravação para segurar a fila se o banco estiver fora. Só desiste
+// quando o prazo do encerramento acaba.
+func (bu *BidUseCase) storeBatch(ctx context.Context, bids []bid_entity.Bid) *internal_error.InternalError {
+	retryInterval := bu.flushRetryInterval
+	for {
+		failed := make(map[string]bool)
+		for _, rejection := range bu.BidRepository.CreateBidsWithResults(ctx, bids) {
+			if rejection.Reason == bid_entity.RejectionStorageError {
+				failed[rejection.BidId] = true
 			}
 		}
-	}()
+
+		var resolved, pending []bid_entity.Bid
+		for _, bid := range bids {
+			if failed[bid.Id] {
+				pending = append(pending, bid)
+			} else {
+				resolved = append(resolved, bid)
+			}
+		}
+		if len(resolved) > 0 {
+			bu.ackLoggedBids(resolved)
+		}
+
+		if len(pending) == 0 {
+			return nil
+		}
+
+		logger.WarnContext(ctx, "Retrying bids that failed to be stored",
+			zap.Int("failed", len(pending)),
+			zap.Duration("retry_in", retryInterval))
+
+		select {
+		case <-bu.giveUp:
+			return internal_error.NewInternalServerError(
+				fmt.Sprintf("Error trying to insert %d of %d bids", len(pending), len(bids)))
+		case <-time.After(retryInterval):
+		}
+
+		bids = pending
+		retryInterval = min(2*retryInterval, maxFlushRetryInterval)
+	}
 }
 
 func (bu *BidUseCase) CreateBid(
 	ctx context.Context,
 	bidInputDTO BidInputDTO) *internal_error.InternalError {
+	ctx, span := tracing.Start(ctx, "BidUseCase.CreateBid")
+	defer span.End()
+
+	metrics.BidsReceived("queue", 1)
+	ctx = logger.WithAuctionId(ctx, bidInputDTO.AuctionId)
 
 	bidEntity, err := bid_entity.CreateBid(bidInputDTO.UserId, bidInputDTO.AuctionId, bidInputDTO.Amount)
 	if err != nil {
+		metrics.BidRejected(bid_entity.RejectionInvalidBid)
+		return err
+	}
+
+	// O lance só é confirmado ao cliente depois de estar no disco
+	if err := bu.bidLog.Append(*bidEntity); err != nil {
 		return err
 	}
 
-	bu.bidChannel <- *bidEntity
+	bu.spans.add(ctx, bidEntity.Id)
+	if err := bu.batcher.Enqueue(ctx, *bidEntity); err != nil {
+		bu.spans.remove(bidEntity.Id)
+		bu.ackLoggedBids([]bid_entity.Bid{*bidEntity})
+		bu.metrics.rejected.Add(1)
+		metrics.BidRejected(bid_entity.RejectionQueueUnavailable)
+		logger.WarnContext(ctx, "Bid was not queued",
+			zap.String("bid_id", bidEntity.Id),
+			zap.String("reason", err.Message),
+			zap.Int("capacity", bu.batcher.Capacity()))
+		return err
+	}
 
+	bu.metrics.enqueued.Add(1)
+	logger.DebugContext(ctx, "Bid queued", zap.String("bid_id", bidEntity.Id))
 	return nil
 }
 
-func getMaxBatchSizeInterval() time.Duration {
-	batchInsertInterval := os.Getenv("BATCH_INSERT_INTERVAL")
-	duration, err := time.ParseDuration(batchInsertInterval)
-	if err != nil {
-		return 3 * time.Minute
+func (bu *BidUseCase) Shutdown(ctx context.Context) *internal_error.InternalError {
+	if err := bu.batcher.Stop(ctx); err != nil {
+		bu.stopRetries()
+		return err
 	}
 
-	return duration
+	flushed := make(chan struct{})
+	go func() {
+		bu.flushes.Wait()
+		close(flushed)
+	}()
+
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		bu.stopRetries()
+		return internal_error.NewInternalServerError("Timed out waiting for queued bids to be stored")
+	}
+}
+
+func (bu *BidUseCase) stopRetries() {
+	bu.giveUpOnce.Do(func() { close(bu.giveUp) })
 }
 
-func getMaxBatchSize() int {
-	value, err := strconv.Atoi(os.Getenv("MAX_BATCH_SIZE"))
+func (bu *BidUseCase) RecoverLoggedBids(
+	ctx context.Context) (*BidRecoveryOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "BidUseCase.RecoverLoggedBids")
+	defer span.End()
+
+	pendingBids, err := bu.bidLog.PendingBids()
+	if err != nil {
+		return nil, err
+	}
+
+	recovery := &BidRecoveryOutputDTO{Pending: len(pendingBids)}
+	if len(pendingBids) == 0 {
+		return recovery, nil
+	}
+
+	bidIds := make([]string, 0, len(pendingBids))
+	for _, bid := range pendingBids {
+		bidIds = append(bidIds, bid.Id)
+	}
+
+	// Lances gravados pouco antes da queda, mas ainda não confirmados no log,
+	// não podem ser aceitos de novo
+	existing, err := bu.BidRepository.FindExistingBidIds(ctx, bidIds)
 	if err != nil {
-		return 5
+		return nil, err
 	}
 
-	return value
+	var missingBids []bid_entity.Bid
+	for _, bid := range pendingBids {
+		if !existing[bid.Id] {
+			missingBids = append(missingBids, bid)
+		}
+	}
+	recovery.AlreadyStored = len(pendingBids) - len(missingBids)
+
+	if len(missingBids) > 0 {
+		failed := 0
+		for _, rejection := range bu.BidRepository.CreateBidsWithResults(ctx, missingBids) {
+			if rejection.Reason == bid_entity.RejectionStorageError {
+				failed++
+				continue
+			}
+			recovery.Rejected++
+		}
+
+		// Os lances continuam no log e a recuperação é repetida na próxima inicialização
+		if failed > 0 {
+			return nil, internal_error.NewInternalServerError(
+				fmt.Sprintf("Error trying to replay %d of %d logged bids", failed, len(missingBids)))
+		}
+		recovery.Replayed = len(missingBids) - recovery.Rejected
+	}
+
+	logger.InfoContext(ctx, "Recovered bids from the write-ahead log",
+		zap.Int("pending", recovery.Pending),
+		zap.Int("replayed", recovery.Replayed),
+		zap.Int("rejected", recovery.Rejected))
+
+	if err := bu.bidLog.Ack(bidIds...); err != nil {
+		return nil, err
+	}
+	return recovery, nil
+}
+
+func (bu *BidUseCase) ackLoggedBids(bids []bid_entity.Bid) {
+	bidIds := make([]string, 0, len(bids))
+	for _, bid := range bids {
+		bidIds = append(bidIds, bid.Id)
+	}
+
+	if err := bu.bidLog.Ack(bidIds...); err != nil {
+		logger.Error("error trying to acknowledge logged bids", err)
+	}
+}
+
+func (bu *BidUseCase) QueueStats() BidQueueStats {
+	stats := BidQueueStats{
+		Depth:                  bu.batcher.Depth(),
+		Capacity:               bu.batcher.Capacity(),
+		Enqueued:               bu.metrics.enqueued.Load(),
+		Rejected:               bu.metrics.rejected.Load(),
+		Flushes:                bu.metrics.flushes.Load(),
+		FlushedBids:            bu.metrics.flushedBids.Load(),
+		InFlightFlushes:        len(bu.flushSlots),
+		LastFlushMillis:        bu.metrics.lastFlushMillis.Load(),
+		LastBatchLatencyMillis: bu.metrics.lastBatchLatencyMs.Load(),
+	}
+	if stats.Flushes > 0 {
+		stats.AvgFlushMillis = float64(bu.metrics.totalFlushMillis.Load()) / float64(stats.Flushes)
+	}
+	return stats
+}
+
+// groupBidsByAuction separa o lote por leilão mantendo a ordem de chegada
+func groupBidsByAuction(bidEntities []bid_entity.Bid) map[string][]bid_entity.Bid {
+	groups := make(map[string][]bid_entity.Bid)
+	for _, bid := range bidEntities {
+		groups[bid.AuctionId] = append(groups[bid.AuctionId], bid)
+	}
+	return groups
 }
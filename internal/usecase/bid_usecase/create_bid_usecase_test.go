@@ -0,0 +1,438 @@
+package bid_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/pagination_entity"
+	"fullcycle-auction_go/internal/entity/ratelimit_entity"
+	"fullcycle-auction_go/internal/infra/ratelimit"
+	"fullcycle-auction_go/internal/internal_error"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// blockingBidRepository segura as gravações do leilão blockedAuctionId até
+// que release seja fechado, recusa os lances de closedAuctionId e falha as
+// primeiras failures gravações
+type blockingBidRepository struct {
+	blockedAuctionId string
+	closedAuctionId  string
+	release          chan struct{}
+	failures         int
+
+	mutex   sync.Mutex
+	created []bid_entity.Bid
+}
+
+func (r *blockingBidRepository) CreateBid(
+	ctx context.Context, bidEntities []bid_entity.Bid) *internal_error.InternalError {
+	if len(bidEntities) > 0 && bidEntities[0].AuctionId == r.blockedAuctionId {
+		<-r.release
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.created = append(r.created, bidEntities...)
+	return nil
+}
+
+func (r *blockingBidRepository) CreateBidsWithResults(
+	ctx context.Context, bidEntities []bid_entity.Bid) []bid_entity.BidRejection {
+	r.mutex.Lock()
+	failing := r.failures > 0
+	if failing {
+		r.failures--
+	}
+	r.mutex.Unlock()
+
+	var accepted []bid_entity.Bid
+	var rejections []bid_entity.BidRejection
+	for _, bid := range bidEntities {
+		if failing {
+			rejections = append(rejections, bid_entity.BidRejection{
+				BidId: bid.Id, Reason: bid_entity.RejectionStorageError})
+			continue
+		}
+		if bid.AuctionId == r.closedAuctionId {
+			rejections = append(rejections, bid_entity.BidRejection{
+				BidId: bid.Id, Reason: bid_entity.RejectionAuctionClosed})
+			continue
+		}
+		accepted = append(accepted, bid)
+	}
+
+	r.CreateBid(ctx, accepted)
+	return rejections
+}
+
+func (r *blockingBidRepository) createdCount() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.created)
+}
+
+func (r *blockingBidRepository) FindBidByAuctionId(
+	ctx context.Context,
+	auctionId string,
+	page pagination_entity.PageRequest) (*bid_entity.BidPage, *internal_error.InternalError) {
+	return nil, internal_error.NewNotFoundError("not implemented")
+}
+
+func (r *blockingBidRepository) FindWinningBidByAuctionId(
+	ctx context.Context, auctionId string) (*bid_entity.Bid, *internal_error.InternalError) {
+	return nil, internal_error.NewNotFoundError("not implemented")
+}
+
+func (r *blockingBidRepository) VoidBid(
+	ctx context.Context, bidId, reason string) (*bid_entity.Bid, *internal_error.InternalError) {
+	return nil, internal_error.NewNotFoundError("not implemented")
+}
+
+func (r *blockingBidRepository) FindExistingBidIds(
+	ctx context.Context, bidIds []string) (map[string]bool, *internal_error.InternalError) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existing := make(map[string]bool)
+	for _, bid := range r.created {
+		existing[bid.Id] = true
+	}
+	return existing, nil
+}
+
+// memoryBidLog simula o write-ahead log mantendo os lances não confirmados
+type memoryBidLog struct {
+	mutex   sync.Mutex
+	pending []bid_entity.Bid
+}
+
+func (l *memoryBidLog) Append(bid bid_entity.Bid) *internal_error.InternalError {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.pending = append(l.pending, bid)
+	return nil
+}
+
+func (l *memoryBidLog) Ack(bidIds ...string) *internal_error.InternalError {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	acked := make(map[string]bool)
+	for _, bidId := range bidIds {
+		acked[bidId] = true
+	}
+
+	pending := l.pending[:0]
+	for _, bid := range l.pending {
+		if !acked[bid.Id] {
+			pending = append(pending, bid)
+		}
+	}
+	l.pending = pending
+	return nil
+}
+
+func (l *memoryBidLog) PendingBids() ([]bid_entity.Bid, *internal_error.InternalError) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return append([]bid_entity.Bid(nil), l.pending...), nil
+}
+
+func (l *memoryBidLog) pendingCount() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return len(l.pending)
+}
+
+func newBidInput(auctionId string) BidInputDTO {
+	return BidInputDTO{UserId: uuid.NewString(), AuctionId: auctionId, Amount: 10}
+}
+
+func TestCreateBidQueueAndFlushes(t *testing.T) {
+	blockedAuctionId := uuid.NewString()
+	repository := &blockingBidRepository{blockedAuctionId: blockedAuctionId, release: make(chan struct{})}
+	bidLog := &memoryBidLog{}
+	bidUseCase := NewBidUseCase(repository, bidLog, BidBatchConfig{
+		QueueCapacity:        1,
+		MaxBatchSize:         1,
+		FlushInterval:        time.Hour,
+		MaxConcurrentFlushes: 2,
+	})
+	bidUseCase.Start()
+	ctx := context.Background()
+
+	waitFor := func(condition func(stats BidQueueStats) bool) {
+		require.Eventually(t, func() bool { return condition(bidUseCase.QueueStats()) },
+			time.Second, time.Millisecond)
+	}
+
+	// Um leilão lento não atrasa a gravação dos demais
+	first := newBidInput(blockedAuctionId)
+	require.Nil(t, bidUseCase.CreateBid(ctx, first))
+	waitFor(func(stats BidQueueStats) bool { return stats.InFlightFlushes == 1 })
+	require.Nil(t, bidUseCase.CreateBid(ctx, newBidInput(uuid.NewString())))
+	assert.Eventually(t, func() bool { return repository.createdCount() == 1 },
+		time.Second, time.Millisecond)
+
+	// O segundo lance do leilão lento ocupa a outra gravação e o terceiro segura o consumo da fila
+	second := newBidInput(blockedAuctionId)
+	require.Nil(t, bidUseCase.CreateBid(ctx, second))
+	waitFor(func(stats BidQueueStats) bool { return stats.InFlightFlushes == 2 })
+	third := newBidInput(blockedAuctionId)
+	require.Nil(t, bidUseCase.CreateBid(ctx, third))
+	waitFor(func(stats BidQueueStats) bool { return stats.Depth == 0 })
+
+	// O próximo ocupa a fila e o seguinte é recusado sem bloquear
+	fourth := newBidInput(blockedAuctionId)
+	require.Nil(t, bidUseCase.CreateBid(ctx, fourth))
+	err := bidUseCase.CreateBid(ctx, newBidInput(blockedAuctionId))
+	require.NotNil(t, err)
+	assert.Equal(t, "service_unavailable", err.Err)
+
+	stats := bidUseCase.QueueStats()
+	assert.Equal(t, int64(5), stats.Enqueued)
+	assert.Equal(t, int64(1), stats.Rejected)
+	assert.Equal(t, 1, stats.Capacity)
+
+	// O lance recusado sai do log; os que aguardam gravação continuam nele
+	assert.Equal(t, 4, bidLog.pendingCount())
+
+	close(repository.release)
+	require.Eventually(t, func() bool { return repository.createdCount() == 5 },
+		time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool { return bidLog.pendingCount() == 0 },
+		time.Second, time.Millisecond)
+	require.Nil(t, bidUseCase.Shutdown(ctx))
+
+	// Os lances do mesmo leilão são gravados na ordem de chegada
+	var userIds []string
+	for _, bid := range repository.created[1:] {
+		userIds = append(userIds, bid.UserId)
+	}
+	assert.Equal(t, []string{first.UserId, second.UserId, third.UserId, fourth.UserId}, userIds)
+}
+
+func TestRecoverLoggedBidsSkipsAlreadyStoredBids(t *testing.T) {
+	auctionId := uuid.NewString()
+	stored, _ := bid_entity.CreateBid(uuid.NewString(), auctionId, 10)
+	lost, _ := bid_entity.CreateBid(uuid.NewString(), auctionId, 20)
+
+	closedAuctionId := uuid.NewString()
+	late, _ := bid_entity.CreateBid(uuid.NewString(), closedAuctionId, 30)
+
+	repository := &blockingBidRepository{closedAuctionId: closedAuctionId, created: []bid_entity.Bid{*stored}}
+	bidLog := &memoryBidLog{pending: []bid_entity.Bid{*stored, *lost, *late}}
+
+	bidUseCase := NewBidUseCase(repository, bidLog, BidBatchConfig{})
+	recovery, err := bidUseCase.RecoverLoggedBids(context.Background())
+	require.Nil(t, err)
+
+	assert.Equal(t, []bid_entity.Bid{*stored, *lost}, repository.created)
+	assert.Equal(t, 0, bidLog.pendingCount())
+	assert.Equal(t, BidRecoveryOutputDTO{Pending: 3, AlreadyStored: 1, Replayed: 1, Rejected: 1}, *recovery)
+}
+
+func TestShutdownFlushesQueuedBids(t *testing.T) {
+	repository := &blockingBidRepository{}
+	bidLog := &memoryBidLog{}
+	bidUseCase := NewBidUseCase(repository, bidLog, BidBatchConfig{MaxBatchSize: 10, FlushInterval: time.Hour})
+	bidUseCase.Start()
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		require.Nil(t, bidUseCase.CreateBid(ctx, newBidInput(uuid.NewString())))
+	}
+	assert.Equal(t, 0, repository.createdCount())
+
+	require.Nil(t, bidUseCase.Shutdown(ctx))
+	assert.Equal(t, 3, repository.createdCount())
+	assert.Equal(t, 0, bidLog.pendingCount())
+
+	err := bidUseCase.CreateBid(ctx, newBidInput(uuid.NewString()))
+	require.NotNil(t, err)
+	assert.Equal(t, "service_unavailable", err.Err)
+}
+
+func TestFlushSpanLinksOriginatingRequests(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	repository := &blockingBidRepository{}
+	bidUseCase := NewBidUseCase(repository, &memoryBidLog{}, BidBatchConfig{MaxBatchSize: 10, FlushInterval: time.Hour})
+	bidUseCase.Start()
+
+	auctionId := uuid.NewString()
+	for i := 0; i < 2; i++ {
+		require.Nil(t, bidUseCase.CreateBid(context.Background(), newBidInput(auctionId)))
+	}
+	require.Nil(t, bidUseCase.Shutdown(context.Background()))
+
+	var requestSpans []trace.SpanContext
+	var flushSpans []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		switch span.Name() {
+		case "BidUseCase.CreateBid":
+			requestSpans = append(requestSpans, span.SpanContext())
+		case "BidUseCase.flush":
+			flushSpans = append(flushSpans, span)
+		}
+	}
+
+	require.Len(t, flushSpans, 1)
+	var linked []trace.SpanContext
+	for _, link := range flushSpans[0].Links() {
+		linked = append(linked, link.SpanContext)
+	}
+	assert.ElementsMatch(t, requestSpans, linked)
+	assert.NotEqual(t, requestSpans[0].TraceID(), flushSpans[0].SpanContext().TraceID())
+}
+
+func TestShutdownGivesUpAfterDeadline(t *testing.T) {
+	blockedAuctionId := uuid.NewString()
+	repository := &blockingBidRepository{blockedAuctionId: blockedAuctionId, release: make(chan struct{})}
+	bidLog := &memoryBidLog{}
+	bidUseCase := NewBidUseCase(repository, bidLog, BidBatchConfig{MaxBatchSize: 1, FlushInterval: time.Hour})
+	bidUseCase.Start()
+
+	require.Nil(t, bidUseCase.CreateBid(context.Background(), newBidInput(blockedAuctionId)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.NotNil(t, bidUseCase.Shutdown(ctx))
+
+	// O lance não gravado continua no log para a próxima inicialização
+	assert.Equal(t, 1, bidLog.pendingCount())
+
+	close(repository.release)
+	require.Nil(t, bidUseCase.Shutdown(context.Background()))
+}
+
+func TestFlushRetriesFailedBids(t *testing.T) {
+	closedAuctionId := uuid.NewString()
+	repository := &blockingBidRepository{closedAuctionId: closedAuctionId, failures: 2}
+	bidLog := &memoryBidLog{}
+	bidUseCase := NewBidUseCase(repository, bidLog, BidBatchConfig{
+		MaxBatchSize:       2,
+		FlushInterval:      time.Hour,
+		FlushRetryInterval: time.Millisecond,
+	})
+	bidUseCase.Start()
+	ctx := context.Background()
+
+	require.Nil(t, bidUseCase.CreateBid(ctx, newBidInput(uuid.NewString())))
+	require.Nil(t, bidUseCase.CreateBid(ctx, newBidInput(closedAuctionId)))
+
+	// As duas primeiras gravações falham; a terceira grava o lance sem
+	// esperar uma nova inicialização, e o recusado também sai do log
+	require.Eventually(t, func() bool { return repository.createdCount() == 1 },
+		time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool { return bidLog.pendingCount() == 0 },
+		time.Second, time.Millisecond)
+	require.Nil(t, bidUseCase.Shutdown(ctx))
+}
+
+func TestShutdownStopsRetryingFailedBids(t *testing.T) {
+	repository := &blockingBidRepository{failures: math.MaxInt}
+	bidLog := &memoryBidLog{}
+	bidUseCase := NewBidUseCase(repository, bidLog, BidBatchConfig{
+		MaxBatchSize:       1,
+		FlushInterval:      time.Hour,
+		FlushRetryInterval: time.Millisecond,
+	})
+	bidUseCase.Start()
+
+	require.Nil(t, bidUseCase.CreateBid(context.Background(), newBidInput(uuid.NewString())))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.NotNil(t, bidUseCase.Shutdown(ctx))
+
+	// As tentativas param e o lance fica no log para a próxima inicialização
+	require.Nil(t, bidUseCase.Shutdown(context.Background()))
+	assert.Equal(t, 0, repository.createdCount())
+	assert.Equal(t, 1, bidLog.pendingCount())
+}
+
+func TestCreateBidsReportsEachItem(t *testing.T) {
+	closedAuctionId := uuid.NewString()
+	repository := &blockingBidRepository{closedAuctionId: closedAuctionId}
+	bidLog := &memoryBidLog{}
+	bidUseCase := NewBidUseCase(repository, bidLog, BidBatchConfig{MaxBidsPerRequest: 3})
+
+	invalid := newBidInput(uuid.NewString())
+	invalid.Amount = 0
+	output, err := bidUseCase.CreateBids(context.Background(), []BidInputDTO{
+		newBidInput(uuid.NewString()),
+		invalid,
+		newBidInput(closedAuctionId),
+	})
+	require.Nil(t, err)
+
+	assert.Equal(t, 1, output.Accepted)
+	assert.Equal(t, 2, output.Rejected)
+	require.Len(t, output.Results, 3)
+
+	assert.Equal(t, BulkBidAccepted, output.Results[0].Status)
+	assert.NotEmpty(t, output.Results[0].BidId)
+	assert.Equal(t, BulkBidRejected, output.Results[1].Status)
+	assert.Equal(t, "Amount is not a valid value", output.Results[1].Reason)
+	assert.Equal(t, BulkBidRejected, output.Results[2].Status)
+	assert.Equal(t, bid_entity.RejectionAuctionClosed, output.Results[2].Reason)
+	assert.Empty(t, output.Results[2].BidId)
+
+	// O envio em lote grava direto no banco, sem passar pela fila nem pelo log
+	assert.Equal(t, 1, repository.createdCount())
+	assert.Equal(t, 0, bidLog.pendingCount())
+}
+
+func TestCreateBidsAppliesAuctionRateLimitPerItem(t *testing.T) {
+	repository := &blockingBidRepository{}
+	bidUseCase := NewBidUseCase(repository, &memoryBidLog{}, BidBatchConfig{
+		MaxBidsPerRequest:     5,
+		AuctionRateLimit:      ratelimit_entity.Limit{Rate: 0.001, Burst: 2},
+		AuctionRateLimitStore: ratelimit.NewMemoryStore(),
+	})
+
+	busyAuctionId, otherAuctionId := uuid.NewString(), uuid.NewString()
+	output, err := bidUseCase.CreateBids(context.Background(), []BidInputDTO{
+		newBidInput(busyAuctionId),
+		newBidInput(busyAuctionId),
+		newBidInput(busyAuctionId),
+		newBidInput(otherAuctionId),
+	})
+	require.Nil(t, err)
+
+	assert.Equal(t, 3, output.Accepted)
+	assert.Equal(t, 1, output.Rejected)
+	assert.Equal(t, BulkBidRejected, output.Results[2].Status)
+	assert.Equal(t, bid_entity.RejectionRateLimited, output.Results[2].Reason)
+	assert.Equal(t, BulkBidAccepted, output.Results[3].Status)
+	assert.Equal(t, 3, repository.createdCount())
+}
+
+func TestCreateBidsRejectsEmptyAndOversizedRequests(t *testing.T) {
+	bidUseCase := NewBidUseCase(&blockingBidRepository{}, &memoryBidLog{}, BidBatchConfig{MaxBidsPerRequest: 2})
+
+	_, err := bidUseCase.CreateBids(context.Background(), nil)
+	require.NotNil(t, err)
+	assert.Equal(t, "bad_request", err.Err)
+
+	auctionId := uuid.NewString()
+	_, err = bidUseCase.CreateBids(context.Background(), []BidInputDTO{
+		newBidInput(auctionId), newBidInput(auctionId), newBidInput(auctionId),
+	})
+	require.NotNil(t, err)
+	assert.Equal(t, "bad_request", err.Err)
+}
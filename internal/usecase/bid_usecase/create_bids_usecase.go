@@ -0,0 +1,120 @@
+package bid_usecase
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/ratelimit_entity"
+	"fullcycle-auction_go/internal/infra/metrics"
+	"fullcycle-auction_go/internal/infra/tracing"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// auctionRateLimitRule é o nome da regra por leilão, o mesmo usado no
+// middleware de POST /bid para que as duas rotas dividam o balde
+const auctionRateLimitRule = "auction"
+
+// Situação de cada item de um envio em lote
+const (
+	BulkBidAccepted = "accepted"
+	BulkBidRejected = "rejected"
+)
+
+// BulkBidResultDTO é o resultado de um item, na mesma posição do envio
+type BulkBidResultDTO struct {
+	Index  int    `json:"index"`
+	BidId  string `json:"bid_id,omitempty"`
+	Status string `json:"status"`
+	// Reason explica a recusa: erro de validação, rate_limited,
+	// auction_not_found, auction_closed ou storage_error
+	Reason string `json:"reason,omitempty"`
+}
+
+type BulkBidOutputDTO struct {
+	Accepted int                `json:"accepted"`
+	Rejected int                `json:"rejected"`
+	Results  []BulkBidResultDTO `json:"results"`
+}
+
+func (bu *BidUseCase) CreateBids(
+	ctx context.Context,
+	bidInputs []BidInputDTO) (*BulkBidOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "BidUseCase.CreateBids")
+	defer span.End()
+
+	if len(bidInputs) == 0 {
+		return nil, internal_error.NewBadRequestError("At least one bid is required")
+	}
+	if len(bidInputs) > bu.maxBidsPerRequest {
+		return nil, internal_error.NewBadRequestError(
+			fmt.Sprintf("A request can have at most %d bids", bu.maxBidsPerRequest))
+	}
+
+	metrics.BidsReceived("batch", len(bidInputs))
+
+	now := time.Now()
+	results := make([]BulkBidResultDTO, len(bidInputs))
+	resultIndexByBidId := make(map[string]int, len(bidInputs))
+	validBids := make([]bid_entity.Bid, 0, len(bidInputs))
+	for i, bidInput := range bidInputs {
+		bidEntity, err := bid_entity.CreateBid(bidInput.UserId, bidInput.AuctionId, bidInput.Amount)
+		if err != nil {
+			metrics.BidRejected(bid_entity.RejectionInvalidBid)
+			results[i] = BulkBidResultDTO{Index: i, Status: BulkBidRejected, Reason: err.Message}
+			continue
+		}
+
+		if bu.auctionThrottled(ctx, bidEntity.AuctionId, now) {
+			metrics.BidRejected(bid_entity.RejectionRateLimited)
+			results[i] = BulkBidResultDTO{Index: i, Status: BulkBidRejected, Reason: bid_entity.RejectionRateLimited}
+			continue
+		}
+
+		results[i] = BulkBidResultDTO{Index: i, BidId: bidEntity.Id, Status: BulkBidAccepted}
+		resultIndexByBidId[bidEntity.Id] = i
+		validBids = append(validBids, *bidEntity)
+	}
+
+	if len(validBids) > 0 {
+		for _, rejection := range bu.BidRepository.CreateBidsWithResults(ctx, validBids) {
+			i := resultIndexByBidId[rejection.BidId]
+			results[i] = BulkBidResultDTO{Index: i, Status: BulkBidRejected, Reason: rejection.Reason}
+		}
+	}
+
+	output := &BulkBidOutputDTO{Results: results}
+	for _, result := range results {
+		if result.Status == BulkBidAccepted {
+			output.Accepted++
+		} else {
+			output.Rejected++
+		}
+	}
+
+	logger.InfoContext(ctx, "Bulk bid submission processed",
+		zap.Int("accepted", output.Accepted),
+		zap.Int("rejected", output.Rejected))
+
+	return output, nil
+}
+
+// auctionThrottled aplica a cada item a mesma regra por leilão de POST /bid,
+// que não enxerga os leilões de um corpo em lista. Como no middleware, uma
+// falha no armazenamento dos baldes não recusa o lance.
+func (bu *BidUseCase) auctionThrottled(ctx context.Context, auctionId string, now time.Time) bool {
+	if bu.auctionRateLimitStore == nil || bu.auctionRateLimit.Disabled() {
+		return false
+	}
+
+	decision, err := bu.auctionRateLimitStore.Take(
+		ctx, ratelimit_entity.Key(auctionRateLimitRule, auctionId), bu.auctionRateLimit, 1, now)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to apply rate limit", err)
+		return false
+	}
+	return !decision.Allowed
+}
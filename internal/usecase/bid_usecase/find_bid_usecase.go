@@ -2,44 +2,64 @@ package bid_usecase
 
 import (
 	"context"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/pagination_entity"
+	"fullcycle-auction_go/internal/infra/tracing"
 	"fullcycle-auction_go/internal/internal_error"
 )
 
 func (bu *BidUseCase) FindBidByAuctionId(
-	ctx context.Context, auctionId string) ([]BidOutputDTO, *internal_error.InternalError) {
-	bidList, err := bu.BidRepository.FindBidByAuctionId(ctx, auctionId)
+	ctx context.Context,
+	auctionId string,
+	input FindBidsInputDTO) (*BidPageOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "BidUseCase.FindBidByAuctionId")
+	defer span.End()
+
+	bidPage, err := bu.BidRepository.FindBidByAuctionId(ctx, auctionId, pagination_entity.PageRequest{
+		Limit:      input.Limit,
+		Token:      input.PageToken,
+		SortBy:     input.Sort,
+		Descending: input.Order == "desc",
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	var bidOutputList []BidOutputDTO
-	for _, bid := range bidList {
-		bidOutputList = append(bidOutputList, BidOutputDTO{
-			Id:        bid.Id,
-			UserId:    bid.UserId,
-			AuctionId: bid.AuctionId,
-			Amount:    bid.Amount,
-			Timestamp: bid.Timestamp,
-		})
+	bidOutputList := make([]BidOutputDTO, 0, len(bidPage.Bids))
+	for _, bid := range bidPage.Bids {
+		bidOutputList = append(bidOutputList, toBidOutputDTO(bid))
 	}
 
-	return bidOutputList, nil
+	return &BidPageOutputDTO{
+		Items:     bidOutputList,
+		NextToken: bidPage.NextToken,
+		Total:     bidPage.Total,
+	}, nil
 }
 
 func (bu *BidUseCase) FindWinningBidByAuctionId(
 	ctx context.Context, auctionId string) (*BidOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "BidUseCase.FindWinningBidByAuctionId")
+	defer span.End()
+
 	bidEntity, err := bu.BidRepository.FindWinningBidByAuctionId(ctx, auctionId)
 	if err != nil {
 		return nil, err
 	}
 
-	bidOutput := &BidOutputDTO{
-		Id:        bidEntity.Id,
-		UserId:    bidEntity.UserId,
-		AuctionId: bidEntity.AuctionId,
-		Amount:    bidEntity.Amount,
-		Timestamp: bidEntity.Timestamp,
-	}
+	bidOutput := toBidOutputDTO(*bidEntity)
+	return &bidOutput, nil
+}
 
-	return bidOutput, nil
+func toBidOutputDTO(bidEntity bid_entity.Bid) BidOutputDTO {
+	return BidOutputDTO{
+		Id:         bidEntity.Id,
+		UserId:     bidEntity.UserId,
+		AuctionId:  bidEntity.AuctionId,
+		Amount:     bidEntity.Amount,
+		Timestamp:  bidEntity.Timestamp,
+		Sequence:   bidEntity.Sequence,
+		Voided:     bidEntity.Voided,
+		VoidReason: bidEntity.VoidReason,
+	}
 }
@@ -0,0 +1,32 @@
+package bid_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/infra/tracing"
+	"fullcycle-auction_go/internal/internal_error"
+
+	"go.uber.org/zap"
+)
+
+// VoidBid anula um lance; o lance continua visível na listagem, mas deixa de
+// contar para o preço corrente e para a escolha do vencedor
+func (bu *BidUseCase) VoidBid(
+	ctx context.Context,
+	bidId string,
+	input VoidBidInputDTO) (*BidOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "BidUseCase.VoidBid")
+	defer span.End()
+
+	bidEntity, err := bu.BidRepository.VoidBid(ctx, bidId, input.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.InfoContext(ctx, "Bid voided", zap.String("bid_id", bidId),
+		zap.String("auction_id", bidEntity.AuctionId),
+		zap.String("reason", input.Reason))
+
+	bidOutput := toBidOutputDTO(*bidEntity)
+	return &bidOutput, nil
+}
@@ -0,0 +1,212 @@
+package category_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/category_entity"
+	"fullcycle-auction_go/internal/entity/pagination_entity"
+	"fullcycle-auction_go/internal/infra/tracing"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+type CategoryInputDTO struct {
+	Name              string  `json:"name" binding:"required,min=2,max=60"`
+	Slug              string  `json:"slug" binding:"omitempty,max=60"`
+	ParentId          string  `json:"parent_id" binding:"omitempty,uuid"`
+	AllowedConditions []int64 `json:"allowed_conditions" binding:"omitempty,dive,oneof=1 2 3"`
+	// DefaultDuration usa o formato de duração do Go, por exemplo "72h"
+	DefaultDuration string `json:"default_duration"`
+}
+
+type CategoryOutputDTO struct {
+	Id                string              `json:"id"`
+	Name              string              `json:"name"`
+	Slug              string              `json:"slug"`
+	ParentId          string              `json:"parent_id,omitempty"`
+	Ancestors         []string            `json:"ancestors"`
+	AllowedConditions []int64             `json:"allowed_conditions"`
+	DefaultDuration   string              `json:"default_duration,omitempty"`
+	Children          []CategoryOutputDTO `json:"children,omitempty"`
+}
+
+func NewCategoryUseCase(
+	categoryRepository category_entity.CategoryRepositoryInterface,
+	auctionRepository auction_entity.AuctionRepositoryInterface) CategoryUseCaseInterface {
+	return &CategoryUseCase{
+		categoryRepository: categoryRepository,
+		auctionRepository:  auctionRepository,
+	}
+}
+
+type CategoryUseCaseInterface interface {
+	CreateCategory(
+		ctx context.Context,
+		input CategoryInputDTO) (*CategoryOutputDTO, *internal_error.InternalError)
+
+	UpdateCategory(
+		ctx context.Context,
+		id string,
+		input CategoryInputDTO) (*CategoryOutputDTO, *internal_error.InternalError)
+
+	DeleteCategory(
+		ctx context.Context, id string) *internal_error.InternalError
+
+	FindCategoryById(
+		ctx context.Context, id string) (*CategoryOutputDTO, *internal_error.InternalError)
+
+	FindCategoryTree(
+		ctx context.Context) ([]CategoryOutputDTO, *internal_error.InternalError)
+}
+
+type CategoryUseCase struct {
+	categoryRepository category_entity.CategoryRepositoryInterface
+	auctionRepository  auction_entity.AuctionRepositoryInterface
+}
+
+func (cu *CategoryUseCase) CreateCategory(
+	ctx context.Context,
+	input CategoryInputDTO) (*CategoryOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "CategoryUseCase.CreateCategory")
+	defer span.End()
+
+	parent, err := cu.findParent(ctx, input.ParentId)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultDuration, err := parseDuration(input.DefaultDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	category, err := category_entity.CreateCategory(
+		input.Name, input.Slug, parent, toConditions(input.AllowedConditions), defaultDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cu.categoryRepository.CreateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+
+	output := toCategoryOutputDTO(*category)
+	return &output, nil
+}
+
+func (cu *CategoryUseCase) UpdateCategory(
+	ctx context.Context,
+	id string,
+	input CategoryInputDTO) (*CategoryOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "CategoryUseCase.UpdateCategory")
+	defer span.End()
+
+	category, err := cu.categoryRepository.FindCategoryById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	parent, err := cu.findParent(ctx, input.ParentId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Impede ciclos: a categoria não pode ficar abaixo dela mesma ou de um descendente
+	if parent != nil && (parent.Id == category.Id || parent.IsDescendantOf(category.Id)) {
+		return nil, internal_error.NewBadRequestError("Category cannot be moved below itself")
+	}
+
+	defaultDuration, err := parseDuration(input.DefaultDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	category.Name = input.Name
+	category.Slug = input.Slug
+	if category.Slug == "" {
+		category.Slug = category_entity.Slugify(input.Name)
+	}
+	category.AllowedConditions = toConditions(input.AllowedConditions)
+	category.DefaultDuration = defaultDuration
+	category.SetParent(parent)
+
+	if err := category.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := cu.categoryRepository.UpdateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+
+	output := toCategoryOutputDTO(*category)
+	return &output, nil
+}
+
+// DeleteCategory só remove categorias sem subcategorias e sem leilões
+func (cu *CategoryUseCase) DeleteCategory(
+	ctx context.Context, id string) *internal_error.InternalError {
+	ctx, span := tracing.Start(ctx, "CategoryUseCase.DeleteCategory")
+	defer span.End()
+
+	if _, err := cu.categoryRepository.FindCategoryById(ctx, id); err != nil {
+		return err
+	}
+
+	descendants, err := cu.categoryRepository.FindDescendants(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(descendants) > 0 {
+		return internal_error.NewBadRequestError("Category has subcategories and cannot be deleted")
+	}
+
+	auctionPage, err := cu.auctionRepository.FindAuctions(ctx,
+		auction_entity.AuctionFilter{CategoryIds: []string{id}},
+		pagination_entity.PageRequest{Limit: 1})
+	if err != nil {
+		return err
+	}
+	if len(auctionPage.Auctions) > 0 {
+		return internal_error.NewBadRequestError("Category has auctions and cannot be deleted")
+	}
+
+	return cu.categoryRepository.DeleteCategory(ctx, id)
+}
+
+func (cu *CategoryUseCase) findParent(
+	ctx context.Context, parentId string) (*category_entity.Category, *internal_error.InternalError) {
+	if parentId == "" {
+		return nil, nil
+	}
+
+	parent, err := cu.categoryRepository.FindCategoryById(ctx, parentId)
+	if err != nil {
+		if err.Err == "not_found" {
+			return nil, internal_error.NewBadRequestError("Parent category does not exist")
+		}
+		return nil, err
+	}
+
+	return parent, nil
+}
+
+func parseDuration(value string) (time.Duration, *internal_error.InternalError) {
+	if value == "" {
+		return 0, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, internal_error.NewBadRequestError("Invalid default duration")
+	}
+
+	return duration, nil
+}
+
+func toConditions(values []int64) []auction_entity.ProductCondition {
+	conditions := make([]auction_entity.ProductCondition, 0, len(values))
+	for _, value := range values {
+		conditions = append(conditions, auction_entity.ProductCondition(value))
+	}
+	return conditions
+}
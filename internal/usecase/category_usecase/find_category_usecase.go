@@ -0,0 +1,88 @@
+package category_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/category_entity"
+	"fullcycle-auction_go/internal/infra/tracing"
+	"fullcycle-auction_go/internal/internal_error"
+)
+
+// FindCategoryById retorna a categoria com toda a sua subárvore
+func (cu *CategoryUseCase) FindCategoryById(
+	ctx context.Context, id string) (*CategoryOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "CategoryUseCase.FindCategoryById")
+	defer span.End()
+
+	category, err := cu.categoryRepository.FindCategoryById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	descendants, err := cu.categoryRepository.FindDescendants(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	output := buildTree(*category, childrenByParent(descendants))
+	return &output, nil
+}
+
+// FindCategoryTree retorna todas as categorias organizadas a partir das raízes
+func (cu *CategoryUseCase) FindCategoryTree(
+	ctx context.Context) ([]CategoryOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "CategoryUseCase.FindCategoryTree")
+	defer span.End()
+
+	categories, err := cu.categoryRepository.FindCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	children := childrenByParent(categories)
+	roots := make([]CategoryOutputDTO, 0, len(children[""]))
+	for _, root := range children[""] {
+		roots = append(roots, buildTree(root, children))
+	}
+
+	return roots, nil
+}
+
+func childrenByParent(categories []category_entity.Category) map[string][]category_entity.Category {
+	children := make(map[string][]category_entity.Category)
+	for _, category := range categories {
+		children[category.ParentId] = append(children[category.ParentId], category)
+	}
+	return children
+}
+
+func buildTree(
+	category category_entity.Category,
+	children map[string][]category_entity.Category) CategoryOutputDTO {
+	output := toCategoryOutputDTO(category)
+	for _, child := range children[category.Id] {
+		output.Children = append(output.Children, buildTree(child, children))
+	}
+	return output
+}
+
+func toCategoryOutputDTO(category category_entity.Category) CategoryOutputDTO {
+	allowedConditions := make([]int64, 0, len(category.AllowedConditions))
+	for _, condition := range category.AllowedConditions {
+		allowedConditions = append(allowedConditions, int64(condition))
+	}
+
+	var defaultDuration string
+	if category.DefaultDuration > 0 {
+		defaultDuration = category.DefaultDuration.String()
+	}
+
+	return CategoryOutputDTO{
+		Id:                category.Id,
+		Name:              category.Name,
+		Slug:              category.Slug,
+		ParentId:          category.ParentId,
+		Ancestors:         category.Ancestors,
+		AllowedConditions: allowedConditions,
+		DefaultDuration:   defaultDuration,
+	}
+}
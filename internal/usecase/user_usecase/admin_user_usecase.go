@@ -0,0 +1,172 @@
+package user_usecase
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/infra/tracing"
+	"fullcycle-auction_go/internal/internal_error"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// UserAdminOutputDTO é a visão completa do usuário, usada apenas nas rotas de administração
+type UserAdminOutputDTO struct {
+	Id        string   `json:"id"`
+	Name      string   `json:"name"`
+	Email     string   `json:"email"`
+	Roles     []string `json:"roles"`
+	Suspended bool     `json:"suspended"`
+}
+
+// LookupUser busca um usuário pelo id ou, quando o valor contém "@", pelo e-mail
+func (u *UserUseCase) LookupUser(
+	ctx context.Context, idOrEmail string) (*UserAdminOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "UserUseCase.LookupUser")
+	defer span.End()
+
+	var user *user_entity.User
+	var err *internal_error.InternalError
+	if strings.Contains(idOrEmail, "@") {
+		user, err = u.UserRepository.FindUserByEmail(ctx, user_entity.NormalizeEmail(idOrEmail))
+	} else {
+		user, err = u.UserRepository.FindUserById(ctx, idOrEmail)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return toUserAdminOutputDTO(user), nil
+}
+
+func (u *UserUseCase) GrantRole(
+	ctx context.Context,
+	actingUserId, userId, role string) (*UserAdminOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "UserUseCase.GrantRole")
+	defer span.End()
+
+	userRole := user_entity.Role(role)
+	if !userRole.IsValid() {
+		return nil, internal_error.NewBadRequestError(fmt.Sprintf("Invalid role %s", role))
+	}
+
+	user, err := u.UserRepository.FindUserById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.GrantRole(userRole) {
+		if err := u.UserRepository.UpdateUserRoles(ctx, userId, user.Roles); err != nil {
+			return nil, err
+		}
+		logger.InfoContext(ctx, "Role granted", zap.String("role", role), zap.String("target_user_id", userId),
+			zap.String("acting_user_id", actingUserId))
+	}
+
+	return toUserAdminOutputDTO(user), nil
+}
+
+func (u *UserUseCase) RevokeRole(
+	ctx context.Context,
+	actingUserId, userId, role string) (*UserAdminOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "UserUseCase.RevokeRole")
+	defer span.End()
+
+	userRole := user_entity.Role(role)
+	if !userRole.IsValid() {
+		return nil, internal_error.NewBadRequestError(fmt.Sprintf("Invalid role %s", role))
+	}
+
+	// Impede que um administrador fique sem acesso às rotas de administração por engano
+	if userRole == user_entity.RoleAdmin && actingUserId == userId {
+		return nil, internal_error.NewBadRequestError("Admins cannot revoke their own admin role")
+	}
+
+	user, err := u.UserRepository.FindUserById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.RevokeRole(userRole) {
+		if err := u.UserRepository.UpdateUserRoles(ctx, userId, user.Roles); err != nil {
+			return nil, err
+		}
+		logger.InfoContext(ctx, "Role revoked", zap.String("role", role), zap.String("target_user_id", userId),
+			zap.String("acting_user_id", actingUserId))
+	}
+
+	return toUserAdminOutputDTO(user), nil
+}
+
+func (u *UserUseCase) SetSuspended(
+	ctx context.Context,
+	actingUserId, userId string,
+	suspended bool) (*UserAdminOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "UserUseCase.SetSuspended")
+	defer span.End()
+
+	if suspended && actingUserId == userId {
+		return nil, internal_error.NewBadRequestError("Admins cannot suspend themselves")
+	}
+
+	user, err := u.UserRepository.FindUserById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.UserRepository.SetUserSuspended(ctx, userId, suspended); err != nil {
+		return nil, err
+	}
+	user.Suspended = suspended
+
+	logger.InfoContext(ctx, "User suspension changed", zap.String("target_user_id", userId), zap.Bool("suspended", suspended),
+		zap.String("acting_user_id", actingUserId))
+
+	return toUserAdminOutputDTO(user), nil
+}
+
+// BootstrapAdmins concede o papel de administrador aos e-mails informados, para
+// que exista ao menos um administrador capaz de conceder os demais papéis.
+// E-mails ainda não cadastrados são apenas registrados no log.
+func (u *UserUseCase) BootstrapAdmins(ctx context.Context, emails []string) {
+	ctx, span := tracing.Start(ctx, "UserUseCase.BootstrapAdmins")
+	defer span.End()
+
+	for _, email := range emails {
+		user, err := u.UserRepository.FindUserByEmail(ctx, email)
+		if err != nil {
+			logger.InfoContext(ctx, "Bootstrap admin not registered yet", zap.String("email", email))
+			continue
+		}
+
+		if !user.GrantRole(user_entity.RoleAdmin) {
+			continue
+		}
+
+		if err := u.UserRepository.UpdateUserRoles(ctx, user.Id, user.Roles); err != nil {
+			logger.ErrorContext(ctx, "Error trying to grant admin role to bootstrap user", err, zap.String("email", email))
+			continue
+		}
+		logger.InfoContext(ctx, "Admin role granted to bootstrap user", zap.String("email", email))
+	}
+}
+
+func toUserAdminOutputDTO(user *user_entity.User) *UserAdminOutputDTO {
+	return &UserAdminOutputDTO{
+		Id:        user.Id,
+		Name:      user.Name,
+		Email:     user.Email,
+		Roles:     rolesToStrings(user.Roles),
+		Suspended: user.Suspended,
+	}
+}
+
+func rolesToStrings(roles []user_entity.Role) []string {
+	values := make([]string, 0, len(roles))
+	for _, role := range roles {
+		values = append(values, string(role))
+	}
+	return values
+}
@@ -3,32 +3,31 @@ package user_usecase
 import (
 	"context"
 	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/infra/tracing"
 	"fullcycle-auction_go/internal/internal_error"
-	"github.com/google/uuid"
 )
 
 type UserCreateInputDTO struct {
-	Name string `json:"name" binding:"required,min=3"`
+	Name     string `json:"name" binding:"required,min=3"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
 }
 
 type UserCreateOutputDTO struct {
-	Id   string `json:"id"`
-	Name string `json:"name"`
+	Id    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
 }
 
-// Adicionar ao UserUseCaseInterface
-// Interface já existente precisa ser atualizada para incluir o novo método
-// Adicione esta linha na definição da interface em find_user_usecase.go:
-//
-// CreateUser(ctx context.Context, input UserCreateInputDTO) (*UserCreateOutputDTO, *internal_error.InternalError)
-
 func (u *UserUseCase) CreateUser(
 	ctx context.Context, input UserCreateInputDTO) (*UserCreateOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "UserUseCase.CreateUser")
+	defer span.End()
 
-	// Criar uma nova entidade de usuário
-	user := &user_entity.User{
-		Id:   uuid.New().String(),
-		Name: input.Name,
+	// Criar uma nova entidade de usuário, já com o hash da senha
+	user, err := user_entity.CreateUser(input.Name, input.Email, input.Password)
+	if err != nil {
+		return nil, err
 	}
 
 	// Inserir no banco de dados
@@ -38,7 +37,8 @@ func (u *UserUseCase) CreateUser(
 
 	// Retornar DTO de saída
 	return &UserCreateOutputDTO{
-		Id:   user.Id,
-		Name: user.Name,
+		Id:    user.Id,
+		Name:  user.Name,
+		Email: user.Email,
 	}, nil
 }
@@ -3,6 +3,7 @@ package user_usecase
 import (
 	"context"
 	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/infra/tracing"
 	"fullcycle-auction_go/internal/internal_error"
 )
 
@@ -17,8 +18,9 @@ type UserUseCase struct {
 }
 
 type UserOutputDTO struct {
-	Id   string `json:"id"`
-	Name string `json:"name"`
+	Id    string   `json:"id"`
+	Name  string   `json:"name"`
+	Roles []string `json:"roles"`
 }
 
 type UserUseCaseInterface interface {
@@ -29,17 +31,39 @@ type UserUseCaseInterface interface {
 	CreateUser(
 		ctx context.Context,
 		input UserCreateInputDTO) (*UserCreateOutputDTO, *internal_error.InternalError)
+
+	LookupUser(
+		ctx context.Context, idOrEmail string) (*UserAdminOutputDTO, *internal_error.InternalError)
+
+	GrantRole(
+		ctx context.Context,
+		actingUserId, userId, role string) (*UserAdminOutputDTO, *internal_error.InternalError)
+
+	RevokeRole(
+		ctx context.Context,
+		actingUserId, userId, role string) (*UserAdminOutputDTO, *internal_error.InternalError)
+
+	SetSuspended(
+		ctx context.Context,
+		actingUserId, userId string,
+		suspended bool) (*UserAdminOutputDTO, *internal_error.InternalError)
+
+	BootstrapAdmins(ctx context.Context, emails []string)
 }
 
 func (u *UserUseCase) FindUserById(
 	ctx context.Context, id string) (*UserOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracing.Start(ctx, "UserUseCase.FindUserById")
+	defer span.End()
+
 	userEntity, err := u.UserRepository.FindUserById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
 	return &UserOutputDTO{
-		Id:   userEntity.Id,
-		Name: userEntity.Name,
+		Id:    userEntity.Id,
+		Name:  userEntity.Name,
+		Roles: rolesToStrings(userEntity.Roles),
 	}, nil
 }